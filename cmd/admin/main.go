@@ -0,0 +1,308 @@
+// Command admin manages users, workspaces, their members and API keys.
+//
+//	admin users create <name>
+//	admin users list
+//	admin workspaces create -owner <user> [-name <name>] <slug>
+//	admin workspaces list
+//	admin members set -workspace <slug> -user <user> -role owner|editor|viewer
+//	admin members remove -workspace <slug> -user <user>
+//	admin members list [-workspace <slug>] [-user <user>]
+//	admin keys create -owner <user> -workspace <slug> [-name <name>]
+//	admin keys list [-owner <user>]
+//	admin keys revoke <prefix>
+//
+// Storage is configured through the same environment variables as the server.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/yan-cerqueira-unvoid/url-shortener/config"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/services"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+const usage = `usage:
+  admin users create <name>
+  admin users list
+  admin workspaces create -owner <user> [-name <name>] <slug>
+  admin workspaces list
+  admin members set -workspace <slug> -user <user> -role owner|editor|viewer
+  admin members remove -workspace <slug> -user <user>
+  admin members list [-workspace <slug>] [-user <user>]
+  admin keys create -owner <user> -workspace <slug> [-name <name>]
+  admin keys list [-owner <user>]
+  admin keys revoke <prefix>`
+
+// adminUser is who member changes made from this command are attributed to.
+const adminUser = "admin"
+
+type admin struct {
+	stores     *store.Stores
+	keys       *services.APIKeyService
+	workspaces *services.WorkspaceService
+}
+
+type command func(admin *admin, args []string) error
+
+var commands = map[string]map[string]command{
+	"users": {
+		"create": createUser,
+		"list":   listUsers,
+	},
+	"workspaces": {
+		"create": createWorkspace,
+		"list":   listWorkspaces,
+	},
+	"members": {
+		"set":    setMember,
+		"remove": removeMember,
+		"list":   listMembers,
+	},
+	"keys": {
+		"create": createKey,
+		"list":   listKeys,
+		"revoke": revokeKey,
+	},
+}
+
+func main() {
+	log.SetFlags(0)
+
+	if len(os.Args) < 3 {
+		log.Fatal(usage)
+	}
+
+	run, ok := commands[os.Args[1]][os.Args[2]]
+	if !ok {
+		log.Fatalf("unknown command %q\n%s", os.Args[1]+" "+os.Args[2], usage)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	if err := withAdmin(config.LoadConfig(), func(admin *admin) error {
+		return run(admin, os.Args[3:])
+	}); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func withAdmin(cfg *config.Config, fn func(admin *admin) error) error {
+	ctx := context.Background()
+
+	stores, err := store.Open(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer func() {
+		if err := stores.Close(context.Background()); err != nil {
+			log.Printf("Failed to close storage: %v", err)
+		}
+	}()
+
+	return fn(&admin{
+		stores:     stores,
+		keys:       services.NewAPIKeyService(ctx, stores.APIKeys),
+		workspaces: services.NewWorkspaceService(ctx, stores.Workspaces),
+	})
+}
+
+// ownerAccess lets the command manage any workspace.
+func ownerAccess(workspace string) models.Access {
+	return models.Access{User: adminUser, Workspace: workspace, Role: models.RoleOwner}
+}
+
+func createUser(admin *admin, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	user, err := admin.workspaces.CreateUser(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created user %s\n", user.Name)
+
+	return nil
+}
+
+func listUsers(admin *admin, args []string) error {
+	users, err := admin.workspaces.ListUsers()
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tCREATED")
+	for _, user := range users {
+		fmt.Fprintf(writer, "%s\t%s\n", user.Name, user.CreatedAt.Format(time.RFC3339))
+	}
+
+	return writer.Flush()
+}
+
+func createWorkspace(admin *admin, args []string) error {
+	flags := flag.NewFlagSet("workspaces create", flag.ExitOnError)
+	owner := flags.String("owner", "", "user that becomes the first owner (required)")
+	name := flags.String("name", "", "display name")
+	flags.Parse(args) //nolint:errcheck
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	workspace, err := admin.workspaces.CreateWorkspace(flags.Arg(0), *name, *owner)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created workspace %s owned by %s\n", workspace.Slug, *owner)
+
+	return nil
+}
+
+func listWorkspaces(admin *admin, args []string) error {
+	workspaces, err := admin.workspaces.ListWorkspaces()
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "SLUG\tNAME\tCREATED")
+	for _, workspace := range workspaces {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", workspace.Slug, workspace.Name, workspace.CreatedAt.Format(time.RFC3339))
+	}
+
+	return writer.Flush()
+}
+
+func setMember(admin *admin, args []string) error {
+	flags := flag.NewFlagSet("members set", flag.ExitOnError)
+	workspace := flags.String("workspace", "", "workspace slug (required)")
+	user := flags.String("user", "", "user name (required)")
+	role := flags.String("role", "", "owner, editor or viewer (required)")
+	flags.Parse(args) //nolint:errcheck
+
+	if _, err := admin.stores.Workspaces.GetWorkspace(context.Background(), *workspace); err != nil {
+		return fmt.Errorf("workspace %q: %w", *workspace, err)
+	}
+
+	member, err := admin.workspaces.SetMember(ownerAccess(*workspace), *user, *role)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s is now %s of %s\n", member.User, member.Role, member.Workspace)
+
+	return nil
+}
+
+func removeMember(admin *admin, args []string) error {
+	flags := flag.NewFlagSet("members remove", flag.ExitOnError)
+	workspace := flags.String("workspace", "", "workspace slug (required)")
+	user := flags.String("user", "", "user name (required)")
+	flags.Parse(args) //nolint:errcheck
+
+	if err := admin.workspaces.RemoveMember(ownerAccess(*workspace), *user); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed %s from %s\n", *user, *workspace)
+
+	return nil
+}
+
+func listMembers(admin *admin, args []string) error {
+	flags := flag.NewFlagSet("members list", flag.ExitOnError)
+	workspace := flags.String("workspace", "", "only list the members of this workspace")
+	user := flags.String("user", "", "only list the memberships of this user")
+	flags.Parse(args) //nolint:errcheck
+
+	members, err := admin.stores.Workspaces.ListMembers(context.Background(), *workspace, *user)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "WORKSPACE\tUSER\tROLE")
+	for _, member := range members {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", member.Workspace, member.User, member.Role)
+	}
+
+	return writer.Flush()
+}
+
+func createKey(admin *admin, args []string) error {
+	flags := flag.NewFlagSet("keys create", flag.ExitOnError)
+	owner := flags.String("owner", "", "user the key acts as (required)")
+	workspace := flags.String("workspace", models.DefaultWorkspace, "workspace the key works in")
+	name := flags.String("name", "", "label to tell the owner's keys apart")
+	flags.Parse(args) //nolint:errcheck
+
+	// A key is only useful while its owner is a member of the workspace, so
+	// catch typos now rather than on the first request.
+	access, err := admin.workspaces.Access(*owner, *workspace)
+	if err != nil {
+		return err
+	}
+
+	key, rawKey, err := admin.keys.CreateKey(*name, *owner, *workspace)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created key %s for %s in %s (currently %s)\n", key.Prefix, key.Owner, key.Workspace, access.Role)
+	fmt.Println(rawKey)
+	fmt.Fprintln(os.Stderr, "Store the key now, it cannot be shown again.")
+
+	return nil
+}
+
+func listKeys(admin *admin, args []string) error {
+	flags := flag.NewFlagSet("keys list", flag.ExitOnError)
+	owner := flags.String("owner", "", "only list the keys of this owner")
+	flags.Parse(args) //nolint:errcheck
+
+	keys, err := admin.keys.ListKeys(*owner)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "PREFIX\tOWNER\tWORKSPACE\tNAME\tCREATED\tREVOKED")
+	for _, key := range keys {
+		revoked := "-"
+		if key.IsRevoked() {
+			revoked = key.RevokedAt.Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", key.Prefix, key.Owner, models.WorkspaceOrDefault(key.Workspace), key.Name, key.CreatedAt.Format(time.RFC3339), revoked)
+	}
+
+	return writer.Flush()
+}
+
+func revokeKey(admin *admin, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	if err := admin.keys.RevokeKey(args[0]); err != nil {
+		return err
+	}
+
+	fmt.Printf("Revoked key %s\n", args[0])
+
+	return nil
+}
@@ -0,0 +1,130 @@
+// Command export dumps links or click events for loading into a data
+// warehouse.
+//
+//	export [-format csv|jsonl|columnar] [-workspace slug] [-from time] [-to time] [-o file] links|clicks
+//
+// Times are RFC 3339 or YYYY-MM-DD; from is inclusive and to exclusive.
+// Without -workspace every workspace is exported, and without -o the dump
+// goes to standard output. Storage is configured through the same
+// environment variables as the server.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/yan-cerqueira-unvoid/url-shortener/config"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/export"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+func main() {
+	format := flag.String("format", export.FormatCSV, "output format: csv, jsonl or columnar")
+	workspace := flag.String("workspace", "", "only export this workspace")
+	fromFlag := flag.String("from", "", "only export records at or after this time")
+	toFlag := flag.String("to", "", "only export records before this time")
+	output := flag.String("o", "-", "output file, - for standard output")
+	flag.Parse()
+
+	if flag.NArg() != 1 || (flag.Arg(0) != "links" && flag.Arg(0) != "clicks") {
+		log.Fatal("usage: export [-format csv|jsonl|columnar] [-workspace slug] [-from time] [-to time] [-o file] links|clicks")
+	}
+
+	if !export.IsValidFormat(*format) {
+		log.Fatalf("unknown format %q, expected csv, jsonl or columnar", *format)
+	}
+
+	from, err := parseTime(*fromFlag)
+	if err != nil {
+		log.Fatalf("-from: %v", err)
+	}
+	to, err := parseTime(*toFlag)
+	if err != nil {
+		log.Fatalf("-to: %v", err)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	filter := export.Filter{Workspace: *workspace, From: from, To: to}
+	written, err := run(ctx, config.LoadConfig(), flag.Arg(0), *format, filter, *output)
+	if err != nil {
+		stop()
+		log.Fatalf("Export stopped after %d %s: %v", written, flag.Arg(0), err)
+	}
+
+	log.Printf("Exported %d %s", written, flag.Arg(0))
+}
+
+func run(ctx context.Context, cfg *config.Config, kind, format string, filter export.Filter, output string) (int64, error) {
+	stores, err := store.Open(ctx, cfg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer func() {
+		if err := stores.Close(context.Background()); err != nil {
+			log.Printf("Failed to close storage: %v", err)
+		}
+	}()
+
+	var out io.Writer = os.Stdout
+	if output != "-" {
+		file, err := os.Create(output)
+		if err != nil {
+			return 0, err
+		}
+		defer file.Close()
+
+		out = file
+	}
+
+	columns := export.LinkColumns
+	if kind == "clicks" {
+		columns = export.ClickColumns
+	}
+
+	w, err := export.NewWriter(out, format, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	var written int64
+	if kind == "clicks" {
+		written, err = export.Clicks(ctx, stores.Clicks, filter, w)
+	} else {
+		written, err = export.Links(ctx, stores.URLs, filter, w)
+	}
+	if err != nil {
+		return written, err
+	}
+
+	return written, w.Close()
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("%q is not an RFC 3339 time or a YYYY-MM-DD date", value)
+}
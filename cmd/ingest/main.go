@@ -0,0 +1,144 @@
+// Command ingest backfills click events and counters from access logs.
+//
+//	ingest [-format auto|combined|json|caddy|legacy] [-batch-size n] [-since time] [-until time] [-dry-run] [file ...]
+//
+// With no file, or with "-", the log is read from standard input. Storage is
+// configured through the same environment variables as the server. Clicks
+// already imported are skipped, so a log can safely be imported again; use
+// -until to leave out the period the server was recording clicks itself.
+// Times are RFC 3339. The command exits with status 1 when an import fails.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/yan-cerqueira-unvoid/url-shortener/config"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/analytics"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/geo"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/ingest"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/parser"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+func main() {
+	format := flag.String("format", parser.FormatAuto, "log format: auto, combined, json, caddy or legacy")
+	batchSize := flag.Int("batch-size", ingest.DefaultBatchSize, "clicks written per batch")
+	sinceFlag := flag.String("since", "", "skip entries logged before this time")
+	untilFlag := flag.String("until", "", "skip entries logged at or after this time")
+	dryRun := flag.Bool("dry-run", false, "parse and count the log without writing")
+	flag.Parse()
+
+	since, err := parseTime(*sinceFlag)
+	if err != nil {
+		log.Fatalf("-since: %v", err)
+	}
+	until, err := parseTime(*untilFlag)
+	if err != nil {
+		log.Fatalf("-until: %v", err)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	logParser, err := parser.NewLogParser(*format)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+
+	opts := ingest.Options{BatchSize: *batchSize, DryRun: *dryRun, Since: since, Until: until}
+	total, err := run(ctx, config.LoadConfig(), logParser, opts, paths)
+
+	log.Printf("Read %d lines: %d clicks imported (%d from bots), %d already imported, %d skipped, %d malformed",
+		total.Lines, total.Imported, total.Bots, total.Duplicates, total.Skipped, total.Malformed)
+	if *dryRun {
+		log.Println("Dry run, nothing was written")
+	}
+
+	if err != nil {
+		stop()
+		log.Fatal(err)
+	}
+}
+
+// run imports paths in order, stopping at the first failure. The result
+// covers everything read until then.
+func run(ctx context.Context, cfg *config.Config, logParser *parser.LogParser, opts ingest.Options, paths []string) (ingest.Result, error) {
+	var total ingest.Result
+
+	stores, err := store.Open(ctx, cfg)
+	if err != nil {
+		return total, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer func() {
+		if err := stores.Close(context.Background()); err != nil {
+			log.Printf("Failed to close storage: %v", err)
+		}
+	}()
+
+	geoReader, err := geo.Open(cfg.Analytics.GeoIPDatabase)
+	if err != nil {
+		return total, fmt.Errorf("failed to initialize geolocation: %w", err)
+	}
+	defer geoReader.Close()
+
+	opts.CountBots = cfg.Analytics.CountBots
+	opts.Enrichers = analytics.Enrichers(geoReader)
+	ingester := ingest.New(logParser, stores.URLs, stores.Clicks, opts)
+
+	for _, path := range paths {
+		result, err := ingestFile(ctx, ingester, path)
+		total.Lines += result.Lines
+		total.Imported += result.Imported
+		total.Skipped += result.Skipped
+		total.Duplicates += result.Duplicates
+		total.Malformed += result.Malformed
+		total.Bots += result.Bots
+
+		if err != nil {
+			return total, fmt.Errorf("ingest stopped in %s after %d lines: %w", path, result.Lines, err)
+		}
+	}
+
+	return total, nil
+}
+
+func ingestFile(ctx context.Context, ingester *ingest.Ingester, path string) (ingest.Result, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return ingest.Result{}, err
+		}
+		defer file.Close()
+
+		r = file
+	}
+
+	return ingester.Run(ctx, r)
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
@@ -11,12 +11,16 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
 	"github.com/yan-cerqueira-unvoid/url-shortener/config"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/analytics"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/geo"
 	"github.com/yan-cerqueira-unvoid/url-shortener/internal/handlers"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/jwks"
 	"github.com/yan-cerqueira-unvoid/url-shortener/internal/parser"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/ratelimit"
 	"github.com/yan-cerqueira-unvoid/url-shortener/internal/services"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
 )
 
 func main() {
@@ -30,37 +34,118 @@ func main() {
 		cfg.PrintConfig()
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), cfg.MongoDB.Timeout)
-	defer cancel()
-
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.MongoDB.URI))
+	stores, err := store.Open(context.Background(), cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
+		log.Fatalf("Failed to initialize storage: %v", err)
 	}
 	defer func() {
-		if err := client.Disconnect(context.Background()); err != nil {
-			log.Printf("Failed to disconnect from MongoDB: %v", err)
+		if err := stores.Close(context.Background()); err != nil {
+			log.Printf("Failed to close storage: %v", err)
 		}
 	}()
 
-	if err := client.Ping(ctx, nil); err != nil {
-		log.Fatalf("Failed to ping MongoDB: %v", err)
+	codeGenerator, err := services.NewCodeGenerator(cfg.URLShortener, stores.URLs)
+	if err != nil {
+		log.Fatalf("Failed to initialize short code generator: %v", err)
 	}
-	log.Println("Connected to MongoDB successfully")
-
-	db := client.Database(cfg.MongoDB.Database)
 
-	urlService := services.NewURLService(db, &ctx)
+	urlService := services.NewURLService(context.Background(), stores.URLs, cfg.URLShortener, codeGenerator)
+	statsService := services.NewStatsService(context.Background(), stores.URLs, stores.Clicks)
+	exportService := services.NewExportService(context.Background(), stores.URLs, stores.Clicks)
+	apiKeyService := services.NewAPIKeyService(context.Background(), stores.APIKeys)
+	workspaceService := services.NewWorkspaceService(context.Background(), stores.Workspaces)
 	urlParser := parser.NewURLParser()
 
+	var tokenVerifier handlers.TokenVerifierInterface
+	if cfg.Auth.JWT.JWKS != "" {
+		keys, err := jwks.Open(context.Background(), cfg.Auth.JWT.JWKS)
+		if err != nil {
+			log.Fatalf("Failed to load JWT signing keys: %v", err)
+		}
+
+		verifier, err := services.NewTokenVerifier(context.Background(), keys, cfg.Auth.JWT)
+		if err != nil {
+			log.Fatalf("Failed to initialize JWT authentication: %v", err)
+		}
+		tokenVerifier = verifier
+	}
+
+	geoReader, err := geo.Open(cfg.Analytics.GeoIPDatabase)
+	if err != nil {
+		log.Fatalf("Failed to initialize geolocation: %v", err)
+	}
+	defer geoReader.Close()
+
+	var rateLimits handlers.RateLimitStoreInterface = ratelimit.NewMemoryStore()
+	if cfg.RateLimit.RedisURL != "" {
+		redisOptions, err := redis.ParseURL(cfg.RateLimit.RedisURL)
+		if err != nil {
+			log.Fatalf("Invalid rate limit Redis URL: %v", err)
+		}
+
+		redisClient := redis.NewClient(redisOptions)
+		defer redisClient.Close()
+
+		if err := redisClient.Ping(context.Background()).Err(); err != nil {
+			log.Fatalf("Failed to connect to rate limit Redis: %v", err)
+		}
+		rateLimits = ratelimit.NewRedisStore(redisClient, "url_shortener:ratelimit:")
+	}
+	createLimit := ratelimit.PerMinute(cfg.RateLimit.CreatePerMinute, cfg.RateLimit.CreateBurst)
+	redirectLimit := ratelimit.PerMinute(cfg.RateLimit.RedirectPerMinute, cfg.RateLimit.RedirectBurst)
+	bulkLimit := ratelimit.PerMinute(cfg.RateLimit.BulkPerMinute, cfg.RateLimit.BulkBurst)
+
+	clickRecorder := analytics.NewRecorder(stores.Clicks, stores.URLs, cfg.Analytics, analytics.Enrichers(geoReader)...)
+	clickRecorder.Start()
+
 	if os.Getenv("GIN_MODE") == "release" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 	router := gin.Default()
 
+	trustedProxies := cfg.Server.TrustedProxies
+	if len(trustedProxies) == 0 {
+		trustedProxies = nil
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
+
+	linkURLs, err := handlers.NewLinkURLs(cfg.URLShortener.QRBaseURL, trustedProxies)
+	if err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
+
 	router.GET("/", handlers.HomeHandler())
-	router.GET("/:shortCode", handlers.RedirectHandler(urlService))
-	router.POST("/shorten", handlers.ShortenURLHandler(urlService, urlParser))
+	router.GET("/:shortCode", handlers.RateLimit(rateLimits, "redirect", redirectLimit), handlers.RedirectHandler(urlService, clickRecorder))
+	router.GET("/:shortCode/qr", handlers.RateLimit(rateLimits, "redirect", redirectLimit), handlers.QRCodeHandler(urlService, linkURLs))
+
+	authenticated := router.Group("/")
+	if cfg.Auth.RequireAPIKey {
+		authenticated.Use(handlers.RequireAuth(apiKeyService, tokenVerifier, workspaceService))
+	} else {
+		log.Println("API keys are not required, anyone can create and manage links")
+		authenticated.Use(handlers.AllowAnonymous())
+	}
+
+	authenticated.POST("/shorten", handlers.RateLimit(rateLimits, "create", createLimit), handlers.ShortenURLHandler(urlService, urlParser, linkURLs))
+
+	api := authenticated.Group("/api/v1")
+	{
+		api.GET("/links", handlers.ListLinksHandler(urlService))
+		api.POST("/links/bulk", handlers.BulkShortenHandler(urlService, urlParser, rateLimits, bulkLimit))
+		api.GET("/links/:code", handlers.GetLinkHandler(urlService))
+		api.PATCH("/links/:code", handlers.UpdateLinkHandler(urlService, urlParser))
+		api.DELETE("/links/:code", handlers.DeleteLinkHandler(urlService))
+		api.POST("/links/:code/restore", handlers.RestoreLinkHandler(urlService))
+		api.GET("/links/:code/stats", handlers.LinkStatsHandler(statsService))
+		api.GET("/usage", handlers.UsageHandler(urlService))
+		api.GET("/export/links", handlers.ExportLinksHandler(exportService))
+		api.GET("/export/clicks", handlers.ExportClicksHandler(exportService))
+		api.GET("/workspace/members", handlers.ListMembersHandler(workspaceService))
+		api.PUT("/workspace/members/:user", handlers.SetMemberHandler(workspaceService))
+		api.DELETE("/workspace/members/:user", handlers.RemoveMemberHandler(workspaceService))
+	}
 
 	server := &http.Server{
 		Addr:         ":" + cfg.Server.Port,
@@ -82,10 +167,19 @@ func main() {
 	<-quit
 	log.Println("Shutting down server...")
 
-	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		log.Printf("Server forced to shutdown: %v", err)
+	}
+
+	// Redirects have stopped, so the clicks still buffered can be flushed
+	// before the storage connection is closed.
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFlush()
+
+	if err := clickRecorder.Close(flushCtx); err != nil {
+		log.Printf("Failed to flush click events: %v", err)
 	}
 }
@@ -4,12 +4,18 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
 	Server       ServerConfig
+	Analytics    AnalyticsConfig
+	Auth         AuthConfig
+	RateLimit    RateLimitConfig
+	Storage      StorageConfig
 	MongoDB      MongoDBConfig
+	SQL          SQLConfig
 	URLShortener URLShortenerConfig
 }
 
@@ -18,6 +24,68 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+
+	// TrustedProxies lists the proxy IPs or CIDRs whose X-Forwarded-For header
+	// is believed when resolving client IPs. Empty trusts none.
+	TrustedProxies []string
+}
+
+type AnalyticsConfig struct {
+	BufferSize    int
+	BatchSize     int
+	FlushInterval time.Duration
+	// CountBots adds bot clicks to the link click counters.
+	CountBots bool
+	// GeoIPDatabase is the path of an MMDB file used to geolocate clicks.
+	// Empty disables geolocation.
+	GeoIPDatabase string
+}
+
+type AuthConfig struct {
+	// RequireAPIKey protects link creation and the management API with API
+	// keys. Redirects are always public.
+	RequireAPIKey bool
+	JWT           JWTConfig
+}
+
+// JWTConfig accepts bearer tokens from an identity provider besides API keys.
+// Tokens are only accepted when JWKS is set.
+type JWTConfig struct {
+	// JWKS is the path or http(s) URL of the provider's JSON Web Key Set.
+	JWKS     string
+	Issuer   string
+	Audience string
+	// UserClaim and WorkspaceClaim name the claims holding the user name and
+	// workspace slug. Tokens without a workspace claim act in the default
+	// workspace.
+	UserClaim      string
+	WorkspaceClaim string
+	// Leeway tolerates clock skew when checking expiry and not-before times.
+	Leeway time.Duration
+}
+
+// RateLimitConfig limits each client, told apart by API key, token subject
+// or IP address. A limit of zero requests disables it.
+type RateLimitConfig struct {
+	// Shortening requests per minute, and how many may come at once.
+	CreatePerMinute int
+	CreateBurst     int
+	// Redirects per minute, and how many may come at once. This also slows
+	// down guessing short codes.
+	RedirectPerMinute int
+	RedirectBurst     int
+	// Links created by bulk requests per minute, and how many may come at
+	// once. The burst is at least MaxBulkLinks, so that a full request can
+	// pass.
+	BulkPerMinute int
+	BulkBurst     int
+	// RedisURL shares the limits between server instances through Redis.
+	// Empty keeps them in each instance's memory.
+	RedisURL string
+}
+
+type StorageConfig struct {
+	Backend string
 }
 
 type MongoDBConfig struct {
@@ -26,9 +94,41 @@ type MongoDBConfig struct {
 	Timeout  time.Duration
 }
 
+type SQLConfig struct {
+	Dialect string
+	DSN     string
+}
+
 type URLShortenerConfig struct {
-	DefaultExpiry time.Duration
+	DefaultExpiry time.Duration // zero means links never expire by default
+	MaxExpiry     time.Duration // zero means no upper bound
 	CodeLength    int
+	CodeStrategy  string // random, counter or sqids
+	CodeAlphabet  string // empty uses base62
+
+	DefaultRedirectType int
+
+	MaxBulkLinks int // most links one bulk request may create
+
+	// QRBaseURL is the scheme and host encoded in QR codes, such as
+	// https://sho.rt. Empty takes them from each request.
+	QRBaseURL string
+
+	Quotas QuotaConfig
+}
+
+// QuotaConfig caps the links of each user, across workspaces, and of each
+// workspace, whoever created them.
+type QuotaConfig struct {
+	User      QuotaLimits
+	Workspace QuotaLimits
+}
+
+// QuotaLimits are plan-style caps. Zero means unlimited.
+type QuotaLimits struct {
+	ActiveLinks  int64 // links neither deleted nor expired
+	CustomCodes  int64 // active links with a custom short code
+	MonthlyLinks int64 // links created this calendar month (UTC), deleted or not
 }
 
 func LoadConfig() *Config {
@@ -37,13 +137,64 @@ func LoadConfig() *Config {
 	readTimeout, _ := strconv.Atoi(getEnv("SERVER_READ_TIMEOUT", "5"))
 	writeTimeout, _ := strconv.Atoi(getEnv("SERVER_WRITE_TIMEOUT", "10"))
 	idleTimeout, _ := strconv.Atoi(getEnv("SERVER_IDLE_TIMEOUT", "120"))
+	trustedProxies := splitList(getEnv("TRUSTED_PROXIES", ""))
+
+	clickBufferSize, _ := strconv.Atoi(getEnv("CLICK_BUFFER_SIZE", "1024"))
+	clickBatchSize, _ := strconv.Atoi(getEnv("CLICK_BATCH_SIZE", "100"))
+	clickFlushInterval, _ := strconv.Atoi(getEnv("CLICK_FLUSH_INTERVAL", "1"))
+	clickCountBots, _ := strconv.ParseBool(getEnv("CLICK_COUNT_BOTS", "false"))
+	geoIPDatabase := getEnv("GEOIP_DATABASE", "")
+
+	storageBackend := getEnv("STORAGE_BACKEND", "mongo")
+
+	// Keys are created by cmd/admin in another process, which cannot reach
+	// the server's in-memory store, so memory storage runs open by default.
+	requireAPIKeyDefault := "true"
+	if storageBackend == "memory" {
+		requireAPIKeyDefault = "false"
+	}
+	// A mistyped value must not open the management routes to anyone.
+	requireAPIKey, err := strconv.ParseBool(strings.TrimSpace(getEnv("REQUIRE_API_KEY", requireAPIKeyDefault)))
+	if err != nil {
+		log.Fatalf("Invalid REQUIRE_API_KEY %q: use true or false", os.Getenv("REQUIRE_API_KEY"))
+	}
+	jwtJWKS := getEnv("JWT_JWKS", "")
+	jwtIssuer := getEnv("JWT_ISSUER", "")
+	jwtAudience := getEnv("JWT_AUDIENCE", "")
+	jwtUserClaim := getEnv("JWT_USER_CLAIM", "sub")
+	jwtWorkspaceClaim := getEnv("JWT_WORKSPACE_CLAIM", "workspace")
+	jwtLeeway, _ := strconv.Atoi(getEnv("JWT_LEEWAY", "30"))
+
+	rateLimitCreate, _ := strconv.Atoi(getEnv("RATE_LIMIT_CREATE", "60"))
+	rateLimitCreateBurst, _ := strconv.Atoi(getEnv("RATE_LIMIT_CREATE_BURST", "20"))
+	rateLimitRedirect, _ := strconv.Atoi(getEnv("RATE_LIMIT_REDIRECT", "600"))
+	rateLimitRedirectBurst, _ := strconv.Atoi(getEnv("RATE_LIMIT_REDIRECT_BURST", "100"))
+	rateLimitBulk, _ := strconv.Atoi(getEnv("RATE_LIMIT_BULK", "600"))
+	rateLimitBulkBurst, _ := strconv.Atoi(getEnv("RATE_LIMIT_BULK_BURST", "0"))
+	rateLimitRedisURL := getEnv("RATE_LIMIT_REDIS_URL", "")
 
 	mongoURI := getEnv("MONGO_URI", "mongodb://localhost:27017")
 	mongoDatabase := getEnv("DB_NAME", "url_shortener")
 	mongoTimeout, _ := strconv.Atoi(getEnv("MONGO_TIMEOUT", "10"))
 
+	sqlDialect := getEnv("SQL_DIALECT", "sqlite")
+	sqlDSN := getEnv("SQL_DSN", "url_shortener.db")
+
 	defaultExpiryDays, _ := strconv.Atoi(getEnv("URL_DEFAULT_EXPIRY_DAYS", "365"))
+	maxExpiryDays, _ := strconv.Atoi(getEnv("URL_MAX_EXPIRY_DAYS", "0"))
 	codeLength, _ := strconv.Atoi(getEnv("URL_CODE_LENGTH", "6"))
+	codeStrategy := getEnv("URL_CODE_STRATEGY", "random")
+	codeAlphabet := getEnv("URL_CODE_ALPHABET", "")
+	defaultRedirectType, _ := strconv.Atoi(getEnv("URL_DEFAULT_REDIRECT_TYPE", "301"))
+	maxBulkLinks, _ := strconv.Atoi(getEnv("BULK_MAX_LINKS", "500"))
+	qrBaseURL := getEnv("QR_BASE_URL", "")
+
+	userActiveLinks, _ := strconv.ParseInt(getEnv("QUOTA_USER_ACTIVE_LINKS", "0"), 10, 64)
+	userCustomCodes, _ := strconv.ParseInt(getEnv("QUOTA_USER_CUSTOM_CODES", "0"), 10, 64)
+	userMonthlyLinks, _ := strconv.ParseInt(getEnv("QUOTA_USER_MONTHLY_LINKS", "0"), 10, 64)
+	workspaceActiveLinks, _ := strconv.ParseInt(getEnv("QUOTA_WORKSPACE_ACTIVE_LINKS", "0"), 10, 64)
+	workspaceCustomCodes, _ := strconv.ParseInt(getEnv("QUOTA_WORKSPACE_CUSTOM_CODES", "0"), 10, 64)
+	workspaceMonthlyLinks, _ := strconv.ParseInt(getEnv("QUOTA_WORKSPACE_MONTHLY_LINKS", "0"), 10, 64)
 
 	return &Config{
 		Server: ServerConfig{
@@ -51,15 +202,73 @@ func LoadConfig() *Config {
 			ReadTimeout:  time.Duration(readTimeout) * time.Second,
 			WriteTimeout: time.Duration(writeTimeout) * time.Second,
 			IdleTimeout:  time.Duration(idleTimeout) * time.Second,
+
+			TrustedProxies: trustedProxies,
+		},
+		Analytics: AnalyticsConfig{
+			BufferSize:    clickBufferSize,
+			BatchSize:     clickBatchSize,
+			FlushInterval: time.Duration(clickFlushInterval) * time.Second,
+			CountBots:     clickCountBots,
+			GeoIPDatabase: geoIPDatabase,
+		},
+		Auth: AuthConfig{
+			RequireAPIKey: requireAPIKey,
+			JWT: JWTConfig{
+				JWKS:           jwtJWKS,
+				Issuer:         jwtIssuer,
+				Audience:       jwtAudience,
+				UserClaim:      jwtUserClaim,
+				WorkspaceClaim: jwtWorkspaceClaim,
+				Leeway:         time.Duration(jwtLeeway) * time.Second,
+			},
+		},
+		RateLimit: RateLimitConfig{
+			CreatePerMinute:   rateLimitCreate,
+			CreateBurst:       rateLimitCreateBurst,
+			RedirectPerMinute: rateLimitRedirect,
+			RedirectBurst:     rateLimitRedirectBurst,
+			BulkPerMinute:     rateLimitBulk,
+			BulkBurst:         max(rateLimitBulkBurst, maxBulkLinks),
+			RedisURL:          rateLimitRedisURL,
+		},
+		Storage: StorageConfig{
+			Backend: storageBackend,
 		},
 		MongoDB: MongoDBConfig{
 			URI:      mongoURI,
 			Database: mongoDatabase,
 			Timeout:  time.Duration(mongoTimeout) * time.Second,
 		},
+		SQL: SQLConfig{
+			Dialect: sqlDialect,
+			DSN:     sqlDSN,
+		},
 		URLShortener: URLShortenerConfig{
 			DefaultExpiry: time.Duration(defaultExpiryDays) * 24 * time.Hour,
+			MaxExpiry:     time.Duration(maxExpiryDays) * 24 * time.Hour,
 			CodeLength:    codeLength,
+			CodeStrategy:  codeStrategy,
+			CodeAlphabet:  codeAlphabet,
+
+			DefaultRedirectType: defaultRedirectType,
+
+			MaxBulkLinks: maxBulkLinks,
+
+			QRBaseURL: qrBaseURL,
+
+			Quotas: QuotaConfig{
+				User: QuotaLimits{
+					ActiveLinks:  userActiveLinks,
+					CustomCodes:  userCustomCodes,
+					MonthlyLinks: userMonthlyLinks,
+				},
+				Workspace: QuotaLimits{
+					ActiveLinks:  workspaceActiveLinks,
+					CustomCodes:  workspaceCustomCodes,
+					MonthlyLinks: workspaceMonthlyLinks,
+				},
+			},
 		},
 	}
 }
@@ -73,18 +282,65 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+// splitList parses a comma-separated environment value, skipping blanks.
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
 func (c *Config) PrintConfig() {
 	log.Println("Server Configuration:")
 	log.Printf("Port: %s\n", c.Server.Port)
 	log.Printf("Read Timeout: %v\n", c.Server.ReadTimeout)
 	log.Printf("Write Timeout: %v\n", c.Server.WriteTimeout)
 	log.Printf("Idle Timeout: %v\n", c.Server.IdleTimeout)
+	log.Printf("Trusted Proxies: %v\n", c.Server.TrustedProxies)
+
+	log.Println("Analytics Configuration:")
+	log.Printf("Click Buffer Size: %d\n", c.Analytics.BufferSize)
+	log.Printf("Click Batch Size: %d\n", c.Analytics.BatchSize)
+	log.Printf("Click Flush Interval: %v\n", c.Analytics.FlushInterval)
+	log.Printf("Count Bot Clicks: %v\n", c.Analytics.CountBots)
+	log.Printf("GeoIP Database: %s\n", c.Analytics.GeoIPDatabase)
+
+	log.Println("Auth Configuration:")
+	log.Printf("Require API Key: %v\n", c.Auth.RequireAPIKey)
+	log.Printf("JWT JWKS: %s\n", c.Auth.JWT.JWKS)
+	log.Printf("JWT Issuer: %s\n", c.Auth.JWT.Issuer)
+	log.Printf("JWT Audience: %s\n", c.Auth.JWT.Audience)
+	log.Printf("JWT Claims: user=%s workspace=%s\n", c.Auth.JWT.UserClaim, c.Auth.JWT.WorkspaceClaim)
+	log.Printf("JWT Leeway: %v\n", c.Auth.JWT.Leeway)
+
+	log.Println("Rate Limit Configuration:")
+	log.Printf("Create: %d/min, burst %d\n", c.RateLimit.CreatePerMinute, c.RateLimit.CreateBurst)
+	log.Printf("Redirect: %d/min, burst %d\n", c.RateLimit.RedirectPerMinute, c.RateLimit.RedirectBurst)
+	log.Printf("Bulk Links: %d/min, burst %d\n", c.RateLimit.BulkPerMinute, c.RateLimit.BulkBurst)
+	log.Printf("Shared in Redis: %v\n", c.RateLimit.RedisURL != "")
+
+	log.Println("Storage Configuration:")
+	log.Printf("Backend: %s\n", c.Storage.Backend)
 
 	log.Println("MongoDB Configuration:")
 	log.Printf("Database: %s\n", c.MongoDB.Database)
 	log.Printf("Timeout: %v\n", c.MongoDB.Timeout)
 
+	log.Println("SQL Configuration:")
+	log.Printf("Dialect: %s\n", c.SQL.Dialect)
+
 	log.Println("URL Shortener Configuration:")
 	log.Printf("Default Expiry: %v\n", c.URLShortener.DefaultExpiry)
+	log.Printf("Max Expiry: %v\n", c.URLShortener.MaxExpiry)
 	log.Printf("Code Length: %d\n", c.URLShortener.CodeLength)
+	log.Printf("Code Strategy: %s\n", c.URLShortener.CodeStrategy)
+	log.Printf("Default Redirect Type: %d\n", c.URLShortener.DefaultRedirectType)
+	log.Printf("Max Bulk Links: %d\n", c.URLShortener.MaxBulkLinks)
+	log.Printf("QR Base URL: %s\n", c.URLShortener.QRBaseURL)
+	log.Printf("User Quotas: %+v\n", c.URLShortener.Quotas.User)
+	log.Printf("Workspace Quotas: %+v\n", c.URLShortener.Quotas.Workspace)
 }
@@ -165,3 +165,55 @@ func TestGetEnv(t *testing.T) {
 		t.Errorf("Expected value to be default_value, got %s", value)
 	}
 }
+
+func TestLoadConfigStorageBackend(t *testing.T) {
+	err := os.Unsetenv("STORAGE_BACKEND")
+	if err != nil {
+		t.Errorf("Error unsetting STORAGE_BACKEND environment variable: %v", err)
+	}
+
+	cfg := LoadConfig()
+	if cfg.Storage.Backend != "mongo" {
+		t.Errorf("Expected storage backend to be mongo, got %s", cfg.Storage.Backend)
+	}
+
+	err = os.Setenv("STORAGE_BACKEND", "memory")
+	if err != nil {
+		t.Errorf("Error setting STORAGE_BACKEND environment variable: %v", err)
+	}
+	defer os.Unsetenv("STORAGE_BACKEND")
+
+	cfg = LoadConfig()
+	if cfg.Storage.Backend != "memory" {
+		t.Errorf("Expected storage backend to be memory, got %s", cfg.Storage.Backend)
+	}
+}
+
+func TestLoadConfigRequireAPIKey(t *testing.T) {
+	err := os.Unsetenv("REQUIRE_API_KEY")
+	if err != nil {
+		t.Errorf("Error unsetting REQUIRE_API_KEY environment variable: %v", err)
+	}
+	defer os.Unsetenv("STORAGE_BACKEND")
+
+	for backend, expected := range map[string]bool{"mongo": true, "sql": true, "memory": false} {
+		err = os.Setenv("STORAGE_BACKEND", backend)
+		if err != nil {
+			t.Errorf("Error setting STORAGE_BACKEND environment variable: %v", err)
+		}
+
+		if cfg := LoadConfig(); cfg.Auth.RequireAPIKey != expected {
+			t.Errorf("Expected RequireAPIKey to be %v with %s storage, got %v", expected, backend, cfg.Auth.RequireAPIKey)
+		}
+	}
+
+	err = os.Setenv("REQUIRE_API_KEY", "True ")
+	if err != nil {
+		t.Errorf("Error setting REQUIRE_API_KEY environment variable: %v", err)
+	}
+	defer os.Unsetenv("REQUIRE_API_KEY")
+
+	if cfg := LoadConfig(); !cfg.Auth.RequireAPIKey {
+		t.Errorf("Expected REQUIRE_API_KEY=\"True \" to apply to memory storage")
+	}
+}
@@ -0,0 +1,92 @@
+package analytics
+
+import (
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/geo"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/parser"
+)
+
+// Enricher derives extra dimensions of a click before it is stored. It runs
+// off the redirect path and must leave fields it cannot derive untouched.
+type Enricher interface {
+	Enrich(click *models.Click)
+}
+
+// UserAgentEnricher fills in browser, OS and device type from the user agent
+// and flags bots.
+type UserAgentEnricher struct{}
+
+func (UserAgentEnricher) Enrich(click *models.Click) {
+	ua := parser.ParseUserAgent(click.UserAgent)
+
+	if click.Browser == "" {
+		click.Browser = ua.Browser
+	}
+	if click.OS == "" {
+		click.OS = ua.OS
+	}
+	if click.Device == "" {
+		click.Device = ua.Device
+	}
+	click.Bot = click.Bot || ua.IsBot
+}
+
+// CountClicks groups clicks by short code for the link counters. Bot clicks
+// are left out unless countBots is set.
+func CountClicks(clicks []models.Click, countBots bool) map[string]int64 {
+	counts := make(map[string]int64)
+	for _, click := range clicks {
+		if click.Bot && !countBots {
+			continue
+		}
+		counts[click.ShortCode]++
+	}
+
+	return counts
+}
+
+// Locator resolves an IP address to a location.
+type Locator interface {
+	Lookup(ip string) (geo.Location, bool)
+}
+
+// GeoEnricher fills in country, region and city from the client IP.
+type GeoEnricher struct {
+	locator Locator
+}
+
+func NewGeoEnricher(locator Locator) GeoEnricher {
+	return GeoEnricher{locator: locator}
+}
+
+func (enricher GeoEnricher) Enrich(click *models.Click) {
+	if click.IP == "" || (click.Country != "" && click.Region != "" && click.City != "") {
+		return
+	}
+
+	location, ok := enricher.locator.Lookup(click.IP)
+	if !ok {
+		return
+	}
+
+	if click.Country == "" {
+		click.Country = location.Country
+	}
+	if click.Region == "" {
+		click.Region = location.Region
+	}
+	if click.City == "" {
+		click.City = location.City
+	}
+}
+
+// Enrichers returns the enrichers applied to every click: user agent
+// classification and, when a database is loaded, geolocation.
+func Enrichers(geoReader *geo.Reader) []Enricher {
+	enrichers := []Enricher{UserAgentEnricher{}}
+	if geoReader.Enabled() {
+		enrichers = append(enrichers, NewGeoEnricher(geoReader))
+	}
+
+	return enrichers
+}
@@ -0,0 +1,60 @@
+package analytics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/geo"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+)
+
+type locatorFunc func(ip string) (geo.Location, bool)
+
+func (f locatorFunc) Lookup(ip string) (geo.Location, bool) {
+	return f(ip)
+}
+
+func TestGeoEnricher(t *testing.T) {
+	enricher := NewGeoEnricher(locatorFunc(func(ip string) (geo.Location, bool) {
+		if ip == "192.0.2.1" {
+			return geo.Location{Country: "BR", Region: "BR-SP", City: "São Paulo"}, true
+		}
+
+		return geo.Location{}, false
+	}))
+
+	click := models.Click{IP: "192.0.2.1"}
+	enricher.Enrich(&click)
+	assert.Equal(t, "BR", click.Country)
+	assert.Equal(t, "BR-SP", click.Region)
+	assert.Equal(t, "São Paulo", click.City)
+
+	unknown := models.Click{IP: "203.0.113.1"}
+	enricher.Enrich(&unknown)
+	assert.Empty(t, unknown.Country)
+
+	known := models.Click{IP: "192.0.2.1", Country: "PT"}
+	enricher.Enrich(&known)
+	assert.Equal(t, "PT", known.Country)
+	assert.Equal(t, "BR-SP", known.Region)
+}
+
+func TestEnrichers_WithoutGeoDatabase(t *testing.T) {
+	reader, err := geo.Open("")
+	assert.NoError(t, err)
+
+	enrichers := Enrichers(reader)
+
+	assert.Equal(t, []Enricher{UserAgentEnricher{}}, enrichers)
+}
+
+func TestCountClicks(t *testing.T) {
+	clicks := []models.Click{
+		{ShortCode: "abc123"},
+		{ShortCode: "abc123", Bot: true},
+		{ShortCode: "def456"},
+	}
+
+	assert.Equal(t, map[string]int64{"abc123": 1, "def456": 1}, CountClicks(clicks, false))
+	assert.Equal(t, map[string]int64{"abc123": 2, "def456": 1}, CountClicks(clicks, true))
+}
@@ -0,0 +1,182 @@
+package analytics
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/config"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+	writeTimeout         = 5 * time.Second
+	dropReportInterval   = time.Minute
+)
+
+// Recorder collects clicks off the redirect path and writes them in batches:
+// the events are enriched and go to the click store, and the per-link counters
+// are incremented in one bulk write, both through WriteClicks. Bot clicks are
+// stored but only counted when cfg.CountBots is set. A batch is flushed when
+// it reaches the batch size or when the flush interval elapses, whichever
+// comes first. When the buffer is full new events are dropped rather than
+// slowing the caller down; drops are logged every minute and on Close.
+type Recorder struct {
+	clicks    store.ClickStore
+	counter   store.ClickCounter
+	enrichers []Enricher
+	countBots bool
+
+	events        chan models.Click
+	done          chan struct{}
+	batchSize     int
+	flushInterval time.Duration
+
+	mu       sync.RWMutex
+	closed   bool
+	dropped  atomic.Int64
+	reported int64 // dropped clicks already logged, owned by run
+}
+
+func NewRecorder(clickStore store.ClickStore, counter store.ClickCounter, cfg config.AnalyticsConfig, enrichers ...Enricher) *Recorder {
+	bufferSize := cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	flushInterval := cfg.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = defaultFlushInterval
+	}
+
+	return &Recorder{
+		clicks:        clickStore,
+		counter:       counter,
+		enrichers:     enrichers,
+		countBots:     cfg.CountBots,
+		events:        make(chan models.Click, bufferSize),
+		done:          make(chan struct{}),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+	}
+}
+
+// Start launches the background writer. It must be called once.
+func (r *Recorder) Start() {
+	go r.run()
+}
+
+// Record queues a click without blocking.
+func (r *Recorder) Record(click models.Click) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.closed {
+		r.dropped.Add(1)
+		return
+	}
+
+	select {
+	case r.events <- click:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// Dropped returns how many clicks were discarded because the buffer was full,
+// the recorder was closed or their batch could not be fully written.
+func (r *Recorder) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+// Close stops accepting clicks and waits for the queued ones to be flushed or
+// for ctx to be done.
+func (r *Recorder) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.events)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	reportTicker := time.NewTicker(dropReportInterval)
+	defer reportTicker.Stop()
+
+	batch := make([]models.Click, 0, r.batchSize)
+	for {
+		select {
+		case click, ok := <-r.events:
+			if !ok {
+				r.flush(batch)
+				r.reportDropped()
+				return
+			}
+
+			batch = append(batch, click)
+			if len(batch) >= r.batchSize {
+				r.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			r.flush(batch)
+			batch = batch[:0]
+		case <-reportTicker.C:
+			r.reportDropped()
+		}
+	}
+}
+
+// reportDropped logs the clicks dropped since the last report, if any.
+func (r *Recorder) reportDropped() {
+	dropped := r.dropped.Load()
+	if dropped == r.reported {
+		return
+	}
+
+	log.Printf("Dropped %d clicks (%d in total)", dropped-r.reported, dropped)
+	r.reported = dropped
+}
+
+func (r *Recorder) flush(batch []models.Click) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+
+	for i := range batch {
+		for _, enricher := range r.enrichers {
+			enricher.Enrich(&batch[i])
+		}
+	}
+
+	if _, err := WriteClicks(ctx, r.clicks, r.counter, batch, r.countBots); err != nil {
+		r.dropped.Add(int64(len(batch)))
+		log.Printf("Failed to record %d clicks: %v", len(batch), err)
+	}
+}
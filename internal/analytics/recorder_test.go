@@ -0,0 +1,283 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yan-cerqueira-unvoid/url-shortener/config"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/mocks"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+func TestRecorder_FlushesBatchOnClose(t *testing.T) {
+	clickStore := new(mocks.ClickStore)
+	urlStore := new(mocks.URLStore)
+
+	clickStore.On("InsertClicks", mock.Anything, mock.MatchedBy(func(clicks []models.Click) bool {
+		return len(clicks) == 3
+	})).Return(nil).Once()
+	urlStore.On("AddClicks", mock.Anything, map[string]int64{"abc123": 2, "def456": 1}).Return(nil).Once()
+
+	recorder := NewRecorder(clickStore, urlStore, config.AnalyticsConfig{BufferSize: 10, BatchSize: 10, FlushInterval: time.Hour})
+	recorder.Start()
+
+	recorder.Record(models.Click{ShortCode: "abc123"})
+	recorder.Record(models.Click{ShortCode: "def456"})
+	recorder.Record(models.Click{ShortCode: "abc123"})
+
+	require.NoError(t, recorder.Close(context.Background()))
+
+	clickStore.AssertExpectations(t)
+	urlStore.AssertExpectations(t)
+	assert.Equal(t, int64(0), recorder.Dropped())
+}
+
+func TestRecorder_FlushesWhenBatchIsFull(t *testing.T) {
+	clickStore := new(mocks.ClickStore)
+	urlStore := new(mocks.URLStore)
+
+	clickStore.On("InsertClicks", mock.Anything, mock.Anything).Return(nil)
+	urlStore.On("AddClicks", mock.Anything, mock.Anything).Return(nil)
+
+	recorder := NewRecorder(clickStore, urlStore, config.AnalyticsConfig{BufferSize: 10, BatchSize: 2, FlushInterval: time.Hour})
+	recorder.Start()
+
+	for i := 0; i < 5; i++ {
+		recorder.Record(models.Click{ShortCode: "abc123"})
+	}
+
+	require.NoError(t, recorder.Close(context.Background()))
+
+	// Two full batches plus the remainder flushed on close.
+	clickStore.AssertNumberOfCalls(t, "InsertClicks", 3)
+	urlStore.AssertNumberOfCalls(t, "AddClicks", 3)
+}
+
+func TestRecorder_FlushesOnInterval(t *testing.T) {
+	clickStore := new(mocks.ClickStore)
+	urlStore := new(mocks.URLStore)
+
+	flushed := make(chan struct{})
+	clickStore.On("InsertClicks", mock.Anything, mock.Anything).Return(nil).Run(func(mock.Arguments) { close(flushed) }).Once()
+	urlStore.On("AddClicks", mock.Anything, map[string]int64{"abc123": 1}).Return(nil).Once()
+
+	recorder := NewRecorder(clickStore, urlStore, config.AnalyticsConfig{BufferSize: 10, BatchSize: 100, FlushInterval: 10 * time.Millisecond})
+	recorder.Start()
+	defer recorder.Close(context.Background()) //nolint:errcheck
+
+	recorder.Record(models.Click{ShortCode: "abc123"})
+
+	select {
+	case <-flushed:
+	case <-time.After(time.Second):
+		t.Fatal("batch was not flushed on interval")
+	}
+}
+
+func TestRecorder_CountsAgainstMemoryStore(t *testing.T) {
+	urlStore := store.NewMemoryURLStore()
+	ctx := context.Background()
+	require.NoError(t, urlStore.Insert(ctx, &models.URL{ShortCode: "abc123", OriginalURL: "https://example.com"}))
+
+	recorder := NewRecorder(store.NewMemoryClickStore(urlStore), urlStore, config.AnalyticsConfig{BufferSize: 10})
+	recorder.Start()
+
+	recorder.Record(models.Click{ShortCode: "abc123"})
+	recorder.Record(models.Click{ShortCode: "abc123"})
+	recorder.Record(models.Click{ShortCode: "missing"})
+
+	require.NoError(t, recorder.Close(ctx))
+
+	url, err := urlStore.GetByCode(ctx, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), url.Clicks)
+}
+
+func TestRecorder_DropsWhenBufferIsFull(t *testing.T) {
+	clickStore := new(mocks.ClickStore)
+	urlStore := new(mocks.URLStore)
+	clickStore.On("InsertClicks", mock.Anything, mock.Anything).Return(nil)
+	urlStore.On("AddClicks", mock.Anything, mock.Anything).Return(nil)
+
+	// Not started, so nothing drains the buffer.
+	recorder := NewRecorder(clickStore, urlStore, config.AnalyticsConfig{BufferSize: 2})
+
+	for i := 0; i < 5; i++ {
+		recorder.Record(models.Click{ShortCode: "abc123"})
+	}
+
+	assert.Equal(t, int64(3), recorder.Dropped())
+
+	recorder.Start()
+	require.NoError(t, recorder.Close(context.Background()))
+	urlStore.AssertCalled(t, "AddClicks", mock.Anything, map[string]int64{"abc123": 2})
+}
+
+func TestRecorder_DropsAfterClose(t *testing.T) {
+	clickStore := new(mocks.ClickStore)
+	urlStore := new(mocks.URLStore)
+
+	recorder := NewRecorder(clickStore, urlStore, config.AnalyticsConfig{BufferSize: 1})
+	recorder.Start()
+	require.NoError(t, recorder.Close(context.Background()))
+
+	recorder.Record(models.Click{ShortCode: "abc123"})
+
+	assert.Equal(t, int64(1), recorder.Dropped())
+	clickStore.AssertNotCalled(t, "InsertClicks", mock.Anything, mock.Anything)
+	urlStore.AssertNotCalled(t, "AddClicks", mock.Anything, mock.Anything)
+}
+
+func TestRecorder_CountsFailedBatchesAsDropped(t *testing.T) {
+	clickStore := new(mocks.ClickStore)
+	urlStore := new(mocks.URLStore)
+	clickStore.On("InsertClicks", mock.Anything, mock.Anything).Return(errors.New("database error"))
+	urlStore.On("AddClicks", mock.Anything, mock.Anything).Return(errors.New("database error"))
+
+	recorder := NewRecorder(clickStore, urlStore, config.AnalyticsConfig{BufferSize: 2, BatchSize: 1})
+	recorder.Start()
+
+	recorder.Record(models.Click{ShortCode: "abc123"})
+	recorder.Record(models.Click{ShortCode: "def456"})
+
+	require.NoError(t, recorder.Close(context.Background()))
+	clickStore.AssertNumberOfCalls(t, "InsertClicks", 2)
+	assert.Equal(t, int64(2), recorder.Dropped())
+}
+
+func TestRecorder_DoesNotCountUnstoredClicks(t *testing.T) {
+	clickStore := new(mocks.ClickStore)
+	urlStore := new(mocks.URLStore)
+	clickStore.On("InsertClicks", mock.Anything, mock.Anything).Return(errors.New("database error"))
+
+	recorder := NewRecorder(clickStore, urlStore, config.AnalyticsConfig{BufferSize: 1})
+	recorder.Start()
+
+	recorder.Record(models.Click{ShortCode: "abc123"})
+
+	require.NoError(t, recorder.Close(context.Background()))
+	urlStore.AssertNotCalled(t, "AddClicks", mock.Anything, mock.Anything)
+	assert.Equal(t, int64(1), recorder.Dropped())
+}
+
+func TestRecorder_CountsUncountedClicksAsDropped(t *testing.T) {
+	clickStore := new(mocks.ClickStore)
+	urlStore := new(mocks.URLStore)
+	clickStore.On("InsertClicks", mock.Anything, mock.Anything).Return(nil)
+	urlStore.On("AddClicks", mock.Anything, mock.Anything).Return(errors.New("database error"))
+
+	recorder := NewRecorder(clickStore, urlStore, config.AnalyticsConfig{BufferSize: 1})
+	recorder.Start()
+
+	recorder.Record(models.Click{ShortCode: "abc123"})
+
+	require.NoError(t, recorder.Close(context.Background()))
+	assert.Equal(t, int64(1), recorder.Dropped())
+}
+
+func TestRecorder_WritesEventsAndCountersTogether(t *testing.T) {
+	db, err := store.OpenSQL(context.Background(), store.DialectSQLite, filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	urlStore := store.NewSQLURLStore(db, store.DialectSQLite)
+	require.NoError(t, urlStore.Insert(context.Background(), &models.URL{OriginalURL: "https://example.com", ShortCode: "abc123"}))
+
+	recorder := NewRecorder(store.NewSQLClickStore(db, store.DialectSQLite), urlStore, config.AnalyticsConfig{BufferSize: 10}, UserAgentEnricher{})
+	recorder.Start()
+
+	recorder.Record(models.Click{ShortCode: "abc123", UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0"})
+	recorder.Record(models.Click{ShortCode: "abc123", UserAgent: "Googlebot/2.1"})
+
+	require.NoError(t, recorder.Close(context.Background()))
+
+	url, err := urlStore.GetByCode(context.Background(), "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), url.Clicks)
+	assert.Equal(t, int64(0), recorder.Dropped())
+}
+
+func TestRecorder_CloseHonoursContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	clickStore := new(mocks.ClickStore)
+	urlStore := new(mocks.URLStore)
+	clickStore.On("InsertClicks", mock.Anything, mock.Anything).Return(nil)
+	urlStore.On("AddClicks", mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) { <-release }).
+		Return(nil)
+
+	recorder := NewRecorder(clickStore, urlStore, config.AnalyticsConfig{BufferSize: 1, BatchSize: 1})
+	recorder.Start()
+	recorder.Record(models.Click{ShortCode: "abc123"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, recorder.Close(ctx), context.DeadlineExceeded)
+}
+
+func TestRecorder_EnrichesAndSkipsBotsInCounters(t *testing.T) {
+	clickStore := new(mocks.ClickStore)
+	urlStore := new(mocks.URLStore)
+
+	clickStore.On("InsertClicks", mock.Anything, mock.MatchedBy(func(clicks []models.Click) bool {
+		return len(clicks) == 2 &&
+			!clicks[0].Bot && clicks[0].Browser == "Firefox" && clicks[0].OS == "Linux" && clicks[0].Device == "desktop" &&
+			clicks[1].Bot && clicks[1].Browser == "Slackbot"
+	})).Return(nil).Once()
+	urlStore.On("AddClicks", mock.Anything, map[string]int64{"abc123": 1}).Return(nil).Once()
+
+	recorder := NewRecorder(clickStore, urlStore, config.AnalyticsConfig{BufferSize: 10}, UserAgentEnricher{})
+	recorder.Start()
+
+	recorder.Record(models.Click{ShortCode: "abc123", UserAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0"})
+	recorder.Record(models.Click{ShortCode: "abc123", UserAgent: "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)"})
+
+	require.NoError(t, recorder.Close(context.Background()))
+
+	clickStore.AssertExpectations(t)
+	urlStore.AssertExpectations(t)
+}
+
+func TestRecorder_CountsBotsWhenConfigured(t *testing.T) {
+	clickStore := new(mocks.ClickStore)
+	urlStore := new(mocks.URLStore)
+
+	clickStore.On("InsertClicks", mock.Anything, mock.Anything).Return(nil)
+	urlStore.On("AddClicks", mock.Anything, map[string]int64{"abc123": 1}).Return(nil).Once()
+
+	recorder := NewRecorder(clickStore, urlStore, config.AnalyticsConfig{BufferSize: 10, CountBots: true}, UserAgentEnricher{})
+	recorder.Start()
+
+	recorder.Record(models.Click{ShortCode: "abc123", UserAgent: "Twitterbot/1.0"})
+
+	require.NoError(t, recorder.Close(context.Background()))
+
+	urlStore.AssertExpectations(t)
+}
+
+func TestRecorder_SkipsCounterWhenBatchIsAllBots(t *testing.T) {
+	clickStore := new(mocks.ClickStore)
+	urlStore := new(mocks.URLStore)
+
+	clickStore.On("InsertClicks", mock.Anything, mock.Anything).Return(nil).Once()
+
+	recorder := NewRecorder(clickStore, urlStore, config.AnalyticsConfig{BufferSize: 10}, UserAgentEnricher{})
+	recorder.Start()
+
+	recorder.Record(models.Click{ShortCode: "abc123", UserAgent: "facebookexternalhit/1.1"})
+
+	require.NoError(t, recorder.Close(context.Background()))
+
+	clickStore.AssertExpectations(t)
+	urlStore.AssertNotCalled(t, "AddClicks", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,35 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+// WriteClicks stores clicks and adds them to the link counters, leaving bot
+// clicks out of the counts unless countBots is set. Clicks already stored
+// are skipped and, with a store.ClickWriter, returned as indexes and not
+// counted again; SQL stores also write the events and counters in one
+// transaction. Otherwise the events are stored first, so a failure never
+// counts clicks that were not recorded, but every click is counted.
+func WriteClicks(ctx context.Context, clicks store.ClickStore, counter store.ClickCounter, batch []models.Click, countBots bool) (duplicates []int, err error) {
+	if writer, ok := clicks.(store.ClickWriter); ok {
+		return writer.WriteClicks(ctx, batch, func(click models.Click) bool {
+			return countBots || !click.Bot
+		})
+	}
+
+	if err := clicks.InsertClicks(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	if counts := CountClicks(batch, countBots); len(counts) > 0 {
+		if err := counter.AddClicks(ctx, counts); err != nil {
+			return nil, fmt.Errorf("clicks stored but not counted: %w", err)
+		}
+	}
+
+	return []int{}, nil
+}
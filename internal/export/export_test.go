@@ -0,0 +1,162 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+var (
+	testColumns = []Column{{"code", TypeString}, {"clicks", TypeInt}, {"bot", TypeBool}, {"expires_at", TypeTimestamp}}
+	testExpiry  = time.Date(2030, 1, 2, 3, 4, 5, 0, time.FixedZone("BRT", -3*60*60))
+)
+
+func writeRows(t *testing.T, format string, rows ...[]any) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, format, testColumns)
+	require.NoError(t, err)
+
+	for _, row := range rows {
+		require.NoError(t, w.Write(row))
+	}
+	require.NoError(t, w.Close())
+
+	return buf.String()
+}
+
+func TestCSVWriter(t *testing.T) {
+	out := writeRows(t, FormatCSV,
+		[]any{"abc", int64(3), false, &testExpiry},
+		[]any{"a,b", int64(0), true, (*time.Time)(nil)},
+	)
+
+	assert.Equal(t, "code,clicks,bot,expires_at\n"+
+		"abc,3,false,2030-01-02T06:04:05Z\n"+
+		"\"a,b\",0,true,\n", out)
+
+	// An empty export still has its header.
+	assert.Equal(t, "code,clicks,bot,expires_at\n", writeRows(t, FormatCSV))
+}
+
+func TestJSONLWriter(t *testing.T) {
+	out := writeRows(t, FormatJSONL,
+		[]any{"abc", int64(3), false, &testExpiry},
+		[]any{"xyz", int64(0), true, (*time.Time)(nil)},
+	)
+
+	assert.Equal(t, `{"code":"abc","clicks":3,"bot":false,"expires_at":"2030-01-02T06:04:05Z"}`+"\n"+
+		`{"code":"xyz","clicks":0,"bot":true,"expires_at":null}`+"\n", out)
+	assert.Empty(t, writeRows(t, FormatJSONL))
+}
+
+func TestColumnarWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, FormatColumnar, testColumns)
+	require.NoError(t, err)
+	w.(*columnarWriter).rowGroupSize = 2
+
+	for i := range 3 {
+		require.NoError(t, w.Write([]any{"code", int64(i), false, (*time.Time)(nil)}))
+	}
+	require.NoError(t, w.Close())
+
+	scanner := bufio.NewScanner(&buf)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.Len(t, lines, 3)
+
+	var header columnarHeader
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &header))
+	assert.Equal(t, columnarHeader{Format: FormatColumnar, Version: ColumnarVersion, Columns: testColumns}, header)
+
+	assert.Equal(t, `{"rows":2,"columns":[["code","code"],[0,1],[false,false],[null,null]]}`, lines[1])
+	assert.Equal(t, `{"rows":1,"columns":[["code"],[2],[false],[null]]}`, lines[2])
+}
+
+func TestNewWriter_UnknownFormat(t *testing.T) {
+	_, err := NewWriter(&bytes.Buffer{}, "parquet", testColumns)
+	assert.Error(t, err)
+	assert.False(t, IsValidFormat("parquet"))
+	assert.Equal(t, "links.columnar.jsonl", Filename("links", FormatColumnar))
+}
+
+func TestWriters_WriteNothingBeforeFirstRow(t *testing.T) {
+	for format := range formats {
+		var buf bytes.Buffer
+		_, err := NewWriter(&buf, format, testColumns)
+		require.NoError(t, err)
+		assert.Zero(t, buf.Len(), format)
+	}
+}
+
+func TestLinksAndClicks(t *testing.T) {
+	ctx := context.Background()
+	urls := store.NewMemoryURLStore()
+	clicks := store.NewMemoryClickStore(urls)
+	day := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	deletedAt := day.Add(time.Hour)
+	for _, url := range []*models.URL{
+		{OriginalURL: "https://example.com/a", ShortCode: "a", Workspace: "sales", CreatedAt: day},
+		{OriginalURL: "https://example.com/b", ShortCode: "b", Workspace: "sales", CreatedAt: day.Add(24 * time.Hour), DeletedAt: &deletedAt},
+		{OriginalURL: "https://example.com/c", ShortCode: "c", CreatedAt: day.Add(48 * time.Hour)},
+	} {
+		require.NoError(t, urls.Insert(ctx, url))
+	}
+	require.NoError(t, clicks.InsertClicks(ctx, []models.Click{
+		{ShortCode: "a", Timestamp: day.Add(time.Hour)},
+		{ShortCode: "b", Timestamp: day.Add(25 * time.Hour)},
+		{ShortCode: "c", Timestamp: day.Add(26 * time.Hour)},
+		{ShortCode: "a", Timestamp: day.Add(72 * time.Hour)},
+	}))
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, FormatCSV, LinkColumns)
+	require.NoError(t, err)
+	written, err := Links(ctx, urls, Filter{Workspace: "sales"}, w)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.EqualValues(t, 2, written)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[1], ",a,https://example.com/a,sales,")
+	assert.Contains(t, lines[2], ",b,https://example.com/b,sales,")
+	assert.True(t, strings.HasSuffix(lines[2], deletedAt.Format(time.RFC3339Nano)))
+
+	buf.Reset()
+	w, err = NewWriter(&buf, FormatJSONL, LinkColumns)
+	require.NoError(t, err)
+	written, err = Links(ctx, urls, Filter{From: day.Add(24 * time.Hour)}, w)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.EqualValues(t, 2, written)
+	assert.Contains(t, buf.String(), `"workspace":"default"`)
+
+	// Clicks of the workspace in the range, whichever day their link was made.
+	buf.Reset()
+	w, err = NewWriter(&buf, FormatJSONL, ClickColumns)
+	require.NoError(t, err)
+	written, err = Clicks(ctx, clicks, Filter{Workspace: "sales", To: day.Add(48 * time.Hour)}, w)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.EqualValues(t, 2, written)
+	assert.NotContains(t, buf.String(), `"short_code":"c"`)
+
+	written, err = Clicks(ctx, clicks, Filter{}, newCSVWriter(&bytes.Buffer{}, ClickColumns))
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, written)
+}
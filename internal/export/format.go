@@ -0,0 +1,309 @@
+// Package export streams links and click events out in formats a data
+// warehouse can load: CSV, JSON Lines and a columnar dump. Rows are written
+// as they are read, so an export never holds more than one row group in
+// memory.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	FormatCSV      = "csv"
+	FormatJSONL    = "jsonl"
+	FormatColumnar = "columnar"
+)
+
+// Column types, as listed in the columnar header.
+const (
+	TypeString    = "string"
+	TypeInt       = "int64"
+	TypeBool      = "bool"
+	TypeTimestamp = "timestamp"
+)
+
+// RowGroupSize is how many rows a columnar row group holds.
+const RowGroupSize = 10000
+
+// ColumnarVersion is the version of the columnar layout, written in its
+// header so readers can tell future layouts apart.
+const ColumnarVersion = 1
+
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Writer writes rows of a fixed set of columns. Values follow the column
+// order and are a string, int64, bool, time.Time or *time.Time, where a nil
+// *time.Time is a null. Nothing reaches the underlying writer before the
+// first row, and Close flushes what is buffered.
+type Writer interface {
+	Write(values []any) error
+	Close() error
+}
+
+type format struct {
+	contentType string
+	extension   string
+	newWriter   func(w io.Writer, columns []Column) Writer
+}
+
+var formats = map[string]format{
+	FormatCSV:      {"text/csv; charset=utf-8", "csv", newCSVWriter},
+	FormatJSONL:    {"application/x-ndjson", "jsonl", newJSONLWriter},
+	FormatColumnar: {"application/x-ndjson", "columnar.jsonl", newColumnarWriter},
+}
+
+// IsValidFormat reports whether name is one of the export formats.
+func IsValidFormat(name string) bool {
+	_, ok := formats[name]
+	return ok
+}
+
+// NewWriter returns a Writer of columns in the named format.
+func NewWriter(w io.Writer, name string, columns []Column) (Writer, error) {
+	format, ok := formats[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown export format %q, expected csv, jsonl or columnar", name)
+	}
+
+	return format.newWriter(w, columns), nil
+}
+
+// ContentType returns the media type of the named format.
+func ContentType(name string) string {
+	return formats[name].contentType
+}
+
+// Filename returns a file name for an export of base in the named format.
+func Filename(base, name string) string {
+	return base + "." + formats[name].extension
+}
+
+// csvWriter writes a header row, then one record per row. Nulls are empty
+// cells and times are RFC 3339 in UTC.
+type csvWriter struct {
+	writer  *csv.Writer
+	columns []Column
+	started bool
+}
+
+func newCSVWriter(w io.Writer, columns []Column) Writer {
+	return &csvWriter{writer: csv.NewWriter(w), columns: columns}
+}
+
+func (w *csvWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+
+	header := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		header[i] = column.Name
+	}
+
+	return w.writer.Write(header)
+}
+
+func (w *csvWriter) Write(values []any) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = formatCell(value)
+	}
+
+	return w.writer.Write(record)
+}
+
+func (w *csvWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	w.writer.Flush()
+
+	return w.writer.Error()
+}
+
+func formatCell(value any) string {
+	switch value := normalize(value).(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case bool:
+		return strconv.FormatBool(value)
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// jsonlWriter writes one JSON object per row, keyed by column name in column
+// order.
+type jsonlWriter struct {
+	writer  *bufio.Writer
+	columns []Column
+}
+
+func newJSONLWriter(w io.Writer, columns []Column) Writer {
+	return &jsonlWriter{writer: bufio.NewWriter(w), columns: columns}
+}
+
+func (w *jsonlWriter) Write(values []any) error {
+	w.writer.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			w.writer.WriteByte(',')
+		}
+
+		name, err := json.Marshal(w.columns[i].Name)
+		if err != nil {
+			return err
+		}
+		encoded, err := json.Marshal(normalize(value))
+		if err != nil {
+			return err
+		}
+
+		w.writer.Write(name)
+		w.writer.WriteByte(':')
+		w.writer.Write(encoded)
+	}
+	w.writer.WriteString("}\n")
+
+	// bufio.Writer keeps the first write error and returns it from here on.
+	_, err := w.writer.Write(nil)
+
+	return err
+}
+
+func (w *jsonlWriter) Close() error {
+	return w.writer.Flush()
+}
+
+// columnarWriter lays rows out column by column, like the row groups of a
+// Parquet file, in JSON Lines any JSON reader can load. The first line is a
+// header naming the columns and their types:
+//
+//	{"format":"columnar","version":1,"columns":[{"name":"id","type":"string"},...]}
+//
+// Each following line is a row group of up to RowGroupSize rows, holding one
+// array of values per column in header order:
+//
+//	{"rows":2,"columns":[["id1","id2"],...]}
+type columnarWriter struct {
+	encoder      *json.Encoder
+	writer       *bufio.Writer
+	columns      []Column
+	group        [][]any
+	rows         int
+	rowGroupSize int
+	started      bool
+}
+
+type columnarHeader struct {
+	Format  string   `json:"format"`
+	Version int      `json:"version"`
+	Columns []Column `json:"columns"`
+}
+
+type columnarGroup struct {
+	Rows    int     `json:"rows"`
+	Columns [][]any `json:"columns"`
+}
+
+func newColumnarWriter(w io.Writer, columns []Column) Writer {
+	buffered := bufio.NewWriter(w)
+
+	return &columnarWriter{
+		encoder:      json.NewEncoder(buffered),
+		writer:       buffered,
+		columns:      columns,
+		group:        make([][]any, len(columns)),
+		rowGroupSize: RowGroupSize,
+	}
+}
+
+func (w *columnarWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+
+	return w.encoder.Encode(columnarHeader{Format: FormatColumnar, Version: ColumnarVersion, Columns: w.columns})
+}
+
+func (w *columnarWriter) Write(values []any) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	for i, value := range values {
+		w.group[i] = append(w.group[i], normalize(value))
+	}
+	w.rows++
+
+	if w.rows >= w.rowGroupSize {
+		return w.flushGroup()
+	}
+
+	return nil
+}
+
+func (w *columnarWriter) flushGroup() error {
+	if w.rows == 0 {
+		return nil
+	}
+
+	if err := w.encoder.Encode(columnarGroup{Rows: w.rows, Columns: w.group}); err != nil {
+		return err
+	}
+
+	w.group = make([][]any, len(w.columns))
+	w.rows = 0
+
+	return nil
+}
+
+func (w *columnarWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if err := w.flushGroup(); err != nil {
+		return err
+	}
+
+	return w.writer.Flush()
+}
+
+// normalize turns nil time pointers into nulls and times into UTC.
+func normalize(value any) any {
+	switch value := value.(type) {
+	case *time.Time:
+		if value == nil {
+			return nil
+		}
+		return value.UTC()
+	case time.Time:
+		return value.UTC()
+	case int:
+		return int64(value)
+	default:
+		return value
+	}
+}
@@ -0,0 +1,120 @@
+package export
+
+import (
+	"context"
+	"time"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+// Filter selects what an export holds. From is inclusive and To exclusive;
+// zero times leave that side of the range open. The range applies to the
+// creation time of links and the timestamp of clicks.
+type Filter struct {
+	Workspace string // empty exports every workspace
+	From      time.Time
+	To        time.Time
+}
+
+var LinkColumns = []Column{
+	{"id", TypeString},
+	{"short_code", TypeString},
+	{"original_url", TypeString},
+	{"workspace", TypeString},
+	{"created_by", TypeString},
+	{"custom_code", TypeBool},
+	{"redirect_type", TypeInt},
+	{"clicks", TypeInt},
+	{"expires_at", TypeTimestamp},
+	{"created_at", TypeTimestamp},
+	{"updated_at", TypeTimestamp},
+	{"deleted_at", TypeTimestamp},
+}
+
+var ClickColumns = []Column{
+	{"id", TypeString},
+	{"short_code", TypeString},
+	{"timestamp", TypeTimestamp},
+	{"referrer", TypeString},
+	{"user_agent", TypeString},
+	{"ip", TypeString},
+	{"accept_language", TypeString},
+	{"country", TypeString},
+	{"region", TypeString},
+	{"city", TypeString},
+	{"browser", TypeString},
+	{"os", TypeString},
+	{"device", TypeString},
+	{"bot", TypeBool},
+}
+
+func linkValues(url models.URL) []any {
+	return []any{
+		url.ID.Hex(),
+		url.ShortCode,
+		url.OriginalURL,
+		models.WorkspaceOrDefault(url.Workspace),
+		url.CreatedBy,
+		url.CustomCode,
+		int64(url.RedirectType),
+		url.Clicks,
+		url.ExpiresAt,
+		url.CreatedAt,
+		url.UpdatedAt,
+		url.DeletedAt,
+	}
+}
+
+func clickValues(click models.Click) []any {
+	return []any{
+		click.ID.Hex(),
+		click.ShortCode,
+		click.Timestamp,
+		click.Referrer,
+		click.UserAgent,
+		click.IP,
+		click.AcceptLanguage,
+		click.Country,
+		click.Region,
+		click.City,
+		click.Browser,
+		click.OS,
+		click.Device,
+		click.Bot,
+	}
+}
+
+// Links writes the links matching filter to w, deleted ones included, oldest
+// first, and returns how many were written. w is not closed.
+func Links(ctx context.Context, urls store.URLStore, filter Filter, w Writer) (int64, error) {
+	opts := store.ListOptions{
+		Status:      store.StatusAll,
+		Workspace:   filter.Workspace,
+		CreatedFrom: filter.From,
+		CreatedTo:   filter.To,
+	}
+
+	var written int64
+	err := urls.ScanURLs(ctx, opts, func(url models.URL) error {
+		written++
+		return w.Write(linkValues(url))
+	})
+
+	return written, err
+}
+
+// Clicks writes the clicks matching filter to w, oldest first, and returns
+// how many were written. A workspace filter selects the clicks on its links,
+// deleted ones included. w is not closed.
+func Clicks(ctx context.Context, clicks store.ClickStore, filter Filter, w Writer) (int64, error) {
+	clickFilter := store.ClickFilter{Workspace: filter.Workspace, From: filter.From, To: filter.To}
+
+	var written int64
+	err := clicks.ScanClicks(ctx, clickFilter, func(click models.Click) error {
+		written++
+		return w.Write(clickValues(click))
+	})
+
+	return written, err
+}
@@ -0,0 +1,94 @@
+// Package geo resolves client IPs to locations using a local database in
+// MaxMind's MMDB format, such as GeoLite2 City or Country. Lookups never leave
+// the process.
+package geo
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Location is where an IP address is registered. Region is the ISO 3166-2
+// code of the first subdivision (e.g. "BR-SP"); City is the English name.
+// Parts missing from the database are left empty.
+type Location struct {
+	Country string
+	Region  string
+	City    string
+}
+
+type cityRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+// Reader looks up locations. The zero value, as returned by Open for an
+// empty path, finds nothing, so geolocation is optional.
+type Reader struct {
+	db *maxminddb.Reader
+}
+
+// Open memory-maps the database at path. An empty path returns a Reader whose
+// lookups always miss.
+func Open(path string) (*Reader, error) {
+	if path == "" {
+		return &Reader{}, nil
+	}
+
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database: %w", err)
+	}
+
+	return &Reader{db: db}, nil
+}
+
+// Enabled reports whether a database is loaded.
+func (r *Reader) Enabled() bool {
+	return r.db != nil
+}
+
+// Lookup returns the location of ip, reporting false when the address is
+// invalid, not in the database, or no database is loaded.
+func (r *Reader) Lookup(ip string) (Location, bool) {
+	if r.db == nil {
+		return Location{}, false
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return Location{}, false
+	}
+
+	var record cityRecord
+	if err := r.db.Lookup(parsed, &record); err != nil {
+		return Location{}, false
+	}
+
+	location := Location{
+		Country: record.Country.ISOCode,
+		City:    record.City.Names["en"],
+	}
+	if len(record.Subdivisions) > 0 && record.Subdivisions[0].ISOCode != "" && location.Country != "" {
+		location.Region = location.Country + "-" + record.Subdivisions[0].ISOCode
+	}
+
+	return location, location != Location{}
+}
+
+func (r *Reader) Close() error {
+	if r.db == nil {
+		return nil
+	}
+
+	return r.db.Close()
+}
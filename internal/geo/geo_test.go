@@ -0,0 +1,101 @@
+package geo
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxmind/mmdbwriter"
+	"github.com/maxmind/mmdbwriter/mmdbtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFixture builds a small City database covering documentation ranges.
+func writeFixture(t *testing.T) string {
+	t.Helper()
+
+	writer, err := mmdbwriter.New(mmdbwriter.Options{
+		DatabaseType:            "GeoLite2-City",
+		IncludeReservedNetworks: true,
+	})
+	require.NoError(t, err)
+
+	records := map[string]mmdbtype.Map{
+		"192.0.2.0/24": {
+			"country":      mmdbtype.Map{"iso_code": mmdbtype.String("BR")},
+			"subdivisions": mmdbtype.Slice{mmdbtype.Map{"iso_code": mmdbtype.String("SP")}},
+			"city":         mmdbtype.Map{"names": mmdbtype.Map{"en": mmdbtype.String("São Paulo")}},
+		},
+		"198.51.100.0/24": {
+			"country": mmdbtype.Map{"iso_code": mmdbtype.String("US")},
+		},
+		"2001:db8::/32": {
+			"country":      mmdbtype.Map{"iso_code": mmdbtype.String("DE")},
+			"subdivisions": mmdbtype.Slice{mmdbtype.Map{"iso_code": mmdbtype.String("BE")}},
+			"city":         mmdbtype.Map{"names": mmdbtype.Map{"en": mmdbtype.String("Berlin")}},
+		},
+	}
+	for cidr, record := range records {
+		_, network, err := net.ParseCIDR(cidr)
+		require.NoError(t, err)
+		require.NoError(t, writer.Insert(network, record))
+	}
+
+	path := filepath.Join(t.TempDir(), "fixture.mmdb")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	_, err = writer.WriteTo(file)
+	require.NoError(t, err)
+
+	return path
+}
+
+func TestReader_Lookup(t *testing.T) {
+	reader, err := Open(writeFixture(t))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	assert.True(t, reader.Enabled())
+
+	tests := []struct {
+		name     string
+		ip       string
+		expected Location
+		found    bool
+	}{
+		{"City record", "192.0.2.10", Location{Country: "BR", Region: "BR-SP", City: "São Paulo"}, true},
+		{"Country only", "198.51.100.7", Location{Country: "US"}, true},
+		{"IPv6", "2001:db8::1", Location{Country: "DE", Region: "DE-BE", City: "Berlin"}, true},
+		{"Not in database", "203.0.113.1", Location{}, false},
+		{"Invalid address", "not-an-ip", Location{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location, found := reader.Lookup(tt.ip)
+
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.expected, location)
+		})
+	}
+}
+
+func TestOpen_WithoutDatabaseIsNoop(t *testing.T) {
+	reader, err := Open("")
+	require.NoError(t, err)
+
+	assert.False(t, reader.Enabled())
+
+	_, found := reader.Lookup("192.0.2.10")
+	assert.False(t, found)
+	assert.NoError(t, reader.Close())
+}
+
+func TestOpen_MissingFile(t *testing.T) {
+	_, err := Open(filepath.Join(t.TempDir(), "missing.mmdb"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+)
+
+const (
+	APIKeyHeader     = "X-API-Key"
+	accessContextKey = "access"
+	clientContextKey = "client"
+
+	anonymousUser = "anonymous"
+)
+
+type APIKeyAuthenticatorInterface interface {
+	Authenticate(rawKey string) (*models.APIKey, error)
+}
+
+// TokenVerifierInterface checks bearer tokens from an identity provider.
+type TokenVerifierInterface interface {
+	Verify(rawToken string) (user, workspace string, err error)
+}
+
+type AccessResolverInterface interface {
+	Access(user, workspace string) (models.Access, error)
+}
+
+// RequireAuth rejects requests without a valid API key or, when tokens is not
+// nil, identity provider token. API keys are read from the X-API-Key header or
+// from an "Authorization: Bearer" header; bearer credentials shaped like a JWT
+// go to tokens instead. The request then acts as the key owner or token
+// subject in their workspace, with the role they currently hold there.
+func RequireAuth(apiKeys APIKeyAuthenticatorInterface, tokens TokenVerifierInterface, resolver AccessResolverInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		credential, isToken := requestCredential(c)
+		if credential == "" {
+			c.Header("WWW-Authenticate", "Bearer")
+			abortWithError(c, http.StatusUnauthorized, CodeUnauthorized, "API key or bearer token required")
+			return
+		}
+
+		var user, workspace, client string
+		if isToken && tokens != nil {
+			var err error
+			if user, workspace, err = tokens.Verify(credential); err != nil {
+				c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+				respondError(c, err)
+				return
+			}
+			client = "user:" + user
+		} else {
+			key, err := apiKeys.Authenticate(credential)
+			if err != nil {
+				c.Header("WWW-Authenticate", "Bearer")
+				respondError(c, err)
+				return
+			}
+			user, workspace = key.Owner, models.WorkspaceOrDefault(key.Workspace)
+			client = "key:" + key.Prefix
+		}
+
+		access, err := resolver.Access(user, workspace)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.Set(accessContextKey, access)
+		c.Set(clientContextKey, client)
+		c.Next()
+	}
+}
+
+// AllowAnonymous lets every request act as the owner of the default
+// workspace. It replaces RequireAuth when authentication is disabled.
+func AllowAnonymous() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(accessContextKey, models.Access{
+			User:      anonymousUser,
+			Workspace: models.DefaultWorkspace,
+			Role:      models.RoleOwner,
+		})
+		c.Next()
+	}
+}
+
+// requestCredential returns the API key or token of the request, reporting
+// whether it is a bearer token in JWT form. API keys never contain dots.
+func requestCredential(c *gin.Context) (string, bool) {
+	if key := strings.TrimSpace(c.GetHeader(APIKeyHeader)); key != "" {
+		return key, false
+	}
+
+	scheme, credential, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	credential = strings.TrimSpace(credential)
+
+	return credential, strings.Count(credential, ".") == 2
+}
+
+// requestAccess returns what the request may do. Without authentication
+// middleware it is the zero Access, which the services refuse.
+func requestAccess(c *gin.Context) models.Access {
+	value, ok := c.Get(accessContextKey)
+	if !ok {
+		return models.Access{}
+	}
+
+	return value.(models.Access)
+}
@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/ratelimit"
+)
+
+// maxBulkBodyBytes caps the size of a bulk request, whatever its format.
+const maxBulkBodyBytes = 10 << 20
+
+const (
+	BulkStatusCreated  = "created"
+	BulkStatusExisting = "existing"
+	BulkStatusError    = "error"
+)
+
+type BulkResultResponse struct {
+	Row    int           `json:"row"` // 1-based position in the request, not counting a CSV header
+	Status string        `json:"status"`
+	Link   *LinkResponse `json:"link,omitempty"`
+	Error  string        `json:"error,omitempty"`
+	Code   string        `json:"code,omitempty"`
+}
+
+type BulkShortenResponse struct {
+	Results  []BulkResultResponse `json:"results"`
+	Created  int                  `json:"created"`
+	Existing int                  `json:"existing"`
+	Failed   int                  `json:"failed"`
+}
+
+// bulkRow is one row of a bulk request, or the reason it could not be read.
+type bulkRow struct {
+	request ShortenURLRequest
+	err     error
+}
+
+// BulkShortenHandler shortens many URLs at once. The body is a JSON array of
+// shorten requests, a CSV document (text/csv) or a multipart form whose
+// "file" field holds either. Rows succeed or fail on their own, so the
+// response is 200 with a result per row unless the request as a whole is
+// rejected. Each row that reaches the service costs one token of the "bulk"
+// rate limit, so a request is refused whole when the client has fewer left.
+func BulkShortenHandler(urlService URLServiceInterface, urlParser URLParserInterface, rateLimits RateLimitStoreInterface, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBulkBodyBytes)
+
+		rows, err := readBulkRows(c)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				abortWithError(c, http.StatusRequestEntityTooLarge, CodeInvalidRequest,
+					fmt.Sprintf("request body must not exceed %d bytes", tooLarge.Limit))
+				return
+			}
+
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		}
+		if len(rows) == 0 {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, "no links to shorten")
+			return
+		}
+
+		// Only rows that parse reach the service; entryRows maps its entries
+		// back to their rows.
+		var entries []models.BulkEntry
+		var entryRows []int
+		for i := range rows {
+			if rows[i].err != nil {
+				continue
+			}
+
+			parseResult, err := urlParser.Parse(rows[i].request.URL)
+			if err != nil {
+				rows[i].err = err
+				continue
+			}
+
+			entries = append(entries, models.BulkEntry{OriginalURL: parseResult.Normalized, Options: rows[i].request.options()})
+			entryRows = append(entryRows, i)
+		}
+
+		var results []models.BulkResult
+		if len(entries) > 0 {
+			if !spendRateLimit(c, rateLimits, "bulk", limit, len(entries)) {
+				return
+			}
+
+			results, err = urlService.ShortenURLs(requestAccess(c), entries)
+			if err != nil {
+				respondError(c, err)
+				return
+			}
+		}
+
+		response := BulkShortenResponse{Results: make([]BulkResultResponse, len(rows))}
+		for i, row := range rows {
+			response.Results[i] = BulkResultResponse{Row: i + 1, Status: BulkStatusError, Error: errorMessage(row.err), Code: CodeInvalidRequest}
+		}
+
+		for j, result := range results {
+			row := &response.Results[entryRows[j]]
+
+			switch {
+			case result.Err != nil:
+				_, row.Code, row.Error = describeError(c, result.Err)
+			case result.Existing:
+				link := newLinkResponse(c, result.URL)
+				*row = BulkResultResponse{Row: row.Row, Status: BulkStatusExisting, Link: &link}
+			default:
+				link := newLinkResponse(c, result.URL)
+				*row = BulkResultResponse{Row: row.Row, Status: BulkStatusCreated, Link: &link}
+			}
+		}
+
+		for _, result := range response.Results {
+			switch result.Status {
+			case BulkStatusCreated:
+				response.Created++
+			case BulkStatusExisting:
+				response.Existing++
+			default:
+				response.Failed++
+			}
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
+
+func readBulkRows(c *gin.Context) ([]bulkRow, error) {
+	switch c.ContentType() {
+	case "text/csv":
+		return readCSVRows(c.Request.Body)
+	case "multipart/form-data":
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, fmt.Errorf("a multipart bulk request needs a file field: %w", err)
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		if strings.EqualFold(filepath.Ext(header.Filename), ".json") || header.Header.Get("Content-Type") == "application/json" {
+			return readJSONRows(file)
+		}
+
+		return readCSVRows(file)
+	default:
+		return readJSONRows(c.Request.Body)
+	}
+}
+
+// readJSONRows reads an array of shorten requests. Malformed JSON fails the
+// whole request; a missing url only fails its row.
+func readJSONRows(r io.Reader) ([]bulkRow, error) {
+	var requests []ShortenURLRequest
+	if err := json.NewDecoder(r).Decode(&requests); err != nil {
+		return nil, fmt.Errorf("body must be a JSON array of links: %w", err)
+	}
+
+	rows := make([]bulkRow, len(requests))
+	for i, request := range requests {
+		rows[i].request = request
+		if request.URL == "" {
+			rows[i].err = errors.New("url is required")
+		}
+	}
+
+	return rows, nil
+}
+
+// csvColumns are the columns a CSV import may have; only url is required.
+var csvColumns = map[string]func(request *ShortenURLRequest, value string) error{
+	"url": func(request *ShortenURLRequest, value string) error {
+		request.URL = value
+		return nil
+	},
+	"custom_code": func(request *ShortenURLRequest, value string) error {
+		request.CustomCode = value
+		return nil
+	},
+	"redirect_type": func(request *ShortenURLRequest, value string) error {
+		redirectType, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("redirect_type must be a number")
+		}
+		request.RedirectType = redirectType
+		return nil
+	},
+	"expires_at": func(request *ShortenURLRequest, value string) error {
+		expiresAt, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return errors.New("expires_at must be an RFC 3339 time")
+		}
+		request.ExpiresAt = &expiresAt
+		return nil
+	},
+	"ttl": func(request *ShortenURLRequest, value string) error {
+		ttl, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return errors.New("ttl must be a number of seconds")
+		}
+		request.TTL = ttl
+		return nil
+	},
+	"never_expires": func(request *ShortenURLRequest, value string) error {
+		neverExpires, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("never_expires must be true or false")
+		}
+		request.NeverExpires = neverExpires
+		return nil
+	},
+}
+
+// readCSVRows reads a CSV document whose header names the columns. Empty
+// cells leave their option unset.
+func readCSVRows(r io.Reader) ([]bulkRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	hasURL := false
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if _, ok := csvColumns[column]; !ok {
+			return nil, fmt.Errorf("unknown CSV column %q", column)
+		}
+		header[i] = column
+		hasURL = hasURL || column == "url"
+	}
+	if !hasURL {
+		return nil, errors.New("CSV header must have a url column")
+	}
+
+	var rows []bulkRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		rows = append(rows, parseCSVRecord(header, record))
+	}
+}
+
+func parseCSVRecord(header, record []string) bulkRow {
+	var row bulkRow
+	if len(record) > len(header) {
+		row.err = fmt.Errorf("row has %d fields but the header has %d", len(record), len(header))
+		return row
+	}
+
+	for i, value := range record {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if err := csvColumns[header[i]](&row.request, value); err != nil {
+			row.err = err
+			return row
+		}
+	}
+
+	if row.request.URL == "" {
+		row.err = errors.New("url is required")
+	}
+
+	return row
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/mocks"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/parser"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/ratelimit"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/services"
+)
+
+func newBulkParser() *mocks.URLParser {
+	mockURLParser := new(mocks.URLParser)
+	mockURLParser.On("Parse", "https://example.com/1").Return(&parser.URLParseResult{Normalized: "https://example.com/1"}, nil)
+	mockURLParser.On("Parse", "https://example.com/2").Return(&parser.URLParseResult{Normalized: "https://example.com/2"}, nil)
+	mockURLParser.On("Parse", "https://example.com/3").Return(&parser.URLParseResult{Normalized: "https://example.com/3"}, nil)
+	mockURLParser.On("Parse", "not a url").Return(nil, errors.New("invalid URL"))
+
+	return mockURLParser
+}
+
+func serveBulk(t *testing.T, urlService *mocks.URLService, req *http.Request) (*httptest.ResponseRecorder, BulkShortenResponse) {
+	t.Helper()
+
+	router := setupRouter()
+	router.POST("/api/v1/links/bulk", withAccess(editorAccess), BulkShortenHandler(urlService, newBulkParser(), nil, ratelimit.Limit{}))
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	var response BulkShortenResponse
+	if resp.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+	}
+
+	return resp, response
+}
+
+func TestBulkShortenHandler_JSON(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+	mockURLService.On("ShortenURLs", editorAccess, []models.BulkEntry{
+		{OriginalURL: "https://example.com/1", Options: models.ShortenOptions{CustomCode: "one"}},
+		{OriginalURL: "https://example.com/2", Options: models.ShortenOptions{TTL: time.Hour}},
+		{OriginalURL: "https://example.com/3"},
+	}).Return([]models.BulkResult{
+		{URL: &models.URL{OriginalURL: "https://example.com/1", ShortCode: "one"}},
+		{URL: &models.URL{OriginalURL: "https://example.com/2", ShortCode: "abc123"}, Existing: true},
+		{Err: &services.Error{Kind: services.ErrQuotaExceeded, Message: "user alice has reached its quota of 2 active links"}},
+	}, nil)
+
+	body := `[
+		{"url": "https://example.com/1", "custom_code": "one"},
+		{"url": "https://example.com/2", "ttl": 3600},
+		{"url": "not a url"},
+		{"custom_code": "nourl"},
+		{"url": "https://example.com/3"}
+	]`
+	req, _ := http.NewRequest("POST", "/api/v1/links/bulk", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, response := serveBulk(t, mockURLService, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, 1, response.Created)
+	assert.Equal(t, 1, response.Existing)
+	assert.Equal(t, 3, response.Failed)
+	require.Len(t, response.Results, 5)
+
+	assert.Equal(t, BulkStatusCreated, response.Results[0].Status)
+	assert.Equal(t, "one", response.Results[0].Link.ShortCode)
+	assert.Equal(t, BulkStatusExisting, response.Results[1].Status)
+	assert.Equal(t, BulkResultResponse{Row: 3, Status: BulkStatusError, Error: "invalid URL", Code: CodeInvalidRequest}, response.Results[2])
+	assert.Equal(t, BulkResultResponse{Row: 4, Status: BulkStatusError, Error: "url is required", Code: CodeInvalidRequest}, response.Results[3])
+	assert.Equal(t, 5, response.Results[4].Row)
+	assert.Equal(t, CodeQuotaExceeded, response.Results[4].Code)
+	mockURLService.AssertExpectations(t)
+}
+
+func TestBulkShortenHandler_CSV(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mockURLService := new(mocks.URLService)
+	mockURLService.On("ShortenURLs", editorAccess, []models.BulkEntry{
+		{OriginalURL: "https://example.com/1", Options: models.ShortenOptions{CustomCode: "one", RedirectType: 302}},
+		{OriginalURL: "https://example.com/2", Options: models.ShortenOptions{ExpiresAt: &expiresAt}},
+	}).Return([]models.BulkResult{
+		{URL: &models.URL{OriginalURL: "https://example.com/1", ShortCode: "one"}},
+		{URL: &models.URL{OriginalURL: "https://example.com/2", ShortCode: "abc123"}},
+	}, nil)
+
+	body := "url,custom_code,redirect_type,expires_at\n" +
+		"https://example.com/1,one,302,\n" +
+		"https://example.com/2,,,2030-01-02T03:04:05Z\n" +
+		"https://example.com/3,,soon,\n"
+	req, _ := http.NewRequest("POST", "/api/v1/links/bulk", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "text/csv")
+
+	resp, response := serveBulk(t, mockURLService, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, 2, response.Created)
+	assert.Equal(t, 1, response.Failed)
+	assert.Equal(t, "redirect_type must be a number", response.Results[2].Error)
+	mockURLService.AssertExpectations(t)
+}
+
+func TestBulkShortenHandler_MultipartUpload(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+	mockURLService.On("ShortenURLs", editorAccess, []models.BulkEntry{{OriginalURL: "https://example.com/1"}}).
+		Return([]models.BulkResult{{URL: &models.URL{OriginalURL: "https://example.com/1", ShortCode: "abc123"}}}, nil)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	file, err := writer.CreateFormFile("file", "links.csv")
+	require.NoError(t, err)
+	_, err = file.Write([]byte("url\nhttps://example.com/1\n"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req, _ := http.NewRequest("POST", "/api/v1/links/bulk", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, response := serveBulk(t, mockURLService, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, 1, response.Created)
+	mockURLService.AssertExpectations(t)
+}
+
+func TestBulkShortenHandler_RejectsRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"malformed JSON", "application/json", `{"url": "https://example.com/1"}`, http.StatusBadRequest},
+		{"empty array", "application/json", `[]`, http.StatusBadRequest},
+		{"unknown CSV column", "text/csv", "url,color\nhttps://example.com/1,red\n", http.StatusBadRequest},
+		{"CSV without url", "text/csv", "custom_code\none\n", http.StatusBadRequest},
+		{"too large", "application/json", `["` + string(bytes.Repeat([]byte("a"), maxBulkBodyBytes)) + `"]`, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockURLService := new(mocks.URLService)
+
+			req, _ := http.NewRequest("POST", "/api/v1/links/bulk", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			resp, _ := serveBulk(t, mockURLService, req)
+
+			assert.Equal(t, tt.status, resp.Code)
+			mockURLService.AssertNotCalled(t, "ShortenURLs")
+		})
+	}
+}
+
+func TestBulkShortenHandler_ServiceError(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+	mockURLService.On("ShortenURLs", editorAccess, []models.BulkEntry{{OriginalURL: "https://example.com/1"}}).
+		Return(nil, &services.Error{Kind: services.ErrInvalid, Message: "a bulk request may hold at most 500 links"})
+
+	req, _ := http.NewRequest("POST", "/api/v1/links/bulk", bytes.NewBufferString(`[{"url": "https://example.com/1"}]`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := serveBulk(t, mockURLService, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestBulkShortenHandler_RateLimitPerLink(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+	mockURLService.On("ShortenURLs", editorAccess, []models.BulkEntry{
+		{OriginalURL: "https://example.com/1"},
+		{OriginalURL: "https://example.com/2"},
+	}).Return([]models.BulkResult{
+		{URL: &models.URL{OriginalURL: "https://example.com/1", ShortCode: "abc123"}},
+		{URL: &models.URL{OriginalURL: "https://example.com/2", ShortCode: "def456"}},
+	}, nil).Once()
+
+	router := setupRouter()
+	router.POST("/api/v1/links/bulk", withAccess(editorAccess),
+		BulkShortenHandler(mockURLService, newBulkParser(), ratelimit.NewMemoryStore(), ratelimit.PerMinute(1, 3)))
+
+	send := func() *httptest.ResponseRecorder {
+		body := `[{"url": "https://example.com/1"}, {"url": "not a url"}, {"url": "https://example.com/2"}]`
+		req, _ := http.NewRequest("POST", "/api/v1/links/bulk", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// Rows that fail to parse are free, so the first request costs two of
+	// the three tokens and the second needs two with one left.
+	resp := send()
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "1", resp.Header().Get("X-RateLimit-Remaining"))
+
+	resp = send()
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "60", resp.Header().Get("Retry-After"))
+	mockURLService.AssertExpectations(t)
+}
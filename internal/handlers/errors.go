@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/services"
+)
+
+const (
+	CodeInvalidRequest = "invalid_request"
+	CodeNotFound       = "not_found"
+	CodeExpired        = "expired"
+	CodeConflict       = "conflict"
+	CodeUnavailable    = "unavailable"
+	CodeUnauthorized   = "unauthorized"
+	CodeForbidden      = "forbidden"
+	CodeRateLimited    = "rate_limited"
+	CodeQuotaExceeded  = "quota_exceeded"
+	CodeInternal       = "internal_error"
+)
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+var errorStatuses = []struct {
+	kind   error
+	status int
+	code   string
+}{
+	{services.ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{services.ErrExpired, http.StatusGone, CodeExpired},
+	{services.ErrConflict, http.StatusConflict, CodeConflict},
+	{services.ErrInvalid, http.StatusBadRequest, CodeInvalidRequest},
+	{services.ErrUnavailable, http.StatusServiceUnavailable, CodeUnavailable},
+	{services.ErrUnauthorized, http.StatusUnauthorized, CodeUnauthorized},
+	{services.ErrForbidden, http.StatusForbidden, CodeForbidden},
+	{services.ErrQuotaExceeded, http.StatusForbidden, CodeQuotaExceeded},
+}
+
+// respondError writes the JSON error body for err, choosing the status from
+// the service error kind. Server-side failures are logged and their details
+// kept out of the response.
+func respondError(c *gin.Context, err error) {
+	status, code, message := describeError(c, err)
+	abortWithError(c, status, code, message)
+}
+
+// describeError maps err to its status, code and client-safe message,
+// logging server-side failures.
+func describeError(c *gin.Context, err error) (int, string, string) {
+	for _, mapping := range errorStatuses {
+		if !errors.Is(err, mapping.kind) {
+			continue
+		}
+
+		message := err.Error()
+		var serviceErr *services.Error
+		if errors.As(err, &serviceErr) {
+			message = serviceErr.Message
+		}
+
+		if mapping.status >= http.StatusInternalServerError {
+			log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		}
+
+		return mapping.status, mapping.code, message
+	}
+
+	log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+
+	return http.StatusInternalServerError, CodeInternal, "internal server error"
+}
+
+func abortWithError(c *gin.Context, status int, code string, message string) {
+	c.AbortWithStatusJSON(status, ErrorResponse{Error: message, Code: code})
+}
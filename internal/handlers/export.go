@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/export"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+)
+
+type ExportServiceInterface interface {
+	ExportLinks(access models.Access, filter export.Filter, w export.Writer) (int64, error)
+	ExportClicks(access models.Access, filter export.Filter, w export.Writer) (int64, error)
+}
+
+type exportFunc func(access models.Access, filter export.Filter, w export.Writer) (int64, error)
+
+// exportWriteTimeout bounds each write of an export rather than the whole
+// download, so large exports may take as long as they need while a stalled
+// client is cut off.
+const exportWriteTimeout = 30 * time.Second
+
+// deadlineWriter renews the connection's write deadline before every write.
+type deadlineWriter struct {
+	io.Writer
+	controller *http.ResponseController
+}
+
+func (w deadlineWriter) Write(p []byte) (int, error) {
+	w.controller.SetWriteDeadline(time.Now().Add(exportWriteTimeout)) //nolint:errcheck
+
+	return w.Writer.Write(p)
+}
+
+// ExportLinksHandler streams the links of the caller's workspace, deleted
+// ones included. See serveExport for the query parameters.
+func ExportLinksHandler(exportService ExportServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		serveExport(c, "links", export.LinkColumns, exportService.ExportLinks)
+	}
+}
+
+// ExportClicksHandler streams the click events on links of the caller's
+// workspace. See serveExport for the query parameters.
+func ExportClicksHandler(exportService ExportServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		serveExport(c, "clicks", export.ClickColumns, exportService.ExportClicks)
+	}
+}
+
+// serveExport streams an export as a file download. Supported query
+// parameters are format (csv, jsonl or columnar; csv by default) and from and
+// to (RFC 3339 or YYYY-MM-DD). Errors found before the first row is sent get
+// a JSON error response; later ones can only cut the download short.
+func serveExport(c *gin.Context, name string, columns []export.Column, run exportFunc) {
+	format := c.DefaultQuery("format", export.FormatCSV)
+	if !export.IsValidFormat(format) {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, "query parameter format must be csv, jsonl or columnar")
+		return
+	}
+
+	from, err := queryTime(c, "from")
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		return
+	}
+
+	to, err := queryTime(c, "to")
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", `attachment; filename="`+export.Filename(name, format)+`"`)
+
+	// Exports outlast the server write timeout meant for ordinary requests.
+	out := deadlineWriter{Writer: c.Writer, controller: http.NewResponseController(c.Writer)}
+	w, err := export.NewWriter(out, format, columns)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	access := requestAccess(c)
+	if _, err := run(access, export.Filter{From: from, To: to}, w); err != nil {
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			respondError(c, err)
+			return
+		}
+
+		log.Printf("%s %s: export cut short: %v", c.Request.Method, c.Request.URL.Path, err)
+		c.Abort()
+		return
+	}
+
+	if err := w.Close(); err != nil {
+		log.Printf("%s %s: export cut short: %v", c.Request.Method, c.Request.URL.Path, err)
+	}
+	c.Status(http.StatusOK)
+}
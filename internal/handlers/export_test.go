@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/export"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/mocks"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/services"
+)
+
+func serveExportRequest(exportService *mocks.ExportService, target string) *httptest.ResponseRecorder {
+	router := setupRouter()
+	router.GET("/api/v1/export/links", withAccess(editorAccess), ExportLinksHandler(exportService))
+	router.GET("/api/v1/export/clicks", withAccess(editorAccess), ExportClicksHandler(exportService))
+
+	req, _ := http.NewRequest("GET", target, nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	return resp
+}
+
+func TestExportLinksHandler(t *testing.T) {
+	from := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	mockExportService := new(mocks.ExportService)
+	mockExportService.On("ExportLinks", editorAccess, export.Filter{From: from}, mock.Anything).
+		Run(func(args mock.Arguments) {
+			w := args.Get(2).(export.Writer)
+			values := make([]any, len(export.LinkColumns))
+			for i := range values {
+				values[i] = ""
+			}
+			values[1] = "abc123"
+			assert.NoError(t, w.Write(values))
+		}).
+		Return(int64(1), nil)
+
+	resp := serveExportRequest(mockExportService, "/api/v1/export/links?format=jsonl&from=2026-05-01")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/x-ndjson", resp.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="links.jsonl"`, resp.Header().Get("Content-Disposition"))
+
+	var row map[string]any
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &row))
+	assert.Equal(t, "abc123", row["short_code"])
+	mockExportService.AssertExpectations(t)
+}
+
+func TestExportClicksHandler_DefaultsToCSV(t *testing.T) {
+	mockExportService := new(mocks.ExportService)
+	mockExportService.On("ExportClicks", editorAccess, export.Filter{}, mock.Anything).Return(int64(0), nil)
+
+	resp := serveExportRequest(mockExportService, "/api/v1/export/clicks")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="clicks.csv"`, resp.Header().Get("Content-Disposition"))
+	assert.Equal(t, "id,short_code,timestamp,referrer,user_agent,ip,accept_language,country,region,city,browser,os,device,bot\n", resp.Body.String())
+}
+
+func TestExportHandler_Errors(t *testing.T) {
+	mockExportService := new(mocks.ExportService)
+	mockExportService.On("ExportLinks", editorAccess, mock.Anything, mock.Anything).
+		Return(int64(0), &services.Error{Kind: services.ErrForbidden, Message: "this requires the viewer role"})
+
+	resp := serveExportRequest(mockExportService, "/api/v1/export/links")
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header().Get("Content-Type"))
+	assert.Empty(t, resp.Header().Get("Content-Disposition"))
+
+	resp = serveExportRequest(mockExportService, "/api/v1/export/links?format=parquet")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = serveExportRequest(mockExportService, "/api/v1/export/links?to=yesterday")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestExportHandler_FailureAfterRowsCutsDownloadShort(t *testing.T) {
+	mockExportService := new(mocks.ExportService)
+	mockExportService.On("ExportClicks", editorAccess, export.Filter{}, mock.Anything).
+		Run(func(args mock.Arguments) {
+			w := args.Get(2).(export.Writer)
+			values := make([]any, len(export.ClickColumns))
+			for i := range values {
+				values[i] = ""
+			}
+			// Fill more than the CSV buffer so rows reach the client.
+			for range 1000 {
+				assert.NoError(t, w.Write(values))
+			}
+		}).
+		Return(int64(1000), errors.New("connection reset"))
+
+	resp := serveExportRequest(mockExportService, "/api/v1/export/clicks")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header().Get("Content-Type"))
+}
@@ -2,20 +2,60 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
 	"github.com/yan-cerqueira-unvoid/url-shortener/internal/parser"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/qr"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
 )
 
 type ShortenURLRequest struct {
-	URL        string `json:"url" binding:"required"`
-	CustomCode string `json:"custom_code,omitempty"`
+	URL          string     `json:"url" binding:"required"`
+	CustomCode   string     `json:"custom_code,omitempty"`
+	RedirectType int        `json:"redirect_type,omitempty"` // 301, 302, 307 or 308
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	TTL          int64      `json:"ttl,omitempty"` // seconds until the link expires
+	NeverExpires bool       `json:"never_expires,omitempty"`
+	// QR, when set, adds a QR code of the short URL to the response.
+	QR *QRCodeRequest `json:"qr,omitempty"`
+}
+
+func (r ShortenURLRequest) options() models.ShortenOptions {
+	return models.ShortenOptions{
+		CustomCode:   r.CustomCode,
+		RedirectType: r.RedirectType,
+		ExpiresAt:    r.ExpiresAt,
+		TTL:          time.Duration(r.TTL) * time.Second,
+		NeverExpires: r.NeverExpires,
+	}
 }
 
 type URLServiceInterface interface {
-	ShortenURL(originalURL string, customCode string) (*models.URL, error)
+	ShortenURL(access models.Access, originalURL string, opts models.ShortenOptions) (*models.URL, error)
+	ShortenURLs(access models.Access, entries []models.BulkEntry) ([]models.BulkResult, error)
 	GetURL(shortCode string) (*models.URL, error)
+	GetLink(access models.Access, shortCode string) (*models.URL, error)
+	ListLinks(access models.Access, opts store.ListOptions) ([]models.URL, int64, error)
+	UpdateLink(access models.Access, shortCode string, update models.LinkUpdate) (*models.URL, error)
+	DeleteLink(access models.Access, shortCode string) error
+	RestoreLink(access models.Access, shortCode string) (*models.URL, error)
+	Usage(access models.Access) (*models.UsageReport, error)
+}
+
+type StatsServiceInterface interface {
+	LinkStats(access models.Access, shortCode string, opts models.StatsOptions) (*models.LinkStats, error)
+}
+
+type WorkspaceServiceInterface interface {
+	ListMembers(access models.Access) ([]models.Membership, error)
+	SetMember(access models.Access, user, role string) (*models.Membership, error)
+	RemoveMember(access models.Access, user string) error
+}
+
+type ClickRecorderInterface interface {
+	Record(click models.Click)
 }
 
 type URLParserInterface interface {
@@ -30,50 +70,101 @@ func HomeHandler() gin.HandlerFunc {
 			"endpoints": []string{
 				"POST /shorten",
 				"GET /:shortCode",
+				"GET /:shortCode/qr",
+				"GET /api/v1/links",
+				"POST /api/v1/links/bulk",
+				"GET /api/v1/links/:code",
+				"PATCH /api/v1/links/:code",
+				"DELETE /api/v1/links/:code",
+				"POST /api/v1/links/:code/restore",
+				"GET /api/v1/links/:code/stats",
+				"GET /api/v1/usage",
+				"GET /api/v1/export/links",
+				"GET /api/v1/export/clicks",
+				"GET /api/v1/workspace/members",
+				"PUT /api/v1/workspace/members/:user",
+				"DELETE /api/v1/workspace/members/:user",
 			},
 		})
 	}
 }
 
-func ShortenURLHandler(urlService URLServiceInterface, urlParser URLParserInterface) gin.HandlerFunc {
+// ShortenURLHandler creates a link. links builds the URLs encoded in QR
+// codes.
+func ShortenURLHandler(urlService URLServiceInterface, urlParser URLParserInterface, links LinkURLs) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var request ShortenURLRequest
 		if err := c.ShouldBindJSON(&request); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
 			return
 		}
 
+		var qrOptions qr.Options
+		if request.QR != nil {
+			var err error
+			if qrOptions, err = request.QR.options(); err != nil {
+				abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, "qr: "+err.Error())
+				return
+			}
+		}
+
 		parseResult, err := urlParser.Parse(request.URL)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
 			return
 		}
 
-		url, err := urlService.ShortenURL(parseResult.Normalized, request.CustomCode)
+		url, err := urlService.ShortenURL(requestAccess(c), parseResult.Normalized, request.options())
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			respondError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{
-			"original_url": url.OriginalURL,
-			"short_code":   url.ShortCode,
-			"short_url":    c.Request.Host + "/" + url.ShortCode,
-			"expires_at":   url.ExpiresAt,
-		})
+		response := gin.H{
+			"original_url":  url.OriginalURL,
+			"short_code":    url.ShortCode,
+			"short_url":     c.Request.Host + "/" + url.ShortCode,
+			"expires_at":    url.ExpiresAt,
+			"redirect_type": url.RedirectType,
+		}
+
+		if request.QR != nil {
+			if response["qr_code"], err = qrDataURI(c, links, url.ShortCode, qrOptions); err != nil {
+				respondError(c, err)
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, response)
 	}
 }
 
-func RedirectHandler(urlService URLServiceInterface) gin.HandlerFunc {
+// RedirectHandler resolves the short code and redirects. The click is handed
+// to clickRecorder, which must not block.
+func RedirectHandler(urlService URLServiceInterface, clickRecorder ClickRecorderInterface) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		shortCode := c.Param("shortCode")
 
 		url, err := urlService.GetURL(shortCode)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			respondError(c, err)
 			return
 		}
 
-		c.Redirect(http.StatusMovedPermanently, url.OriginalURL)
+		clickRecorder.Record(models.Click{
+			ShortCode:      url.ShortCode,
+			Timestamp:      time.Now(),
+			Referrer:       c.Request.Referer(),
+			UserAgent:      c.Request.UserAgent(),
+			IP:             c.ClientIP(),
+			AcceptLanguage: c.GetHeader("Accept-Language"),
+		})
+
+		status := url.RedirectType
+		if status == 0 {
+			status = http.StatusMovedPermanently
+		}
+
+		c.Redirect(status, url.OriginalURL)
 	}
 }
@@ -11,9 +11,11 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/yan-cerqueira-unvoid/url-shortener/internal/mocks"
 	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
 	"github.com/yan-cerqueira-unvoid/url-shortener/internal/parser"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/services"
 )
 
 func setupRouter() *gin.Engine {
@@ -45,7 +47,7 @@ func TestShortenURLHandler_Success(t *testing.T) {
 	mockURLParser := new(mocks.URLParser)
 
 	router := setupRouter()
-	router.POST("/shorten", ShortenURLHandler(mockURLService, mockURLParser))
+	router.POST("/shorten", ShortenURLHandler(mockURLService, mockURLParser, LinkURLs{}))
 
 	expiresAt := time.Now().Add(24 * time.Hour)
 	validURL := "https://example.com"
@@ -61,10 +63,10 @@ func TestShortenURLHandler_Success(t *testing.T) {
 		IsValid:     true,
 	}, nil)
 
-	mockURLService.On("ShortenURL", normalizedURL, "").Return(&models.URL{
+	mockURLService.On("ShortenURL", mock.Anything, normalizedURL, models.ShortenOptions{}).Return(&models.URL{
 		OriginalURL: validURL,
 		ShortCode:   shortCode,
-		ExpiresAt:   expiresAt,
+		ExpiresAt:   &expiresAt,
 	}, nil)
 
 	requestBody := ShortenURLRequest{
@@ -97,7 +99,7 @@ func TestShortenURLHandler_CustomCode(t *testing.T) {
 	mockURLParser := new(mocks.URLParser)
 
 	router := setupRouter()
-	router.POST("/shorten", ShortenURLHandler(mockURLService, mockURLParser))
+	router.POST("/shorten", ShortenURLHandler(mockURLService, mockURLParser, LinkURLs{}))
 
 	expiresAt := time.Now().Add(24 * time.Hour)
 	validURL := "https://example.com"
@@ -113,10 +115,10 @@ func TestShortenURLHandler_CustomCode(t *testing.T) {
 		IsValid:     true,
 	}, nil)
 
-	mockURLService.On("ShortenURL", normalizedURL, customCode).Return(&models.URL{
+	mockURLService.On("ShortenURL", mock.Anything, normalizedURL, models.ShortenOptions{CustomCode: customCode}).Return(&models.URL{
 		OriginalURL: validURL,
 		ShortCode:   customCode,
-		ExpiresAt:   expiresAt,
+		ExpiresAt:   &expiresAt,
 	}, nil)
 
 	requestBody := ShortenURLRequest{
@@ -145,12 +147,54 @@ func TestShortenURLHandler_CustomCode(t *testing.T) {
 	mockURLService.AssertExpectations(t)
 }
 
+func TestShortenURLHandler_ExpiryOptions(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+	mockURLParser := new(mocks.URLParser)
+
+	router := setupRouter()
+	router.POST("/shorten", ShortenURLHandler(mockURLService, mockURLParser, LinkURLs{}))
+
+	validURL := "https://example.com"
+
+	mockURLParser.On("Parse", validURL).Return(&parser.URLParseResult{
+		OriginalURL: validURL,
+		Normalized:  validURL,
+		Domain:      "example.com",
+		Params:      map[string]string{},
+		IsValid:     true,
+	}, nil)
+
+	mockURLService.On("ShortenURL", mock.Anything, validURL, models.ShortenOptions{TTL: time.Hour}).Return(&models.URL{
+		OriginalURL: validURL,
+		ShortCode:   "abc123",
+	}, nil)
+
+	jsonData := []byte(`{"url": "https://example.com", "ttl": 3600}`)
+	req, _ := http.NewRequest("POST", "/shorten", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response map[string]any
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+
+	assert.Nil(t, err)
+	assert.Nil(t, response["expires_at"])
+
+	mockURLParser.AssertExpectations(t)
+	mockURLService.AssertExpectations(t)
+}
+
 func TestShortenURLHandler_InvalidJSON(t *testing.T) {
 	mockURLService := new(mocks.URLService)
 	mockURLParser := new(mocks.URLParser)
 
 	router := setupRouter()
-	router.POST("/shorten", ShortenURLHandler(mockURLService, mockURLParser))
+	router.POST("/shorten", ShortenURLHandler(mockURLService, mockURLParser, LinkURLs{}))
 
 	req, _ := http.NewRequest("POST", "/shorten", bytes.NewBuffer([]byte(`{invalid json}`)))
 	req.Header.Set("Content-Type", "application/json")
@@ -166,7 +210,7 @@ func TestShortenURLHandler_URLParseError(t *testing.T) {
 	mockURLParser := new(mocks.URLParser)
 
 	router := setupRouter()
-	router.POST("/shorten", ShortenURLHandler(mockURLService, mockURLParser))
+	router.POST("/shorten", ShortenURLHandler(mockURLService, mockURLParser, LinkURLs{}))
 
 	invalidURL := "invalid-url"
 
@@ -194,7 +238,7 @@ func TestShortenURLHandler_ShortenURLError(t *testing.T) {
 	mockURLParser := new(mocks.URLParser)
 
 	router := setupRouter()
-	router.POST("/shorten", ShortenURLHandler(mockURLService, mockURLParser))
+	router.POST("/shorten", ShortenURLHandler(mockURLService, mockURLParser, LinkURLs{}))
 
 	validURL := "https://example.com"
 	normalizedURL := "https://example.com"
@@ -208,7 +252,11 @@ func TestShortenURLHandler_ShortenURLError(t *testing.T) {
 		IsValid:     true,
 	}, nil)
 
-	mockURLService.On("ShortenURL", normalizedURL, "").Return(nil, errors.New("database error"))
+	mockURLService.On("ShortenURL", mock.Anything, normalizedURL, models.ShortenOptions{}).Return(nil, &services.Error{
+		Kind:    services.ErrUnavailable,
+		Message: "storage unavailable",
+		Err:     errors.New("database error"),
+	})
 
 	requestBody := ShortenURLRequest{
 		URL: validURL,
@@ -223,7 +271,14 @@ func TestShortenURLHandler_ShortenURLError(t *testing.T) {
 
 	router.ServeHTTP(resp, req)
 
-	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+
+	var response ErrorResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+
+	assert.Nil(t, err)
+	assert.Equal(t, CodeUnavailable, response.Code)
+	assert.NotContains(t, response.Error, "database error")
 
 	mockURLParser.AssertExpectations(t)
 	mockURLService.AssertExpectations(t)
@@ -231,9 +286,10 @@ func TestShortenURLHandler_ShortenURLError(t *testing.T) {
 
 func TestRedirectHandler_Success(t *testing.T) {
 	mockURLService := new(mocks.URLService)
+	mockClickRecorder := new(mocks.ClickRecorder)
 
 	router := setupRouter()
-	router.GET("/:shortCode", RedirectHandler(mockURLService))
+	router.GET("/:shortCode", RedirectHandler(mockURLService, mockClickRecorder))
 
 	shortCode := "abc123"
 	originalURL := "https://example.com"
@@ -242,8 +298,20 @@ func TestRedirectHandler_Success(t *testing.T) {
 		OriginalURL: originalURL,
 		ShortCode:   shortCode,
 	}, nil)
+	mockClickRecorder.On("Record", mock.MatchedBy(func(click models.Click) bool {
+		return click.ShortCode == shortCode &&
+			click.Referrer == "https://referrer.example/" &&
+			click.UserAgent == "test-agent" &&
+			click.IP == "192.0.2.1" &&
+			click.AcceptLanguage == "en-US" &&
+			!click.Timestamp.IsZero()
+	})).Return()
 
 	req, _ := http.NewRequest("GET", "/"+shortCode, nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("Referer", "https://referrer.example/")
+	req.Header.Set("User-Agent", "test-agent")
+	req.Header.Set("Accept-Language", "en-US")
 	resp := httptest.NewRecorder()
 
 	router.ServeHTTP(resp, req)
@@ -252,17 +320,46 @@ func TestRedirectHandler_Success(t *testing.T) {
 	assert.Equal(t, originalURL, resp.Header().Get("Location"))
 
 	mockURLService.AssertExpectations(t)
+	mockClickRecorder.AssertExpectations(t)
+}
+
+func TestRedirectHandler_IgnoresForwardedForFromUntrustedProxy(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+	mockClickRecorder := new(mocks.ClickRecorder)
+
+	router := setupRouter()
+	_ = router.SetTrustedProxies(nil)
+	router.GET("/:shortCode", RedirectHandler(mockURLService, mockClickRecorder))
+
+	mockURLService.On("GetURL", "abc123").Return(&models.URL{
+		OriginalURL: "https://example.com",
+		ShortCode:   "abc123",
+	}, nil)
+	mockClickRecorder.On("Record", mock.MatchedBy(func(click models.Click) bool {
+		return click.IP == "192.0.2.1"
+	})).Return()
+
+	req, _ := http.NewRequest("GET", "/abc123", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.9")
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusMovedPermanently, resp.Code)
+	mockClickRecorder.AssertExpectations(t)
 }
 
 func TestRedirectHandler_NotFound(t *testing.T) {
 	mockURLService := new(mocks.URLService)
 
 	router := setupRouter()
-	router.GET("/:shortCode", RedirectHandler(mockURLService))
+	mockClickRecorder := new(mocks.ClickRecorder)
+	router.GET("/:shortCode", RedirectHandler(mockURLService, mockClickRecorder))
 
 	shortCode := "nonexistent"
 
-	mockURLService.On("GetURL", shortCode).Return(nil, errors.New("short URL not found"))
+	mockURLService.On("GetURL", shortCode).Return(nil, &services.Error{Kind: services.ErrNotFound, Message: "short URL not found"})
 
 	req, _ := http.NewRequest("GET", "/"+shortCode, nil)
 	resp := httptest.NewRecorder()
@@ -272,4 +369,124 @@ func TestRedirectHandler_NotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, resp.Code)
 
 	mockURLService.AssertExpectations(t)
+	mockClickRecorder.AssertNotCalled(t, "Record", mock.Anything)
+}
+
+func TestRedirectHandler_ErrorStatuses(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		status   int
+		code     string
+		response string
+	}{
+		{
+			name:     "Expired",
+			err:      &services.Error{Kind: services.ErrExpired, Message: "URL has expired"},
+			status:   http.StatusGone,
+			code:     CodeExpired,
+			response: "URL has expired",
+		},
+		{
+			name:     "Unavailable",
+			err:      &services.Error{Kind: services.ErrUnavailable, Message: "storage unavailable", Err: errors.New("connection refused")},
+			status:   http.StatusServiceUnavailable,
+			code:     CodeUnavailable,
+			response: "storage unavailable",
+		},
+		{
+			name:     "Unexpected",
+			err:      errors.New("boom"),
+			status:   http.StatusInternalServerError,
+			code:     CodeInternal,
+			response: "internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockURLService := new(mocks.URLService)
+
+			router := setupRouter()
+			router.GET("/:shortCode", RedirectHandler(mockURLService, new(mocks.ClickRecorder)))
+
+			mockURLService.On("GetURL", "abc123").Return(nil, tt.err)
+
+			req, _ := http.NewRequest("GET", "/abc123", nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.status, resp.Code)
+
+			var response ErrorResponse
+			err := json.Unmarshal(resp.Body.Bytes(), &response)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.code, response.Code)
+			assert.Equal(t, tt.response, response.Error)
+		})
+	}
+}
+
+func TestShortenURLHandler_CodeTaken(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+	mockURLParser := new(mocks.URLParser)
+
+	router := setupRouter()
+	router.POST("/shorten", ShortenURLHandler(mockURLService, mockURLParser, LinkURLs{}))
+
+	validURL := "https://example.com"
+
+	mockURLParser.On("Parse", validURL).Return(&parser.URLParseResult{
+		OriginalURL: validURL,
+		Normalized:  validURL,
+		Domain:      "example.com",
+		Params:      map[string]string{},
+		IsValid:     true,
+	}, nil)
+
+	mockURLService.On("ShortenURL", mock.Anything, validURL, models.ShortenOptions{CustomCode: "taken"}).Return(nil, services.ErrCodeTaken)
+
+	jsonData, _ := json.Marshal(ShortenURLRequest{URL: validURL, CustomCode: "taken"})
+	req, _ := http.NewRequest("POST", "/shorten", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	var response ErrorResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+
+	assert.Nil(t, err)
+	assert.Equal(t, CodeConflict, response.Code)
+	assert.Equal(t, "custom short code already in use", response.Error)
+}
+
+func TestRedirectHandler_RedirectTypes(t *testing.T) {
+	for _, status := range []int{http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect} {
+		mockURLService := new(mocks.URLService)
+
+		router := setupRouter()
+		mockClickRecorder := new(mocks.ClickRecorder)
+		router.GET("/:shortCode", RedirectHandler(mockURLService, mockClickRecorder))
+
+		mockClickRecorder.On("Record", mock.Anything).Return()
+		mockURLService.On("GetURL", "abc123").Return(&models.URL{
+			OriginalURL:  "https://example.com",
+			ShortCode:    "abc123",
+			RedirectType: status,
+		}, nil)
+
+		req, _ := http.NewRequest("GET", "/abc123", nil)
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, status, resp.Code)
+		assert.Equal(t, "https://example.com", resp.Header().Get("Location"))
+	}
 }
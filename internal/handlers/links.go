@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/services"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+type UpdateLinkRequest struct {
+	URL          string     `json:"url,omitempty"`
+	RedirectType int        `json:"redirect_type,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	TTL          int64      `json:"ttl,omitempty"` // seconds until the link expires
+	NeverExpires bool       `json:"never_expires,omitempty"`
+}
+
+type LinkResponse struct {
+	*models.URL
+	ShortURL string `json:"short_url"`
+}
+
+type ListLinksResponse struct {
+	Links  []LinkResponse `json:"links"`
+	Total  int64          `json:"total"`
+	Limit  int64          `json:"limit"`
+	Offset int64          `json:"offset"`
+}
+
+func newLinkResponse(c *gin.Context, url *models.URL) LinkResponse {
+	return LinkResponse{URL: url, ShortURL: c.Request.Host + "/" + url.ShortCode}
+}
+
+func GetLinkHandler(urlService URLServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		url, err := urlService.GetLink(requestAccess(c), c.Param("code"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, newLinkResponse(c, url))
+	}
+}
+
+// ListLinksHandler serves a page of links. Supported query parameters are
+// limit, offset, q (search), created_by and status (active, expired, deleted).
+func ListLinksHandler(urlService URLServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, err := queryInt(c, "limit")
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		}
+
+		offset, err := queryInt(c, "offset")
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		}
+
+		opts := services.ClampListOptions(store.ListOptions{
+			Limit:     limit,
+			Offset:    offset,
+			Search:    c.Query("q"),
+			CreatedBy: c.Query("created_by"),
+			Status:    c.Query("status"),
+		})
+
+		urls, total, err := urlService.ListLinks(requestAccess(c), opts)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		links := make([]LinkResponse, 0, len(urls))
+		for i := range urls {
+			links = append(links, newLinkResponse(c, &urls[i]))
+		}
+
+		c.JSON(http.StatusOK, ListLinksResponse{
+			Links:  links,
+			Total:  total,
+			Limit:  opts.Limit,
+			Offset: opts.Offset,
+		})
+	}
+}
+
+func UpdateLinkHandler(urlService URLServiceInterface, urlParser URLParserInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request UpdateLinkRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		}
+
+		update := models.LinkUpdate{
+			RedirectType: request.RedirectType,
+			ExpiresAt:    request.ExpiresAt,
+			TTL:          time.Duration(request.TTL) * time.Second,
+			NeverExpires: request.NeverExpires,
+		}
+
+		if request.URL != "" {
+			parseResult, err := urlParser.Parse(request.URL)
+			if err != nil {
+				abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+				return
+			}
+			update.OriginalURL = parseResult.Normalized
+		}
+
+		url, err := urlService.UpdateLink(requestAccess(c), c.Param("code"), update)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, newLinkResponse(c, url))
+	}
+}
+
+func DeleteLinkHandler(urlService URLServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := urlService.DeleteLink(requestAccess(c), c.Param("code")); err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func RestoreLinkHandler(urlService URLServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		url, err := urlService.RestoreLink(requestAccess(c), c.Param("code"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, newLinkResponse(c, url))
+	}
+}
+
+func queryInt(c *gin.Context, key string) (int64, error) {
+	value := c.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("query parameter %s must be an integer", key)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/mocks"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/parser"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/services"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+func TestGetLinkHandler_Success(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+
+	router := setupRouter()
+	router.GET("/api/v1/links/:code", GetLinkHandler(mockURLService))
+
+	mockURLService.On("GetLink", mock.Anything, "abc123").Return(&models.URL{
+		OriginalURL: "https://example.com",
+		ShortCode:   "abc123",
+		Clicks:      7,
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/links/abc123", nil)
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response map[string]any
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "abc123", response["short_code"])
+	assert.Equal(t, float64(7), response["clicks"])
+	assert.Contains(t, response, "short_url")
+
+	mockURLService.AssertExpectations(t)
+	mockURLService.AssertNotCalled(t, "GetURL", "abc123")
+}
+
+func TestGetLinkHandler_NotFound(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+
+	router := setupRouter()
+	router.GET("/api/v1/links/:code", GetLinkHandler(mockURLService))
+
+	mockURLService.On("GetLink", mock.Anything, "missing").Return(nil, &services.Error{Kind: services.ErrNotFound, Message: "link not found"})
+
+	req, _ := http.NewRequest("GET", "/api/v1/links/missing", nil)
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestListLinksHandler_Success(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+
+	router := setupRouter()
+	router.GET("/api/v1/links", ListLinksHandler(mockURLService))
+
+	expectedOpts := store.ListOptions{Limit: 2, Offset: 4, Search: "example", Status: store.StatusActive}
+	mockURLService.On("ListLinks", mock.Anything, expectedOpts).Return([]models.URL{
+		{OriginalURL: "https://example.com/a", ShortCode: "a"},
+		{OriginalURL: "https://example.com/b", ShortCode: "b"},
+	}, int64(9), nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/links?limit=2&offset=4&q=example&status=active", nil)
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response ListLinksResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+
+	assert.Nil(t, err)
+	assert.Len(t, response.Links, 2)
+	assert.Equal(t, int64(9), response.Total)
+	assert.Equal(t, int64(2), response.Limit)
+	assert.Equal(t, int64(4), response.Offset)
+
+	mockURLService.AssertExpectations(t)
+}
+
+func TestListLinksHandler_InvalidLimit(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+
+	router := setupRouter()
+	router.GET("/api/v1/links", ListLinksHandler(mockURLService))
+
+	req, _ := http.NewRequest("GET", "/api/v1/links?limit=ten", nil)
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	mockURLService.AssertNotCalled(t, "ListLinks")
+}
+
+func TestUpdateLinkHandler_Success(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+	mockURLParser := new(mocks.URLParser)
+
+	router := setupRouter()
+	router.PATCH("/api/v1/links/:code", UpdateLinkHandler(mockURLService, mockURLParser))
+
+	mockURLParser.On("Parse", "example.org/new").Return(&parser.URLParseResult{
+		OriginalURL: "https://example.org/new",
+		Normalized:  "https://example.org/new",
+		IsValid:     true,
+	}, nil)
+
+	mockURLService.On("UpdateLink", mock.Anything, "abc123", models.LinkUpdate{
+		OriginalURL: "https://example.org/new",
+		TTL:         time.Hour,
+	}).Return(&models.URL{OriginalURL: "https://example.org/new", ShortCode: "abc123"}, nil)
+
+	req, _ := http.NewRequest("PATCH", "/api/v1/links/abc123", bytes.NewBufferString(`{"url": "example.org/new", "ttl": 3600}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response map[string]any
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.org/new", response["original_url"])
+
+	mockURLParser.AssertExpectations(t)
+	mockURLService.AssertExpectations(t)
+}
+
+func TestUpdateLinkHandler_DeletedLink(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+	mockURLParser := new(mocks.URLParser)
+
+	router := setupRouter()
+	router.PATCH("/api/v1/links/:code", UpdateLinkHandler(mockURLService, mockURLParser))
+
+	mockURLService.On("UpdateLink", mock.Anything, "abc123", models.LinkUpdate{NeverExpires: true}).
+		Return(nil, &services.Error{Kind: services.ErrConflict, Message: "link is deleted"})
+
+	req, _ := http.NewRequest("PATCH", "/api/v1/links/abc123", bytes.NewBufferString(`{"never_expires": true}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	mockURLParser.AssertNotCalled(t, "Parse", "")
+}
+
+func TestDeleteAndRestoreLinkHandlers(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+
+	router := setupRouter()
+	router.DELETE("/api/v1/links/:code", DeleteLinkHandler(mockURLService))
+	router.POST("/api/v1/links/:code/restore", RestoreLinkHandler(mockURLService))
+
+	mockURLService.On("DeleteLink", mock.Anything, "abc123").Return(nil)
+	mockURLService.On("RestoreLink", mock.Anything, "abc123").Return(&models.URL{ShortCode: "abc123"}, nil)
+
+	req, _ := http.NewRequest("DELETE", "/api/v1/links/abc123", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	req, _ = http.NewRequest("POST", "/api/v1/links/abc123/restore", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	mockURLService.AssertExpectations(t)
+}
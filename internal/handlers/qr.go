@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/qr"
+)
+
+// QRCodeRequest holds the image options of a QR code, all optional: the
+// query parameters of GET /:shortCode/qr or the qr object of a shorten
+// request. Colors are hex, as RGB, RRGGBB or RRGGBBAA.
+type QRCodeRequest struct {
+	Format     string `json:"format,omitempty" form:"format"` // png or svg
+	Size       int    `json:"size,omitempty" form:"size"`     // pixels
+	Level      string `json:"level,omitempty" form:"level"`   // error correction: L, M, Q or H
+	Margin     *int   `json:"margin,omitempty" form:"margin"` // quiet zone, in modules
+	Foreground string `json:"fg,omitempty" form:"fg"`
+	Background string `json:"bg,omitempty" form:"bg"`
+}
+
+func (r QRCodeRequest) options() (qr.Options, error) {
+	opts := qr.DefaultOptions()
+
+	if r.Format != "" {
+		opts.Format = strings.ToLower(r.Format)
+	}
+	if r.Size != 0 {
+		opts.Size = r.Size
+	}
+	if r.Level != "" {
+		opts.Level = strings.ToUpper(r.Level)
+	}
+	if r.Margin != nil {
+		opts.Margin = *r.Margin
+	}
+
+	var err error
+	if r.Foreground != "" {
+		if opts.Foreground, err = qr.ParseColor(r.Foreground); err != nil {
+			return qr.Options{}, err
+		}
+	}
+	if r.Background != "" {
+		if opts.Background, err = qr.ParseColor(r.Background); err != nil {
+			return qr.Options{}, err
+		}
+	}
+
+	return opts, opts.Validate()
+}
+
+// QRCodeHandler renders a QR code of a link's short URL. It resolves the link
+// like a redirect, without counting a click. See QRCodeRequest for the query
+// parameters.
+func QRCodeHandler(urlService URLServiceInterface, links LinkURLs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request QRCodeRequest
+		if err := c.ShouldBindQuery(&request); err != nil {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		}
+
+		opts, err := request.options()
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		}
+
+		url, err := urlService.GetURL(c.Param("shortCode"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		image, err := qr.Render(links.URL(c, url.ShortCode), opts)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		// Without a base URL the image depends on the request's host and
+		// scheme, so shared caches must not serve it to other clients.
+		if links.baseURL != "" {
+			c.Header("Cache-Control", "public, max-age=86400")
+		} else {
+			c.Header("Cache-Control", "private, max-age=86400")
+			c.Header("Vary", "Host, X-Forwarded-Proto")
+		}
+		c.Data(http.StatusOK, qr.ContentType(opts.Format), image)
+	}
+}
+
+// qrDataURI renders a QR code of the link as a data URI, for embedding in a
+// JSON response.
+func qrDataURI(c *gin.Context, links LinkURLs, shortCode string, opts qr.Options) (string, error) {
+	image, err := qr.Render(links.URL(c, shortCode), opts)
+	if err != nil {
+		return "", err
+	}
+
+	return "data:" + qr.ContentType(opts.Format) + ";base64," + base64.StdEncoding.EncodeToString(image), nil
+}
+
+// LinkURLs builds the absolute URLs of short codes, which QR codes need for
+// scanners to open them.
+type LinkURLs struct {
+	baseURL string
+	proxies []*net.IPNet
+}
+
+// NewLinkURLs returns the URLs under baseURL, or, when it is empty, under the
+// scheme and host of each request. X-Forwarded-Proto then sets the scheme
+// only on requests from one of trustedProxies, given as IPs or CIDRs.
+func NewLinkURLs(baseURL string, trustedProxies []string) (LinkURLs, error) {
+	links := LinkURLs{baseURL: strings.TrimRight(baseURL, "/")}
+
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return LinkURLs{}, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			links.proxies = append(links.proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return LinkURLs{}, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		links.proxies = append(links.proxies, network)
+	}
+
+	return links, nil
+}
+
+// URL is the absolute URL of a short code.
+func (links LinkURLs) URL(c *gin.Context, shortCode string) string {
+	if links.baseURL != "" {
+		return links.baseURL + "/" + shortCode
+	}
+
+	scheme := "http"
+	if c.Request.TLS != nil || (links.fromProxy(c) && c.GetHeader("X-Forwarded-Proto") == "https") {
+		scheme = "https"
+	}
+
+	return scheme + "://" + c.Request.Host + "/" + shortCode
+}
+
+// fromProxy reports whether the request's peer is a trusted proxy.
+func (links LinkURLs) fromProxy(c *gin.Context) bool {
+	ip := net.ParseIP(c.RemoteIP())
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range links.proxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
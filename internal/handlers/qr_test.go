@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/mocks"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/parser"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/services"
+)
+
+func serveQRCode(urlService *mocks.URLService, baseURL, target string) *httptest.ResponseRecorder {
+	router := setupRouter()
+	router.GET("/:shortCode", RedirectHandler(urlService, new(mocks.ClickRecorder)))
+	router.GET("/:shortCode/qr", QRCodeHandler(urlService, LinkURLs{baseURL: baseURL}))
+
+	req, _ := http.NewRequest("GET", target, nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	return resp
+}
+
+func TestQRCodeHandler_PNG(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+	mockURLService.On("GetURL", "abc123").Return(&models.URL{ShortCode: "abc123", OriginalURL: "https://example.com"}, nil)
+
+	resp := serveQRCode(mockURLService, "", "/abc123/qr?size=128")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "image/png", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "private, max-age=86400", resp.Header().Get("Cache-Control"))
+	assert.Equal(t, "Host, X-Forwarded-Proto", resp.Header().Get("Vary"))
+
+	img, err := png.Decode(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, 128, img.Bounds().Dx())
+}
+
+func TestQRCodeHandler_SVG(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+	mockURLService.On("GetURL", "abc123").Return(&models.URL{ShortCode: "abc123", OriginalURL: "https://example.com"}, nil)
+
+	resp := serveQRCode(mockURLService, "https://sho.rt", "/abc123/qr?format=svg&level=h&margin=0&fg=%23336699&bg=ffffff00")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "image/svg+xml", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "public, max-age=86400", resp.Header().Get("Cache-Control"))
+	assert.True(t, strings.HasPrefix(resp.Body.String(), "<svg"))
+	assert.Contains(t, resp.Body.String(), `fill="#336699"`)
+	assert.Contains(t, resp.Body.String(), `<path d="M0 0h7v1h-7z`)
+	assert.NotContains(t, resp.Body.String(), "<rect")
+}
+
+func TestQRCodeHandler_Errors(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+	mockURLService.On("GetURL", "abc123").Return(&models.URL{ShortCode: "abc123"}, nil)
+	mockURLService.On("GetURL", "missing").Return(nil, &services.Error{Kind: services.ErrNotFound, Message: "short URL not found"})
+
+	for target, status := range map[string]int{
+		"/missing/qr":            http.StatusNotFound,
+		"/abc123/qr?format=gif":  http.StatusBadRequest,
+		"/abc123/qr?size=big":    http.StatusBadRequest,
+		"/abc123/qr?size=100000": http.StatusBadRequest,
+		"/abc123/qr?level=Z":     http.StatusBadRequest,
+		"/abc123/qr?fg=blue":     http.StatusBadRequest,
+	} {
+		resp := serveQRCode(mockURLService, "", target)
+		assert.Equal(t, status, resp.Code, target)
+	}
+}
+
+func TestLinkURLs(t *testing.T) {
+	fromRequest, err := NewLinkURLs("", []string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+	fromBase, err := NewLinkURLs("https://sho.rt/", nil)
+	require.NoError(t, err)
+
+	router := setupRouter()
+	var urls []string
+	router.GET("/", func(c *gin.Context) {
+		urls = append(urls, fromRequest.URL(c, "abc"), fromBase.URL(c, "abc"))
+	})
+
+	for _, remoteAddr := range []string{"10.1.2.3:4567", "192.168.1.1:4567", "203.0.113.9:4567"} {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Host = "links.example.com"
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-Proto", "https")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, []string{
+		"https://links.example.com/abc", "https://sho.rt/abc",
+		"https://links.example.com/abc", "https://sho.rt/abc",
+		"http://links.example.com/abc", "https://sho.rt/abc",
+	}, urls)
+
+	_, err = NewLinkURLs("", []string{"not-an-ip"})
+	assert.Error(t, err)
+}
+
+func TestShortenURLHandler_QRCode(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+	mockURLParser := new(mocks.URLParser)
+
+	mockURLParser.On("Parse", "https://example.com").Return(&parser.URLParseResult{Normalized: "https://example.com"}, nil)
+	mockURLService.On("ShortenURL", mock.Anything, "https://example.com", models.ShortenOptions{}).
+		Return(&models.URL{OriginalURL: "https://example.com", ShortCode: "abc123"}, nil)
+
+	router := setupRouter()
+	router.POST("/shorten", ShortenURLHandler(mockURLService, mockURLParser, LinkURLs{baseURL: "https://sho.rt"}))
+
+	body := `{"url": "https://example.com", "qr": {"format": "svg", "size": 512}}`
+	req, _ := http.NewRequest("POST", "/shorten", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response map[string]any
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+	dataURI, _ := response["qr_code"].(string)
+	require.True(t, strings.HasPrefix(dataURI, "data:image/svg+xml;base64,"), dataURI)
+
+	svg, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(dataURI, "data:image/svg+xml;base64,"))
+	require.NoError(t, err)
+	assert.Contains(t, string(svg), `width="512"`)
+
+	// Invalid QR options are refused before the link is created.
+	req, _ = http.NewRequest("POST", "/shorten", bytes.NewBufferString(`{"url": "https://example.com", "qr": {"level": "X"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	mockURLService.AssertNumberOfCalls(t, "ShortenURL", 1)
+}
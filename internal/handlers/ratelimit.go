@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/ratelimit"
+)
+
+type RateLimitStoreInterface interface {
+	Take(ctx context.Context, key string, limit ratelimit.Limit, cost int) (ratelimit.Result, error)
+}
+
+// RateLimit refuses requests with 429 once a client has spent its limit on
+// the routes sharing name. Clients are told apart by API key or token
+// subject after RequireAuth, and by IP address otherwise. Every response
+// carries X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset
+// (seconds until the allowance is whole again). When the store fails the
+// request is let through, so an outage of a shared store does not take the
+// service down with it.
+func RateLimit(store RateLimitStoreInterface, name string, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !spendRateLimit(c, store, name, limit, 1) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// spendRateLimit takes cost tokens from the client's bucket for name, as
+// RateLimit describes. It reports whether the request may go on, having
+// aborted it with 429 otherwise.
+func spendRateLimit(c *gin.Context, store RateLimitStoreInterface, name string, limit ratelimit.Limit, cost int) bool {
+	if !limit.Enabled() {
+		return true
+	}
+
+	result, err := store.Take(c.Request.Context(), name+":"+requestClient(c), limit, cost)
+	if err != nil {
+		log.Printf("Rate limit unavailable, allowing %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		return true
+	}
+
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset.Seconds())))
+
+	if !result.Allowed {
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter.Seconds())))
+		abortWithError(c, http.StatusTooManyRequests, CodeRateLimited, "rate limit exceeded, retry later")
+		return false
+	}
+
+	return true
+}
+
+// requestClient identifies who is making the request for rate limiting.
+func requestClient(c *gin.Context) string {
+	if client := c.GetString(clientContextKey); client != "" {
+		return client
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(seconds float64) int {
+	return int(math.Ceil(seconds))
+}
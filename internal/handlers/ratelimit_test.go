@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/ratelimit"
+)
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(context.Context, string, ratelimit.Limit, int) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+func TestRateLimit(t *testing.T) {
+	router := setupRouter()
+	router.GET("/limited", RateLimit(ratelimit.NewMemoryStore(), "test", ratelimit.PerMinute(1, 2)), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/limited", nil)
+		req.RemoteAddr = remoteAddr
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := request("192.0.2.1:1234")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "2", resp.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", resp.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "60", resp.Header().Get("X-RateLimit-Reset"))
+	assert.Empty(t, resp.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, request("192.0.2.1:1234").Code)
+
+	resp = request("192.0.2.1:5678")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "0", resp.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "60", resp.Header().Get("Retry-After"))
+	assert.Contains(t, resp.Body.String(), CodeRateLimited)
+
+	assert.Equal(t, http.StatusOK, request("192.0.2.2:1234").Code)
+}
+
+func TestRateLimit_KeysByClient(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	router := setupRouter()
+	router.GET("/limited", func(c *gin.Context) {
+		c.Set(clientContextKey, c.GetHeader(APIKeyHeader))
+	}, RateLimit(store, "test", ratelimit.PerMinute(1, 1)), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(key string) int {
+		req, _ := http.NewRequest("GET", "/limited", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set(APIKeyHeader, key)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	// Clients behind the same address keep separate allowances.
+	assert.Equal(t, http.StatusOK, request("key:usk_one"))
+	assert.Equal(t, http.StatusOK, request("key:usk_two"))
+	assert.Equal(t, http.StatusTooManyRequests, request("key:usk_one"))
+}
+
+func TestRateLimit_AllowsWhenStoreFails(t *testing.T) {
+	router := setupRouter()
+	router.GET("/limited", RateLimit(failingRateLimitStore{}, "test", ratelimit.PerMinute(1, 1)), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest("GET", "/limited", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, resp.Header().Get("X-RateLimit-Limit"))
+}
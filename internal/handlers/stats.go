@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+)
+
+// LinkStatsHandler serves the click statistics of a link. Supported query
+// parameters are from and to (RFC 3339 or YYYY-MM-DD), interval (hour, day,
+// week), top, the length of the top lists, and include_bots.
+func LinkStatsHandler(statsService StatsServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		from, err := queryTime(c, "from")
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		}
+
+		to, err := queryTime(c, "to")
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		}
+
+		top, err := queryInt(c, "top")
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		}
+
+		includeBots := false
+		if value := c.Query("include_bots"); value != "" {
+			if includeBots, err = strconv.ParseBool(value); err != nil {
+				abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, "query parameter include_bots must be a boolean")
+				return
+			}
+		}
+
+		stats, err := statsService.LinkStats(requestAccess(c), c.Param("code"), models.StatsOptions{
+			From:        from,
+			To:          to,
+			Interval:    c.Query("interval"),
+			Top:         int(top),
+			IncludeBots: includeBots,
+		})
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, stats)
+	}
+}
+
+func queryTime(c *gin.Context, key string) (time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("query parameter %s must be an RFC 3339 time or a YYYY-MM-DD date", key)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/mocks"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/services"
+)
+
+func TestLinkStatsHandler(t *testing.T) {
+	mockStatsService := new(mocks.StatsService)
+
+	router := setupRouter()
+	router.GET("/api/v1/links/:code/stats", LinkStatsHandler(mockStatsService))
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	mockStatsService.On("LinkStats", mock.Anything, "abc123", models.StatsOptions{From: from, To: to, Interval: "hour", Top: 5, IncludeBots: true}).Return(&models.LinkStats{
+		ShortCode:   "abc123",
+		RangeClicks: 3,
+		Series:      []models.StatsBucket{{Start: from, Clicks: 3}},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/links/abc123/stats?from=2026-03-01&to=2026-03-02T12:00:00Z&interval=hour&top=5&include_bots=true", nil)
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response models.LinkStats
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+	assert.Equal(t, int64(3), response.RangeClicks)
+	assert.Len(t, response.Series, 1)
+
+	mockStatsService.AssertExpectations(t)
+}
+
+func TestLinkStatsHandler_InvalidQuery(t *testing.T) {
+	mockStatsService := new(mocks.StatsService)
+
+	router := setupRouter()
+	router.GET("/api/v1/links/:code/stats", LinkStatsHandler(mockStatsService))
+
+	for _, query := range []string{"from=yesterday", "to=2026-13-01", "top=ten", "include_bots=maybe"} {
+		req, _ := http.NewRequest("GET", "/api/v1/links/abc123/stats?"+query, nil)
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code, query)
+	}
+
+	mockStatsService.AssertNotCalled(t, "LinkStats")
+}
+
+func TestLinkStatsHandler_ServiceErrors(t *testing.T) {
+	mockStatsService := new(mocks.StatsService)
+
+	router := setupRouter()
+	router.GET("/api/v1/links/:code/stats", LinkStatsHandler(mockStatsService))
+
+	mockStatsService.On("LinkStats", mock.Anything, "missing", models.StatsOptions{}).Return(nil, &services.Error{Kind: services.ErrNotFound, Message: "link \"missing\" not found"})
+	mockStatsService.On("LinkStats", mock.Anything, "abc123", models.StatsOptions{Interval: "month"}).Return(nil, &services.Error{Kind: services.ErrInvalid, Message: "bad interval"})
+
+	req, _ := http.NewRequest("GET", "/api/v1/links/missing/stats", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/api/v1/links/abc123/stats?interval=month", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
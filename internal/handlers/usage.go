@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageHandler reports how much of their link quotas the caller and their
+// workspace have used.
+func UsageHandler(urlService URLServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := urlService.Usage(requestAccess(c))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/mocks"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/parser"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/services"
+)
+
+func TestUsageHandler(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+	mockURLService.On("Usage", editorAccess).Return(&models.UsageReport{
+		User:      "alice",
+		Workspace: "marketing",
+		UserUsage: models.Usage{ActiveLinks: models.QuotaUsage{Used: 3, Limit: 10}},
+	}, nil)
+
+	router := setupRouter()
+	router.GET("/api/v1/usage", withAccess(editorAccess), UsageHandler(mockURLService))
+
+	req, _ := http.NewRequest("GET", "/api/v1/usage", nil)
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response map[string]any
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &response))
+	assert.Equal(t, map[string]any{"used": 3.0, "limit": 10.0}, response["user_usage"].(map[string]any)["active_links"])
+	assert.Equal(t, map[string]any{"used": 0.0}, response["workspace_usage"].(map[string]any)["monthly_links"])
+}
+
+func TestShortenURLHandler_QuotaExceeded(t *testing.T) {
+	mockURLService := new(mocks.URLService)
+	mockURLParser := new(mocks.URLParser)
+
+	mockURLParser.On("Parse", "https://example.com").Return(&parser.URLParseResult{Normalized: "https://example.com"}, nil)
+	mockURLService.On("ShortenURL", mock.Anything, "https://example.com", mock.Anything).
+		Return(nil, &services.Error{Kind: services.ErrQuotaExceeded, Message: "user alice has reached its quota of 10 active links"})
+
+	router := setupRouter()
+	router.POST("/shorten", ShortenURLHandler(mockURLService, mockURLParser, LinkURLs{}))
+
+	req, _ := http.NewRequest("POST", "/shorten", bytes.NewBufferString(`{"url":"https://example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	var response ErrorResponse
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &response))
+	assert.Equal(t, CodeQuotaExceeded, response.Code)
+	assert.Contains(t, response.Error, "quota of 10 active links")
+}
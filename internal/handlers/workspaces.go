@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+)
+
+type SetMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type ListMembersResponse struct {
+	Workspace string              `json:"workspace"`
+	Members   []models.Membership `json:"members"`
+}
+
+// ListMembersHandler lists the members of the caller's workspace.
+func ListMembersHandler(workspaceService WorkspaceServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		access := requestAccess(c)
+
+		members, err := workspaceService.ListMembers(access)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, ListMembersResponse{Workspace: access.Workspace, Members: members})
+	}
+}
+
+// SetMemberHandler adds a user to the caller's workspace or changes their
+// role. Only owners may call it.
+func SetMemberHandler(workspaceService WorkspaceServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request SetMemberRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		}
+
+		member, err := workspaceService.SetMember(requestAccess(c), c.Param("user"), request.Role)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, member)
+	}
+}
+
+func RemoveMemberHandler(workspaceService WorkspaceServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := workspaceService.RemoveMember(requestAccess(c), c.Param("user")); err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/mocks"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/services"
+)
+
+func withAccess(access models.Access) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(accessContextKey, access)
+		c.Next()
+	}
+}
+
+func TestListMembersHandler(t *testing.T) {
+	mockWorkspaces := new(mocks.WorkspaceService)
+	mockWorkspaces.On("ListMembers", editorAccess).Return([]models.Membership{
+		{Workspace: "marketing", User: "alice", Role: models.RoleEditor},
+		{Workspace: "marketing", User: "bob", Role: models.RoleOwner},
+	}, nil)
+
+	router := setupRouter()
+	router.GET("/api/v1/workspace/members", withAccess(editorAccess), ListMembersHandler(mockWorkspaces))
+
+	req, _ := http.NewRequest("GET", "/api/v1/workspace/members", nil)
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response ListMembersResponse
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &response))
+	assert.Equal(t, "marketing", response.Workspace)
+	assert.Len(t, response.Members, 2)
+}
+
+func TestSetMemberHandler(t *testing.T) {
+	mockWorkspaces := new(mocks.WorkspaceService)
+	mockWorkspaces.On("SetMember", editorAccess, "carol", "viewer").
+		Return(&models.Membership{Workspace: "marketing", User: "carol", Role: models.RoleViewer}, nil)
+
+	router := setupRouter()
+	router.PUT("/api/v1/workspace/members/:user", withAccess(editorAccess), SetMemberHandler(mockWorkspaces))
+
+	req, _ := http.NewRequest("PUT", "/api/v1/workspace/members/carol", bytes.NewBufferString(`{"role":"viewer"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	mockWorkspaces.AssertExpectations(t)
+
+	req, _ = http.NewRequest("PUT", "/api/v1/workspace/members/carol", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestRemoveMemberHandler_Forbidden(t *testing.T) {
+	mockWorkspaces := new(mocks.WorkspaceService)
+	mockWorkspaces.On("RemoveMember", mock.Anything, "bob").
+		Return(&services.Error{Kind: services.ErrForbidden, Message: "this requires the owner role"})
+
+	router := setupRouter()
+	router.DELETE("/api/v1/workspace/members/:user", withAccess(editorAccess), RemoveMemberHandler(mockWorkspaces))
+
+	req, _ := http.NewRequest("DELETE", "/api/v1/workspace/members/bob", nil)
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	var response ErrorResponse
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &response))
+	assert.Equal(t, CodeForbidden, response.Code)
+}
@@ -0,0 +1,268 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/analytics"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/parser"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	DefaultBatchSize = 500
+	maxLineSize      = 1024 * 1024
+
+	// occurrenceWindow is how far back identical lines are remembered. Logs
+	// written by several workers are only roughly in order, so a line may
+	// follow later ones by up to this much and still be numbered right.
+	occurrenceWindow = time.Minute
+)
+
+type LogEntryParser interface {
+	Parse(line string) (*parser.LogEntry, error)
+}
+
+// Result summarises an import.
+type Result struct {
+	Lines    int64
+	Imported int64
+	// Skipped counts lines that are not redirects of an existing link, or
+	// fall outside the Since and Until window.
+	Skipped int64
+	// Duplicates counts clicks already imported by an earlier run.
+	Duplicates int64
+	// Malformed counts lines the parser rejected.
+	Malformed int64
+	// Bots counts the imported clicks flagged as bots.
+	Bots int64
+}
+
+// Ingester backfills click events and counters from access logs. Clicks are
+// only imported for GET requests of short codes that exist in the URL store
+// and, when the log records it, answered with a redirect; other requests in
+// the same log are skipped.
+//
+// Click IDs are derived from the log line, so importing a log again, or an
+// overlapping one after rotation, skips the clicks already stored instead of
+// counting them twice. This relies on the click store reporting duplicates
+// (see store.ClickWriter), which the SQL and MongoDB stores do. Clicks the
+// server recorded itself have other IDs: use Since and Until to leave out
+// the period it was recording.
+type Ingester struct {
+	parser LogEntryParser
+	urls   store.URLStore
+	clicks store.ClickStore
+	opts   Options
+
+	known map[string]bool
+
+	// Identical lines are told apart by their occurrence: seen counts them
+	// per second logged, for the seconds within occurrenceWindow of latest.
+	latest int64
+	seen   map[int64]map[[sha256.Size]byte]int
+}
+
+type Options struct {
+	BatchSize int
+	// DryRun parses and counts the log without writing.
+	DryRun bool
+	// CountBots adds bot clicks to the link click counters.
+	CountBots bool
+	// Since and Until, when set, skip entries logged before Since or at or
+	// after Until.
+	Since     time.Time
+	Until     time.Time
+	Enrichers []analytics.Enricher
+}
+
+func New(logParser LogEntryParser, urlStore store.URLStore, clickStore store.ClickStore, opts Options) *Ingester {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	return &Ingester{
+		parser: logParser,
+		urls:   urlStore,
+		clicks: clickStore,
+		opts:   opts,
+		known:  make(map[string]bool),
+		seen:   make(map[int64]map[[sha256.Size]byte]int),
+	}
+}
+
+// Run reads log lines from r until EOF, writing clicks in batches. A failed
+// write aborts the import; the batches written before it are kept.
+func (ingester *Ingester) Run(ctx context.Context, r io.Reader) (Result, error) {
+	var result Result
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	batch := make([]models.Click, 0, ingester.opts.BatchSize)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		result.Lines++
+
+		entry, err := ingester.parser.Parse(scanner.Text())
+		if err != nil {
+			result.Malformed++
+			continue
+		}
+
+		click, ok, err := ingester.toClick(ctx, entry)
+		if err != nil {
+			return result, err
+		}
+		if !ok {
+			result.Skipped++
+			continue
+		}
+
+		click.ID = ingester.clickID(scanner.Text(), entry.Time)
+		batch = append(batch, click)
+		if len(batch) >= ingester.opts.BatchSize {
+			if err := ingester.flush(ctx, batch, &result); err != nil {
+				return result, err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("failed to read log: %w", err)
+	}
+
+	if err := ingester.flush(ctx, batch, &result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// clickID derives the ID of the click logged by line, the same on every run.
+func (ingester *Ingester) clickID(line string, at time.Time) primitive.ObjectID {
+	second := at.Unix()
+	if second > ingester.latest {
+		ingester.latest = second
+		for logged := range ingester.seen {
+			if time.Duration(second-logged)*time.Second > occurrenceWindow {
+				delete(ingester.seen, logged)
+			}
+		}
+	}
+
+	counts := ingester.seen[second]
+	if counts == nil {
+		counts = make(map[[sha256.Size]byte]int)
+		ingester.seen[second] = counts
+	}
+
+	sum := sha256.Sum256([]byte(line))
+	occurrence := counts[sum]
+	counts[sum]++
+
+	sum = sha256.Sum256(append(sum[:], strconv.Itoa(occurrence)...))
+
+	var id primitive.ObjectID
+	copy(id[:], sum[:])
+
+	return id
+}
+
+func (ingester *Ingester) toClick(ctx context.Context, entry *parser.LogEntry) (models.Click, bool, error) {
+	if entry.ShortCode == "" {
+		return models.Click{}, false, nil
+	}
+
+	if entry.Status != 0 && (entry.Status < 300 || entry.Status > 399) {
+		return models.Click{}, false, nil
+	}
+
+	if !ingester.opts.Since.IsZero() && entry.Time.Before(ingester.opts.Since) {
+		return models.Click{}, false, nil
+	}
+	if !ingester.opts.Until.IsZero() && !entry.Time.Before(ingester.opts.Until) {
+		return models.Click{}, false, nil
+	}
+
+	exists, err := ingester.exists(ctx, entry.ShortCode)
+	if err != nil || !exists {
+		return models.Click{}, false, err
+	}
+
+	click := models.Click{
+		ShortCode: entry.ShortCode,
+		Timestamp: entry.Time,
+		Referrer:  entry.Referrer,
+		UserAgent: entry.UserAgent,
+		IP:        entry.IP,
+	}
+	for _, enricher := range ingester.opts.Enrichers {
+		enricher.Enrich(&click)
+	}
+
+	return click, true, nil
+}
+
+// exists reports whether a link owns the short code, caching the answer for
+// the rest of the import.
+func (ingester *Ingester) exists(ctx context.Context, shortCode string) (bool, error) {
+	if exists, ok := ingester.known[shortCode]; ok {
+		return exists, nil
+	}
+
+	_, err := ingester.urls.GetByCode(ctx, shortCode)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return false, fmt.Errorf("failed to look up %q: %w", shortCode, err)
+	}
+
+	ingester.known[shortCode] = err == nil
+
+	return err == nil, nil
+}
+
+// flush writes batch and adds what it imported to result.
+func (ingester *Ingester) flush(ctx context.Context, batch []models.Click, result *Result) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	duplicates := []int{}
+	if !ingester.opts.DryRun {
+		var err error
+		duplicates, err = analytics.WriteClicks(ctx, ingester.clicks, ingester.urls, batch, ingester.opts.CountBots)
+		if err != nil {
+			return fmt.Errorf("failed to write clicks: %w", err)
+		}
+	}
+
+	skipped := make(map[int]bool, len(duplicates))
+	for _, i := range duplicates {
+		skipped[i] = true
+	}
+
+	for i, click := range batch {
+		if skipped[i] {
+			result.Duplicates++
+			continue
+		}
+
+		result.Imported++
+		if click.Bot {
+			result.Bots++
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/analytics"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/mocks"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/parser"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+const accessLog = `192.0.2.1 - - [10/Mar/2026:13:55:36 +0000] "GET /abc123 HTTP/1.1" 301 0 "https://news.example/" "Mozilla/5.0 (X11; Linux x86_64)"
+2001:db8::2 - - [10/Mar/2026:13:56:00 +0000] "GET /abc123 HTTP/1.1" 301 0 "-" "Mozilla/5.0 (Windows NT 10.0)"
+192.0.2.3 - - [10/Mar/2026:13:57:00 +0000] "GET /missing HTTP/1.1" 404 0 "-" "Mozilla/5.0"
+192.0.2.4 - - [10/Mar/2026:13:58:00 +0000] "POST /shorten HTTP/1.1" 201 87 "-" "curl/8.0"
+192.0.2.4 - - [10/Mar/2026:13:58:30 +0000] "GET /def456 HTTP/1.1" 410 0 "-" "curl/8.0"
+not a log line
+192.0.2.6 - - [10/Mar/2026:13:59:00 +0000] "GET /abc123 HTTP/1.1" 301 0 "-" "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)"
+{"time":"2026-03-10T14:00:00Z","remote_addr":"192.0.2.5","method":"GET","path":"/def456","status":302}
+`
+
+func newLogParser(t *testing.T) *parser.LogParser {
+	t.Helper()
+
+	logParser, err := parser.NewLogParser(parser.FormatAuto)
+	require.NoError(t, err)
+
+	return logParser
+}
+
+func newStores(t *testing.T) (*store.MemoryURLStore, *store.MemoryClickStore) {
+	t.Helper()
+
+	ctx := context.Background()
+	urlStore := store.NewMemoryURLStore()
+	for _, code := range []string{"abc123", "def456"} {
+		require.NoError(t, urlStore.Insert(ctx, &models.URL{ShortCode: code, OriginalURL: "https://example.com/" + code}))
+	}
+
+	return urlStore, store.NewMemoryClickStore(urlStore)
+}
+
+func TestIngester_Run(t *testing.T) {
+	ctx := context.Background()
+	urlStore, clickStore := newStores(t)
+
+	result, err := New(newLogParser(t), urlStore, clickStore, Options{BatchSize: 2, Enrichers: []analytics.Enricher{analytics.UserAgentEnricher{}}}).Run(ctx, strings.NewReader(accessLog))
+	require.NoError(t, err)
+
+	assert.Equal(t, Result{Lines: 8, Imported: 4, Skipped: 3, Malformed: 1, Bots: 1}, result)
+
+	abc, err := urlStore.GetByCode(ctx, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), abc.Clicks)
+
+	var clicks []models.Click
+	require.NoError(t, clickStore.ScanClicks(ctx, store.ClickFilter{ShortCode: "abc123"}, func(click models.Click) error {
+		clicks = append(clicks, click)
+		return nil
+	}))
+	require.Len(t, clicks, 3)
+	assert.True(t, time.Date(2026, 3, 10, 13, 55, 36, 0, time.UTC).Equal(clicks[0].Timestamp))
+	assert.Equal(t, "192.0.2.1", clicks[0].IP)
+	assert.Equal(t, "https://news.example/", clicks[0].Referrer)
+	assert.Equal(t, "Mozilla/5.0 (X11; Linux x86_64)", clicks[0].UserAgent)
+	assert.Equal(t, "Linux", clicks[0].OS)
+	assert.Equal(t, "2001:db8::2", clicks[1].IP)
+	assert.True(t, clicks[2].Bot)
+	assert.Equal(t, "Slackbot", clicks[2].Browser)
+}
+
+func TestIngester_DryRun(t *testing.T) {
+	ctx := context.Background()
+	urlStore, clickStore := newStores(t)
+
+	result, err := New(newLogParser(t), urlStore, clickStore, Options{DryRun: true}).Run(ctx, strings.NewReader(accessLog))
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), result.Imported)
+
+	abc, err := urlStore.GetByCode(ctx, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), abc.Clicks)
+}
+
+func TestIngester_StopsOnWriteError(t *testing.T) {
+	urlStore, _ := newStores(t)
+	clickStore := new(mocks.ClickStore)
+	clickStore.On("InsertClicks", mock.Anything, mock.Anything).Return(errors.New("database error"))
+
+	result, err := New(newLogParser(t), urlStore, clickStore, Options{BatchSize: 1}).Run(context.Background(), strings.NewReader(accessLog))
+
+	assert.ErrorContains(t, err, "database error")
+	assert.Equal(t, int64(0), result.Imported)
+	assert.Equal(t, int64(1), result.Lines)
+}
+
+func TestIngester_RunTwiceImportsOnce(t *testing.T) {
+	ctx := context.Background()
+	db, err := store.OpenSQL(ctx, store.DialectSQLite, filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	urlStore := store.NewSQLURLStore(db, store.DialectSQLite)
+	clickStore := store.NewSQLClickStore(db, store.DialectSQLite)
+	for _, code := range []string{"abc123", "def456"} {
+		require.NoError(t, urlStore.Insert(ctx, &models.URL{ShortCode: code, OriginalURL: "https://example.com/" + code}))
+	}
+
+	// The same click logged twice in one second is two clicks, even when
+	// a line of the next second was written between them.
+	line := `192.0.2.1 - - [10/Mar/2026:13:55:36 +0000] "GET /abc123 HTTP/1.1" 301 0 "-" "Mozilla/5.0"` + "\n"
+	later := `192.0.2.1 - - [10/Mar/2026:13:55:37 +0000] "GET /abc123 HTTP/1.1" 301 0 "-" "Mozilla/5.0"` + "\n"
+	log := accessLog + line + later + line
+
+	first, err := New(newLogParser(t), urlStore, clickStore, Options{BatchSize: 2}).Run(ctx, strings.NewReader(log))
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), first.Imported)
+	assert.Equal(t, int64(0), first.Duplicates)
+
+	second, err := New(newLogParser(t), urlStore, clickStore, Options{BatchSize: 3}).Run(ctx, strings.NewReader(log))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), second.Imported)
+	assert.Equal(t, int64(7), second.Duplicates)
+
+	abc, err := urlStore.GetByCode(ctx, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), abc.Clicks)
+}
+
+func TestIngester_SinceAndUntil(t *testing.T) {
+	ctx := context.Background()
+	urlStore, clickStore := newStores(t)
+
+	result, err := New(newLogParser(t), urlStore, clickStore, Options{
+		Since: time.Date(2026, 3, 10, 13, 56, 0, 0, time.UTC),
+		Until: time.Date(2026, 3, 10, 14, 0, 0, 0, time.UTC),
+	}).Run(ctx, strings.NewReader(accessLog))
+	require.NoError(t, err)
+
+	// Only the 13:56 and 13:59 redirects of abc123 are inside the window.
+	assert.Equal(t, int64(2), result.Imported)
+	assert.Equal(t, int64(5), result.Skipped)
+}
@@ -0,0 +1,353 @@
+// Package jwks loads the public keys an identity provider signs its tokens
+// with, published as a JSON Web Key Set (RFC 7517) in a local file or at a URL.
+package jwks
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// MaxAge is how long a key set fetched from a URL is used before it is
+	// fetched again.
+	MaxAge = time.Hour
+	// MinRefreshInterval limits how often an unknown key ID triggers a fetch,
+	// so tokens with made-up key IDs cannot flood the identity provider.
+	MinRefreshInterval = time.Minute
+
+	maxDocumentSize = 1 << 20
+	fetchTimeout    = 10 * time.Second
+)
+
+var ErrKeyNotFound = errors.New("signing key not found")
+
+// Key is a public verification key. Algorithm is empty when the key set does
+// not restrict the key to one algorithm.
+type Key struct {
+	ID        string
+	Algorithm string
+	Public    crypto.PublicKey
+}
+
+// Set is a parsed key set.
+type Set struct {
+	keys []Key
+}
+
+type jsonWebKey struct {
+	Type      string `json:"kty"`
+	ID        string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+}
+
+// Parse reads a key set document. Keys meant for encryption, key types that
+// cannot verify signatures and keys that are malformed or unsupported are
+// skipped, so one bad key added upstream does not take the others down; a set
+// without any usable key is an error.
+func Parse(data []byte) (*Set, error) {
+	var document struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid key set: %w", err)
+	}
+
+	set := &Set{}
+	var skipped error // the first key skipped as invalid
+	for i, raw := range document.Keys {
+		var jwk jsonWebKey
+		if err := json.Unmarshal(raw, &jwk); err != nil {
+			if skipped == nil {
+				skipped = fmt.Errorf("invalid key %d: %w", i, err)
+			}
+			continue
+		}
+
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		public, err := jwk.publicKey()
+		if err != nil {
+			if skipped == nil {
+				skipped = fmt.Errorf("invalid key %d (%q): %w", i, jwk.ID, err)
+			}
+			continue
+		}
+		if public == nil {
+			continue
+		}
+
+		set.keys = append(set.keys, Key{ID: jwk.ID, Algorithm: jwk.Algorithm, Public: public})
+	}
+
+	if len(set.keys) == 0 {
+		if skipped != nil {
+			return nil, fmt.Errorf("key set has no signing keys: %w", skipped)
+		}
+
+		return nil, errors.New("key set has no signing keys")
+	}
+
+	return set, nil
+}
+
+// Lookup returns the key with the given ID. Tokens without a key ID can only
+// be verified against a set holding a single key.
+func (s *Set) Lookup(id string) (Key, bool) {
+	if id == "" {
+		if len(s.keys) == 1 {
+			return s.keys[0], true
+		}
+
+		return Key{}, false
+	}
+
+	for _, key := range s.keys {
+		if key.ID == id {
+			return key, true
+		}
+	}
+
+	return Key{}, false
+}
+
+// Len returns the number of keys in the set.
+func (s *Set) Len() int {
+	return len(s.keys)
+}
+
+func (jwk jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Type {
+	case "RSA":
+		n, err := decodeInt(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("modulus: %w", err)
+		}
+		e, err := decodeInt(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("exponent: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("unsupported exponent")
+		}
+		if n.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must have at least 2048 bits")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+
+		x, err := decodeInt(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := decodeInt(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if jwk.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key")
+		}
+
+		return ed25519.PublicKey(x), nil
+
+	default:
+		// Symmetric ("oct") and unknown key types are not used to verify.
+		return nil, nil
+	}
+}
+
+func decodeInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, errors.New("missing value")
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
+
+// Provider serves the keys of a key set file or URL. A file is read once; a
+// URL is fetched again once the set is older than MaxAge, or when a token
+// names a key the set does not have, which happens after the identity
+// provider rotates its keys.
+type Provider struct {
+	source string
+	client *http.Client
+
+	// refreshes runs one fetch at a time, shared by every caller that needs
+	// it, without holding mu.
+	refreshes singleflight.Group
+
+	mu        sync.Mutex
+	set       *Set
+	fetchedAt time.Time
+	now       func() time.Time
+}
+
+// Open loads the key set at source, an http(s) URL or a file path.
+func Open(ctx context.Context, source string) (*Provider, error) {
+	provider := &Provider{
+		source: source,
+		client: &http.Client{Timeout: fetchTimeout},
+		now:    time.Now,
+	}
+
+	set, err := provider.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	provider.set = set
+	provider.fetchedAt = provider.now()
+
+	return provider, nil
+}
+
+// Key returns the key with the given ID, refreshing a URL key set when the
+// key is unknown or the set is stale. A failed refresh keeps the previous set.
+// ctx only bounds the wait for a refresh: the refresh itself goes on for the
+// callers after this one.
+func (p *Provider) Key(ctx context.Context, id string) (Key, error) {
+	p.mu.Lock()
+	set, fetchedAt := p.set, p.fetchedAt
+	p.mu.Unlock()
+
+	key, found := set.Lookup(id)
+	if p.isRemote() {
+		age := p.now().Sub(fetchedAt)
+		if age >= MaxAge || (!found && age >= MinRefreshInterval) {
+			refreshed := p.refreshes.DoChan("", func() (any, error) {
+				return p.refresh(fetchedAt), nil
+			})
+
+			select {
+			case result := <-refreshed:
+				key, found = result.Val.(*Set).Lookup(id)
+			case <-ctx.Done():
+			}
+		}
+	}
+
+	if !found {
+		return Key{}, ErrKeyNotFound
+	}
+
+	return key, nil
+}
+
+// refresh fetches the key set unless it was fetched again after seen, and
+// returns the current set.
+func (p *Provider) refresh(seen time.Time) *Set {
+	p.mu.Lock()
+	if p.fetchedAt.After(seen) {
+		defer p.mu.Unlock()
+		return p.set
+	}
+	p.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	set, err := p.load(ctx)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err == nil {
+		p.set = set
+	}
+	// Retry failures no sooner than an unknown key would.
+	p.fetchedAt = p.now()
+
+	return p.set
+}
+
+func (p *Provider) isRemote() bool {
+	return strings.HasPrefix(p.source, "https://") || strings.HasPrefix(p.source, "http://")
+}
+
+func (p *Provider) load(ctx context.Context) (*Set, error) {
+	if !p.isRemote() {
+		data, err := os.ReadFile(p.source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key set: %w", err)
+		}
+
+		return Parse(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch key set: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch key set: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch key set: %w", err)
+	}
+
+	return Parse(data)
+}
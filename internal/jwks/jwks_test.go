@@ -0,0 +1,225 @@
+package jwks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/jwks/jwkstest"
+)
+
+func TestParse(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	encode := base64.RawURLEncoding.EncodeToString
+	document := fmt.Sprintf(`{"keys": [
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": %q, "y": %q},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": %q, "alg": "EdDSA"},
+		{"kty": "oct", "kid": "secret", "k": "c2VjcmV0"},
+		{"kty": "EC", "kid": "enc", "use": "enc", "crv": "P-256", "x": %q, "y": %q}
+	]}`, encode(ecKey.X.Bytes()), encode(ecKey.Y.Bytes()), encode(edKey), encode(ecKey.X.Bytes()), encode(ecKey.Y.Bytes()))
+
+	set, err := Parse([]byte(document))
+	require.NoError(t, err)
+	assert.Equal(t, 2, set.Len())
+
+	key, ok := set.Lookup("ec")
+	require.True(t, ok)
+	assert.True(t, ecKey.PublicKey.Equal(key.Public))
+
+	key, ok = set.Lookup("ed")
+	require.True(t, ok)
+	assert.Equal(t, "EdDSA", key.Algorithm)
+	assert.True(t, edKey.Equal(key.Public))
+
+	for _, id := range []string{"secret", "enc", ""} {
+		_, ok := set.Lookup(id)
+		assert.False(t, ok, id)
+	}
+}
+
+func TestParse_Rejects(t *testing.T) {
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	for name, document := range map[string]string{
+		"not json":    `keys`,
+		"empty":       `{"keys": []}`,
+		"only secret": `{"keys": [{"kty": "oct", "k": "c2VjcmV0"}]}`,
+		"bad curve":   `{"keys": [{"kty": "EC", "crv": "P-192", "x": "AQ", "y": "AQ"}]}`,
+		"off curve":   `{"keys": [{"kty": "EC", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`,
+		"weak rsa": fmt.Sprintf(`{"keys": [{"kty": "RSA", "n": %q, "e": "AQAB"}]}`,
+			base64.RawURLEncoding.EncodeToString(weak.N.Bytes())),
+	} {
+		_, err := Parse([]byte(document))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestParse_SkipsUnusableKeys(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	encode := base64.RawURLEncoding.EncodeToString
+	document := fmt.Sprintf(`{"keys": [
+		{"kty": "EC", "kid": "k1", "crv": "P-256K", "x": "AQ", "y": "AQ"},
+		{"kty": "RSA", "kid": "weak", "n": %q, "e": "AQAB"},
+		{"kty": "EC", "kid": "broken", "crv": 256},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": %q, "y": %q}
+	]}`, encode(weak.N.Bytes()), encode(ecKey.X.Bytes()), encode(ecKey.Y.Bytes()))
+
+	set, err := Parse([]byte(document))
+	require.NoError(t, err)
+	assert.Equal(t, 1, set.Len())
+
+	_, ok := set.Lookup("ec")
+	assert.True(t, ok)
+}
+
+func TestProvider_File(t *testing.T) {
+	signer := jwkstest.NewSigner(t, "one")
+
+	provider, err := Open(context.Background(), jwkstest.WriteKeySet(t, signer))
+	require.NoError(t, err)
+
+	key, err := provider.Key(context.Background(), "one")
+	require.NoError(t, err)
+	assert.Equal(t, "RS256", key.Algorithm)
+
+	// A single key also verifies tokens that do not name it.
+	_, err = provider.Key(context.Background(), "")
+	require.NoError(t, err)
+
+	_, err = provider.Key(context.Background(), "two")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	_, err = Open(context.Background(), "/does/not/exist.json")
+	assert.Error(t, err)
+}
+
+func TestProvider_URLRefreshesOnRotation(t *testing.T) {
+	first := jwkstest.NewSigner(t, "first")
+	second := jwkstest.NewSigner(t, "second")
+
+	var rotated atomic.Bool
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if rotated.Load() {
+			w.Write(jwkstest.KeySet(t, second)) //nolint:errcheck
+			return
+		}
+		w.Write(jwkstest.KeySet(t, first)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	provider, err := Open(context.Background(), server.URL)
+	require.NoError(t, err)
+	now := provider.fetchedAt
+	provider.now = func() time.Time { return now }
+
+	_, err = provider.Key(context.Background(), "first")
+	require.NoError(t, err)
+
+	rotated.Store(true)
+
+	// Unknown keys only trigger a fetch once the last one is old enough.
+	_, err = provider.Key(context.Background(), "second")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	now = now.Add(MinRefreshInterval)
+	_, err = provider.Key(context.Background(), "second")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+
+	_, err = provider.Key(context.Background(), "first")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestProvider_URLKeepsKeysWhenRefreshFails(t *testing.T) {
+	signer := jwkstest.NewSigner(t, "one")
+
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write(jwkstest.KeySet(t, signer)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	provider, err := Open(context.Background(), server.URL)
+	require.NoError(t, err)
+	now := provider.fetchedAt
+	provider.now = func() time.Time { return now }
+
+	failing.Store(true)
+	now = now.Add(MaxAge)
+
+	_, err = provider.Key(context.Background(), "one")
+	require.NoError(t, err)
+}
+
+func TestProvider_RefreshDoesNotBlockOtherKeys(t *testing.T) {
+	first := jwkstest.NewSigner(t, "first")
+	second := jwkstest.NewSigner(t, "second")
+
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) == 1 {
+			w.Write(jwkstest.KeySet(t, first)) //nolint:errcheck
+			return
+		}
+		<-release
+		w.Write(jwkstest.KeySet(t, first, second)) //nolint:errcheck
+	}))
+	defer server.Close()
+	defer close(release)
+
+	provider, err := Open(context.Background(), server.URL)
+	require.NoError(t, err)
+	now := provider.fetchedAt.Add(MinRefreshInterval)
+	provider.now = func() time.Time { return now }
+
+	// A client giving up waits no longer, but does not cancel the refresh.
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := provider.Key(ctx, "second")
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return fetches.Load() == 2 }, time.Second, time.Millisecond)
+
+	_, err = provider.Key(context.Background(), "first")
+	require.NoError(t, err)
+
+	cancel()
+	assert.ErrorIs(t, <-done, ErrKeyNotFound)
+
+	release <- struct{}{}
+	require.Eventually(t, func() bool {
+		_, err := provider.Key(context.Background(), "second")
+		return err == nil
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(2), fetches.Load())
+}
@@ -0,0 +1,88 @@
+// Package jwkstest signs tokens with throwaway keys and publishes them as a
+// key set, standing in for an identity provider in tests.
+package jwkstest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signer holds an RSA key pair and signs RS256 tokens with it.
+type Signer struct {
+	KeyID string
+	key   *rsa.PrivateKey
+}
+
+// NewSigner generates a 2048-bit RSA key identified by keyID.
+func NewSigner(t testing.TB, keyID string) *Signer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	return &Signer{KeyID: keyID, key: key}
+}
+
+// Sign returns a token carrying claims, with the signer's key ID in its
+// header.
+func (s *Signer) Sign(t testing.TB, claims jwt.Claims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.KeyID
+
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	return signed
+}
+
+// KeySet returns the key set document publishing the public keys of signers.
+func KeySet(t testing.TB, signers ...*Signer) []byte {
+	t.Helper()
+
+	keys := make([]map[string]string, 0, len(signers))
+	for _, signer := range signers {
+		public := signer.key.PublicKey
+		keys = append(keys, map[string]string{
+			"kty": "RSA",
+			"kid": signer.KeyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		})
+	}
+
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatalf("failed to encode key set: %v", err)
+	}
+
+	return data
+}
+
+// WriteKeySet writes the key set of signers to a temporary file and returns
+// its path.
+func WriteKeySet(t testing.TB, signers ...*Signer) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, KeySet(t, signers...), 0o600); err != nil {
+		t.Fatalf("failed to write key set: %v", err)
+	}
+
+	return path
+}
@@ -0,0 +1,20 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+)
+
+type APIKeyAuthenticator struct {
+	mock.Mock
+}
+
+func (m *APIKeyAuthenticator) Authenticate(rawKey string) (*models.APIKey, error) {
+	args := m.Called(rawKey)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
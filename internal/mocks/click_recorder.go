@@ -0,0 +1,14 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+)
+
+type ClickRecorder struct {
+	mock.Mock
+}
+
+func (m *ClickRecorder) Record(click models.Click) {
+	m.Called(click)
+}
@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+type ClickStore struct {
+	mock.Mock
+}
+
+func (m *ClickStore) InsertClicks(ctx context.Context, clicks []models.Click) error {
+	args := m.Called(ctx, clicks)
+	return args.Error(0)
+}
+
+func (m *ClickStore) ScanClicks(ctx context.Context, filter store.ClickFilter, fn func(models.Click) error) error {
+	args := m.Called(ctx, filter, fn)
+	return args.Error(0)
+}
+
+func (m *ClickStore) SummarizeClicks(ctx context.Context, filter store.ClickFilter, opts store.SummaryOptions) (*store.ClickSummary, error) {
+	args := m.Called(ctx, filter, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*store.ClickSummary), args.Error(1)
+}
@@ -0,0 +1,23 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/export"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+)
+
+type ExportService struct {
+	mock.Mock
+}
+
+func (m *ExportService) ExportLinks(access models.Access, filter export.Filter, w export.Writer) (int64, error) {
+	args := m.Called(access, filter, w)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *ExportService) ExportClicks(access models.Access, filter export.Filter, w export.Writer) (int64, error) {
+	args := m.Called(access, filter, w)
+
+	return args.Get(0).(int64), args.Error(1)
+}
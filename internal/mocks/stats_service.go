@@ -0,0 +1,20 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+)
+
+type StatsService struct {
+	mock.Mock
+}
+
+func (m *StatsService) LinkStats(access models.Access, shortCode string, opts models.StatsOptions) (*models.LinkStats, error) {
+	args := m.Called(access, shortCode, opts)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.LinkStats), args.Error(1)
+}
@@ -0,0 +1,15 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+type TokenVerifier struct {
+	mock.Mock
+}
+
+func (m *TokenVerifier) Verify(rawToken string) (string, string, error) {
+	args := m.Called(rawToken)
+
+	return args.String(0), args.String(1), args.Error(2)
+}
@@ -3,14 +3,15 @@ package mocks
 import (
 	"github.com/stretchr/testify/mock"
 	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
 )
 
 type URLService struct {
 	mock.Mock
 }
 
-func (m *URLService) ShortenURL(originalURL string, customCode string) (*models.URL, error) {
-	args := m.Called(originalURL, customCode)
+func (m *URLService) ShortenURL(access models.Access, originalURL string, opts models.ShortenOptions) (*models.URL, error) {
+	args := m.Called(access, originalURL, opts)
 
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -19,6 +20,16 @@ func (m *URLService) ShortenURL(originalURL string, customCode string) (*models.
 	return args.Get(0).(*models.URL), args.Error(1)
 }
 
+func (m *URLService) ShortenURLs(access models.Access, entries []models.BulkEntry) ([]models.BulkResult, error) {
+	args := m.Called(access, entries)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]models.BulkResult), args.Error(1)
+}
+
 func (m *URLService) GetURL(shortCode string) (*models.URL, error) {
 	args := m.Called(shortCode)
 
@@ -28,3 +39,59 @@ func (m *URLService) GetURL(shortCode string) (*models.URL, error) {
 
 	return args.Get(0).(*models.URL), args.Error(1)
 }
+
+func (m *URLService) GetLink(access models.Access, shortCode string) (*models.URL, error) {
+	args := m.Called(access, shortCode)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.URL), args.Error(1)
+}
+
+func (m *URLService) ListLinks(access models.Access, opts store.ListOptions) ([]models.URL, int64, error) {
+	args := m.Called(access, opts)
+
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+
+	return args.Get(0).([]models.URL), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *URLService) Usage(access models.Access) (*models.UsageReport, error) {
+	args := m.Called(access)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.UsageReport), args.Error(1)
+}
+
+func (m *URLService) UpdateLink(access models.Access, shortCode string, update models.LinkUpdate) (*models.URL, error) {
+	args := m.Called(access, shortCode, update)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.URL), args.Error(1)
+}
+
+func (m *URLService) DeleteLink(access models.Access, shortCode string) error {
+	args := m.Called(access, shortCode)
+
+	return args.Error(0)
+}
+
+func (m *URLService) RestoreLink(access models.Access, shortCode string) (*models.URL, error) {
+	args := m.Called(access, shortCode)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.URL), args.Error(1)
+}
@@ -0,0 +1,101 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+type URLStore struct {
+	mock.Mock
+}
+
+func (m *URLStore) GetByCode(ctx context.Context, shortCode string) (*models.URL, error) {
+	args := m.Called(ctx, shortCode)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.URL), args.Error(1)
+}
+
+func (m *URLStore) GetByOriginalURL(ctx context.Context, originalURL, workspace string) (*models.URL, error) {
+	args := m.Called(ctx, originalURL, workspace)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.URL), args.Error(1)
+}
+
+func (m *URLStore) Insert(ctx context.Context, url *models.URL) error {
+	args := m.Called(ctx, url)
+
+	return args.Error(0)
+}
+
+func (m *URLStore) InsertMany(ctx context.Context, urls []*models.URL) ([]int, error) {
+	args := m.Called(ctx, urls)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *URLStore) ScanURLs(ctx context.Context, opts store.ListOptions, fn func(models.URL) error) error {
+	args := m.Called(ctx, opts, fn)
+
+	return args.Error(0)
+}
+
+func (m *URLStore) IncrementClicks(ctx context.Context, shortCode string, delta int64) error {
+	args := m.Called(ctx, shortCode, delta)
+
+	return args.Error(0)
+}
+
+func (m *URLStore) AddClicks(ctx context.Context, counts map[string]int64) error {
+	args := m.Called(ctx, counts)
+
+	return args.Error(0)
+}
+
+func (m *URLStore) Delete(ctx context.Context, shortCode string) error {
+	args := m.Called(ctx, shortCode)
+
+	return args.Error(0)
+}
+
+func (m *URLStore) List(ctx context.Context, opts store.ListOptions) ([]models.URL, error) {
+	args := m.Called(ctx, opts)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]models.URL), args.Error(1)
+}
+
+func (m *URLStore) NextSequence(ctx context.Context, name string) (int64, error) {
+	args := m.Called(ctx, name)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *URLStore) Update(ctx context.Context, url *models.URL) error {
+	args := m.Called(ctx, url)
+
+	return args.Error(0)
+}
+
+func (m *URLStore) Count(ctx context.Context, opts store.ListOptions) (int64, error) {
+	args := m.Called(ctx, opts)
+
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,42 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+)
+
+type WorkspaceService struct {
+	mock.Mock
+}
+
+func (m *WorkspaceService) Access(user, workspace string) (models.Access, error) {
+	args := m.Called(user, workspace)
+
+	return args.Get(0).(models.Access), args.Error(1)
+}
+
+func (m *WorkspaceService) ListMembers(access models.Access) ([]models.Membership, error) {
+	args := m.Called(access)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]models.Membership), args.Error(1)
+}
+
+func (m *WorkspaceService) SetMember(access models.Access, user, role string) (*models.Membership, error) {
+	args := m.Called(access, user, role)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Membership), args.Error(1)
+}
+
+func (m *WorkspaceService) RemoveMember(access models.Access, user string) error {
+	args := m.Called(access, user)
+
+	return args.Error(0)
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKey authenticates requests to the management endpoints as Owner working
+// in Workspace. Only a hash of the secret is stored; Prefix is the public part
+// of the key, shown in listings so a key can be identified and revoked
+// without revealing it.
+type APIKey struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name      string             `json:"name" bson:"name"`
+	Owner     string             `json:"owner" bson:"owner"`
+	Workspace string             `json:"workspace" bson:"workspace,omitempty"`
+	Prefix    string             `json:"prefix" bson:"prefix"`
+	Hash      string             `json:"-" bson:"hash"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	RevokedAt *time.Time         `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+}
+
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
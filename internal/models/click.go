@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Click is a single redirect through a short link.
+type Click struct {
+	ID             primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	ShortCode      string             `json:"short_code" bson:"short_code"`
+	Timestamp      time.Time          `json:"timestamp" bson:"timestamp"`
+	Referrer       string             `json:"referrer,omitempty" bson:"referrer,omitempty"`
+	UserAgent      string             `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+	IP             string             `json:"ip,omitempty" bson:"ip,omitempty"`
+	AcceptLanguage string             `json:"accept_language,omitempty" bson:"accept_language,omitempty"`
+
+	// Derived dimensions, left empty until an enricher can fill them in.
+	Country string `json:"country,omitempty" bson:"country,omitempty"`
+	Region  string `json:"region,omitempty" bson:"region,omitempty"`
+	City    string `json:"city,omitempty" bson:"city,omitempty"`
+	Browser string `json:"browser,omitempty" bson:"browser,omitempty"`
+	OS      string `json:"os,omitempty" bson:"os,omitempty"`
+	Device  string `json:"device,omitempty" bson:"device,omitempty"`
+	// Bot marks clicks from crawlers, link previews and monitors. They are
+	// stored but not added to the link's click counter by default.
+	Bot bool `json:"bot,omitempty" bson:"bot,omitempty"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// QuotaUsage is how much of a quota is used. A zero Limit means unlimited.
+type QuotaUsage struct {
+	Used  int64 `json:"used"`
+	Limit int64 `json:"limit,omitempty"`
+}
+
+// Exceeded reports whether adding one more would go over the limit.
+func (q QuotaUsage) Exceeded() bool {
+	return q.Limit > 0 && q.Used >= q.Limit
+}
+
+// Usage measures links against each kind of quota.
+type Usage struct {
+	ActiveLinks  QuotaUsage `json:"active_links"`
+	CustomCodes  QuotaUsage `json:"custom_codes"`
+	MonthlyLinks QuotaUsage `json:"monthly_links"`
+}
+
+// UsageReport is the usage of a user, across workspaces, and of the
+// workspace they act in. MonthlyLinks counts from PeriodStart, the start of
+// the current month in UTC.
+type UsageReport struct {
+	User           string    `json:"user"`
+	Workspace      string    `json:"workspace"`
+	PeriodStart    time.Time `json:"period_start"`
+	UserUsage      Usage     `json:"user_usage"`
+	WorkspaceUsage Usage     `json:"workspace_usage"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+const (
+	IntervalHour = "hour"
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+// StatsOptions selects the range and granularity of link statistics. Zero
+// values are replaced by the service defaults.
+type StatsOptions struct {
+	From     time.Time
+	To       time.Time
+	Interval string
+	Top      int
+	// IncludeBots counts bot clicks like any other; by default they are only
+	// reported in BotClicks.
+	IncludeBots bool
+}
+
+// LinkStats summarises the clicks of one link over a time range.
+type LinkStats struct {
+	ShortCode string `json:"short_code"`
+	// TotalClicks is the lifetime counter of the link, regardless of range.
+	TotalClicks    int64          `json:"total_clicks"`
+	RangeClicks    int64          `json:"range_clicks"`
+	BotClicks      int64          `json:"bot_clicks"`
+	UniqueVisitors int64          `json:"unique_visitors"`
+	From           time.Time      `json:"from"`
+	To             time.Time      `json:"to"`
+	Interval       string         `json:"interval"`
+	Series         []StatsBucket  `json:"series"`
+	TopReferrers   []CountByValue `json:"top_referrers"`
+	TopCountries   []CountByValue `json:"top_countries"`
+	TopRegions     []CountByValue `json:"top_regions"`
+	TopCities      []CountByValue `json:"top_cities"`
+	TopBrowsers    []CountByValue `json:"top_browsers"`
+	TopOS          []CountByValue `json:"top_os"`
+}
+
+// StatsBucket holds the clicks of one interval starting at Start.
+type StatsBucket struct {
+	Start  time.Time `json:"start"`
+	Clicks int64     `json:"clicks"`
+}
+
+type CountByValue struct {
+	Value  string `json:"value"`
+	Clicks int64  `json:"clicks"`
+}
@@ -1,18 +1,78 @@
 package models
 
 import (
+	"net/http"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type URL struct {
-	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	OriginalURL string             `json:"original_url" bson:"original_url"`
-	ShortCode   string             `json:"short_code" bson:"short_code"`
-	Clicks      int64              `json:"clicks" bson:"clicks"`
-	ExpiresAt   time.Time          `json:"expires_at" bson:"expires_at"`
-	CreatedBy   string             `json:"created_by" bson:"created_by"`
-	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	OriginalURL  string             `json:"original_url" bson:"original_url"`
+	ShortCode    string             `json:"short_code" bson:"short_code"`
+	CustomCode   bool               `json:"custom_code" bson:"custom_code,omitempty"` // the creator chose ShortCode
+	Clicks       int64              `json:"clicks" bson:"clicks"`
+	ExpiresAt    *time.Time         `json:"expires_at" bson:"expires_at,omitempty"` // nil means the link never expires
+	RedirectType int                `json:"redirect_type" bson:"redirect_type,omitempty"`
+	CreatedBy    string             `json:"created_by" bson:"created_by"`
+	Workspace    string             `json:"workspace" bson:"workspace,omitempty"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at"`
+	DeletedAt    *time.Time         `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+func (u *URL) IsExpired(now time.Time) bool {
+	return u.ExpiresAt != nil && now.After(*u.ExpiresAt)
+}
+
+func (u *URL) IsDeleted() bool {
+	return u.DeletedAt != nil
+}
+
+// IsValidRedirectType reports whether code is an HTTP redirect status a link
+// may use: 301, 302, 307 or 308.
+func IsValidRedirectType(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	default:
+		return false
+	}
+}
+
+// ShortenOptions carries the optional parameters of a shorten request. At most
+// one of ExpiresAt, TTL and NeverExpires may be set; when none is, the
+// configured default expiry applies.
+type ShortenOptions struct {
+	CustomCode   string
+	RedirectType int // zero uses the configured default
+	ExpiresAt    *time.Time
+	TTL          time.Duration
+	NeverExpires bool
+}
+
+// BulkEntry is one link of a bulk shorten request.
+type BulkEntry struct {
+	OriginalURL string
+	Options     ShortenOptions
+}
+
+// BulkResult is the outcome of one BulkEntry: the link, which Existing marks
+// as reused rather than created, or the error that kept it from being made.
+type BulkResult struct {
+	URL      *URL
+	Existing bool
+	Err      error
+}
+
+// LinkUpdate holds the changes of a PATCH request. An empty OriginalURL keeps
+// the destination; the expiry fields follow the ShortenOptions rules and leave
+// the expiry unchanged when none is set.
+type LinkUpdate struct {
+	OriginalURL  string
+	RedirectType int // zero keeps the current redirect type
+	ExpiresAt    *time.Time
+	TTL          time.Duration
+	NeverExpires bool
 }
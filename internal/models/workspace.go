@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Roles a user can hold in a workspace. Viewers can read links and their
+// statistics, editors can also create and change them and owners can also
+// manage the workspace members.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleOwner  = "owner"
+)
+
+// DefaultWorkspace holds the links and API keys created before workspaces
+// existed, and everything created while authentication is disabled.
+const DefaultWorkspace = "default"
+
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+func IsValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+type User struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name      string             `json:"name" bson:"name"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Workspace groups the links of a team. Slug is its unique, URL-safe name.
+type Workspace struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Slug      string             `json:"slug" bson:"slug"`
+	Name      string             `json:"name" bson:"name"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Membership gives a user a role in a workspace.
+type Membership struct {
+	Workspace string    `json:"workspace" bson:"workspace"`
+	User      string    `json:"user" bson:"user"`
+	Role      string    `json:"role" bson:"role"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+// Access is what an authenticated request acts as: a user working in one
+// workspace with the role they hold there.
+type Access struct {
+	User      string
+	Workspace string
+	Role      string
+}
+
+// Allows reports whether the access role is at least role.
+func (a Access) Allows(role string) bool {
+	return roleRanks[role] > 0 && roleRanks[a.Role] >= roleRanks[role]
+}
+
+// WorkspaceOrDefault maps the empty workspace of records stored before
+// workspaces existed to DefaultWorkspace.
+func WorkspaceOrDefault(workspace string) string {
+	if workspace == "" {
+		return DefaultWorkspace
+	}
+
+	return workspace
+}
@@ -0,0 +1,445 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/textproto"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// FormatAuto picks the format of each line from its shape.
+	FormatAuto = "auto"
+	// FormatCombined is the Apache and NGINX combined log format; the common
+	// log format, without referrer and user agent, is accepted as well.
+	FormatCombined = "combined"
+	// FormatJSON is one JSON object per line with NGINX-style field names.
+	FormatJSON = "json"
+	// FormatCaddy is Caddy's structured access log.
+	FormatCaddy = "caddy"
+	// FormatLegacy is the format ParseLogEntry originally understood:
+	// [time] "GET /code HTTP/1.1" status "user agent" "ip" "referrer"
+	FormatLegacy = "legacy"
+)
+
+var ErrMalformedLogEntry = errors.New("malformed log entry")
+
+// LogEntry is one request read from an access log. ShortCode is set for GET
+// requests whose path has a single segment; Status is 0 when the format does
+// not record it.
+type LogEntry struct {
+	Time      time.Time
+	IP        string
+	Method    string
+	Path      string
+	ShortCode string
+	Status    int
+	Referrer  string
+	UserAgent string
+}
+
+var (
+	combinedRegex = regexp.MustCompile(`^(\S+) \S+ \S+ \[([^\]]+)\] "([^"\\]*(?:\\.[^"\\]*)*)" (\d{3}|-) \S+(?: "([^"\\]*(?:\\.[^"\\]*)*)" "([^"\\]*(?:\\.[^"\\]*)*)")?`)
+	legacyRegex   = regexp.MustCompile(`^\[([^\]]+)\] "([^"]*)" (\d{3})(?: "([^"]*)")?(?: "([^"]*)")?(?: "([^"]*)")?`)
+
+	logTimeLayouts = []string{
+		"02/Jan/2006:15:04:05 -0700",
+		time.RFC3339Nano,
+		time.DateTime,
+	}
+)
+
+// LogParser parses access log lines of one format.
+type LogParser struct {
+	format string
+}
+
+func NewLogParser(format string) (*LogParser, error) {
+	switch format {
+	case "":
+		format = FormatAuto
+	case FormatAuto, FormatCombined, FormatJSON, FormatCaddy, FormatLegacy:
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+
+	return &LogParser{format: format}, nil
+}
+
+// Parse reads one log line. Lines that do not match the format return an
+// error wrapping ErrMalformedLogEntry.
+func (parser *LogParser) Parse(line string) (*LogEntry, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil, fmt.Errorf("%w: empty line", ErrMalformedLogEntry)
+	}
+
+	switch parser.format {
+	case FormatCombined:
+		return parseCombined(line)
+	case FormatJSON:
+		return parseJSONLine(line)
+	case FormatCaddy:
+		return parseCaddy(line)
+	case FormatLegacy:
+		return parseLegacy(line)
+	default:
+		return parseAuto(line)
+	}
+}
+
+func parseAuto(line string) (*LogEntry, error) {
+	switch {
+	case strings.HasPrefix(line, "{"):
+		var probe struct {
+			Request json.RawMessage `json:"request"`
+		}
+		if err := json.Unmarshal([]byte(line), &probe); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformedLogEntry, err)
+		}
+		if len(probe.Request) > 0 && probe.Request[0] == '{' {
+			return parseCaddy(line)
+		}
+
+		return parseJSONLine(line)
+	case strings.HasPrefix(line, "["):
+		return parseLegacy(line)
+	default:
+		return parseCombined(line)
+	}
+}
+
+func parseCombined(line string) (*LogEntry, error) {
+	matches := combinedRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, fmt.Errorf("%w: not in combined log format", ErrMalformedLogEntry)
+	}
+
+	timestamp, err := parseLogTime(matches[2])
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &LogEntry{
+		Time:      timestamp,
+		Referrer:  cleanField(unescapeQuoted(matches[5])),
+		UserAgent: cleanField(unescapeQuoted(matches[6])),
+	}
+
+	if entry.IP, err = parseIP(matches[1]); err != nil {
+		return nil, err
+	}
+	if entry.Status, err = parseStatus(matches[4]); err != nil {
+		return nil, err
+	}
+	if err := entry.setRequestLine(unescapeQuoted(matches[3])); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func parseLegacy(line string) (*LogEntry, error) {
+	matches := legacyRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, fmt.Errorf("%w: not in legacy log format", ErrMalformedLogEntry)
+	}
+
+	timestamp, err := parseLogTime(matches[1])
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &LogEntry{
+		Time:      timestamp,
+		UserAgent: cleanField(matches[4]),
+		Referrer:  cleanField(matches[6]),
+	}
+
+	if entry.IP, err = parseIP(matches[5]); err != nil {
+		return nil, err
+	}
+	if entry.Status, err = parseStatus(matches[3]); err != nil {
+		return nil, err
+	}
+	if err := entry.setRequestLine(matches[2]); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// jsonLogLine covers the field names of common NGINX and generic JSON access
+// log configurations.
+type jsonLogLine struct {
+	Time          string          `json:"time"`
+	TimeISO8601   string          `json:"time_iso8601"`
+	TimeLocal     string          `json:"time_local"`
+	Timestamp     json.RawMessage `json:"timestamp"`
+	RemoteAddr    string          `json:"remote_addr"`
+	IP            string          `json:"ip"`
+	Method        string          `json:"method"`
+	RequestMethod string          `json:"request_method"`
+	Path          string          `json:"path"`
+	URI           string          `json:"uri"`
+	RequestURI    string          `json:"request_uri"`
+	Request       string          `json:"request"`
+	Status        json.RawMessage `json:"status"`
+	Referrer      string          `json:"referrer"`
+	Referer       string          `json:"http_referer"`
+	UserAgent     string          `json:"user_agent"`
+	HTTPUserAgent string          `json:"http_user_agent"`
+}
+
+func parseJSONLine(line string) (*LogEntry, error) {
+	var raw jsonLogLine
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedLogEntry, err)
+	}
+
+	timestamp, err := parseJSONTime(firstNonEmpty(raw.Time, raw.TimeISO8601, raw.TimeLocal), raw.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &LogEntry{
+		Time:      timestamp,
+		Referrer:  cleanField(firstNonEmpty(raw.Referrer, raw.Referer)),
+		UserAgent: cleanField(firstNonEmpty(raw.UserAgent, raw.HTTPUserAgent)),
+	}
+
+	if entry.IP, err = parseIP(firstNonEmpty(raw.RemoteAddr, raw.IP)); err != nil {
+		return nil, err
+	}
+	if entry.Status, err = parseJSONStatus(raw.Status); err != nil {
+		return nil, err
+	}
+
+	method := firstNonEmpty(raw.Method, raw.RequestMethod)
+	target := firstNonEmpty(raw.Path, raw.RequestURI, raw.URI)
+	if method == "" || target == "" {
+		if raw.Request == "" {
+			return nil, fmt.Errorf("%w: missing request method or path", ErrMalformedLogEntry)
+		}
+
+		if err := entry.setRequestLine(raw.Request); err != nil {
+			return nil, err
+		}
+
+		return entry, nil
+	}
+
+	entry.setRequest(method, target)
+
+	return entry, nil
+}
+
+type caddyLogLine struct {
+	Timestamp json.RawMessage `json:"ts"`
+	Status    json.RawMessage `json:"status"`
+	Request   *struct {
+		RemoteIP string              `json:"remote_ip"`
+		ClientIP string              `json:"client_ip"`
+		Method   string              `json:"method"`
+		URI      string              `json:"uri"`
+		Headers  map[string][]string `json:"headers"`
+	} `json:"request"`
+}
+
+func parseCaddy(line string) (*LogEntry, error) {
+	var raw caddyLogLine
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedLogEntry, err)
+	}
+
+	if raw.Request == nil || raw.Request.Method == "" || raw.Request.URI == "" {
+		return nil, fmt.Errorf("%w: missing request", ErrMalformedLogEntry)
+	}
+
+	timestamp, err := parseJSONTime("", raw.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := firstHeaders(raw.Request.Headers)
+	entry := &LogEntry{
+		Time:      timestamp,
+		Referrer:  cleanField(headers["Referer"]),
+		UserAgent: cleanField(headers["User-Agent"]),
+	}
+
+	// client_ip honours Caddy's trusted proxies; remote_ip is the peer.
+	if entry.IP, err = parseIP(firstNonEmpty(raw.Request.ClientIP, raw.Request.RemoteIP)); err != nil {
+		return nil, err
+	}
+	if entry.Status, err = parseJSONStatus(raw.Status); err != nil {
+		return nil, err
+	}
+	entry.setRequest(raw.Request.Method, raw.Request.URI)
+
+	return entry, nil
+}
+
+// setRequestLine reads a request line such as "GET /abc123 HTTP/1.1".
+func (entry *LogEntry) setRequestLine(requestLine string) error {
+	fields := strings.Fields(requestLine)
+	if len(fields) < 2 {
+		return fmt.Errorf("%w: invalid request line %q", ErrMalformedLogEntry, requestLine)
+	}
+
+	entry.setRequest(fields[0], fields[1])
+
+	return nil
+}
+
+func (entry *LogEntry) setRequest(method, target string) {
+	entry.Method = strings.ToUpper(method)
+	entry.Path = target
+	if parsed, err := url.ParseRequestURI(target); err == nil {
+		entry.Path = parsed.Path
+	}
+
+	if entry.Method == "GET" {
+		entry.ShortCode = shortCodeFromPath(entry.Path)
+	}
+}
+
+// shortCodeFromPath returns the code of a "/:shortCode" path, or "" for any
+// other path.
+func shortCodeFromPath(path string) string {
+	code := strings.TrimPrefix(path, "/")
+	if code == "" || strings.Contains(code, "/") || len(code) == len(path) {
+		return ""
+	}
+
+	return code
+}
+
+func parseLogTime(value string) (time.Time, error) {
+	for _, layout := range logTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: invalid time %q", ErrMalformedLogEntry, value)
+}
+
+// parseJSONTime accepts a formatted time or a Unix timestamp in seconds, as
+// Caddy writes by default.
+func parseJSONTime(formatted string, raw json.RawMessage) (time.Time, error) {
+	if formatted != "" {
+		return parseLogTime(formatted)
+	}
+
+	var seconds float64
+	if err := json.Unmarshal(raw, &seconds); err == nil {
+		whole, fraction := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(fraction*1e9)).UTC(), nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil && text != "" {
+		return parseLogTime(text)
+	}
+
+	return time.Time{}, fmt.Errorf("%w: missing time", ErrMalformedLogEntry)
+}
+
+func parseJSONStatus(raw json.RawMessage) (int, error) {
+	if len(raw) == 0 {
+		return 0, nil
+	}
+
+	var status int
+	if err := json.Unmarshal(raw, &status); err == nil {
+		return status, nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		return 0, fmt.Errorf("%w: invalid status %s", ErrMalformedLogEntry, raw)
+	}
+
+	return parseStatus(text)
+}
+
+func parseStatus(value string) (int, error) {
+	if value == "" || value == "-" {
+		return 0, nil
+	}
+
+	status, err := strconv.Atoi(value)
+	if err != nil || status < 100 || status > 599 {
+		return 0, fmt.Errorf("%w: invalid status %q", ErrMalformedLogEntry, value)
+	}
+
+	return status, nil
+}
+
+// parseIP validates an IPv4 or IPv6 address, stripping a port or brackets.
+// A missing address ("-" or empty) is allowed.
+func parseIP(value string) (string, error) {
+	if value == "" || value == "-" {
+		return "", nil
+	}
+
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	value = strings.Trim(value, "[]")
+
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return "", fmt.Errorf("%w: invalid IP address %q", ErrMalformedLogEntry, value)
+	}
+
+	return ip.String(), nil
+}
+
+// unescapeQuoted undoes the \" and \\ escaping of quoted log fields.
+func unescapeQuoted(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	return strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(value)
+}
+
+// cleanField maps the "-" placeholder for a missing value to "".
+func cleanField(value string) string {
+	if value == "-" {
+		return ""
+	}
+
+	return value
+}
+
+// firstHeaders takes the first value of each header, keyed by canonical name.
+func firstHeaders(headers map[string][]string) map[string]string {
+	result := make(map[string]string, len(headers))
+	for name, values := range headers {
+		if len(values) > 0 {
+			result[textproto.CanonicalMIMEHeaderKey(name)] = values[0]
+		}
+	}
+
+	return result
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,163 @@
+package parser
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLogParserFormats(t *testing.T) {
+	clickTime := time.Date(2026, 3, 10, 13, 55, 36, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		format   string
+		line     string
+		expected *LogEntry
+	}{
+		{
+			name:   "Combined with IPv6 and escaped quotes",
+			format: FormatCombined,
+			line:   `2001:db8::1 - frank [10/Mar/2026:13:55:36 +0000] "GET /abc123?utm_source=x HTTP/2.0" 302 512 "https://news.example/item" "Agent \"quoted\"/1.0"`,
+			expected: &LogEntry{
+				Time:      clickTime,
+				IP:        "2001:db8::1",
+				Method:    "GET",
+				Path:      "/abc123",
+				ShortCode: "abc123",
+				Status:    302,
+				Referrer:  "https://news.example/item",
+				UserAgent: `Agent "quoted"/1.0`,
+			},
+		},
+		{
+			name:   "Common log format",
+			format: FormatCombined,
+			line:   `192.0.2.1 - - [10/Mar/2026:13:55:36 +0000] "POST /shorten HTTP/1.1" 201 87`,
+			expected: &LogEntry{
+				Time:   clickTime,
+				IP:     "192.0.2.1",
+				Method: "POST",
+				Path:   "/shorten",
+				Status: 201,
+			},
+		},
+		{
+			name:   "Combined with missing referrer",
+			format: FormatAuto,
+			line:   `192.0.2.1 - - [10/Mar/2026:13:55:36 +0000] "GET /api/v1/links HTTP/1.1" 200 1024 "-" "curl/8.0"`,
+			expected: &LogEntry{
+				Time:      clickTime,
+				IP:        "192.0.2.1",
+				Method:    "GET",
+				Path:      "/api/v1/links",
+				Status:    200,
+				UserAgent: "curl/8.0",
+			},
+		},
+		{
+			name:   "NGINX JSON",
+			format: FormatJSON,
+			line:   `{"time_iso8601":"2026-03-10T13:55:36+00:00","remote_addr":"192.0.2.1","request_method":"GET","request_uri":"/abc123","status":"301","http_referer":"https://social.example/","http_user_agent":"Mozilla/5.0"}`,
+			expected: &LogEntry{
+				Time:      clickTime,
+				IP:        "192.0.2.1",
+				Method:    "GET",
+				Path:      "/abc123",
+				ShortCode: "abc123",
+				Status:    301,
+				Referrer:  "https://social.example/",
+				UserAgent: "Mozilla/5.0",
+			},
+		},
+		{
+			name:   "JSON with request line",
+			format: FormatAuto,
+			line:   `{"time":"10/Mar/2026:13:55:36 +0000","ip":"[2001:db8::2]:443","request":"GET /abc123 HTTP/1.1","status":308}`,
+			expected: &LogEntry{
+				Time:      clickTime,
+				IP:        "2001:db8::2",
+				Method:    "GET",
+				Path:      "/abc123",
+				ShortCode: "abc123",
+				Status:    308,
+			},
+		},
+		{
+			name:   "Caddy",
+			format: FormatAuto,
+			line:   `{"level":"info","ts":1773150936.5,"logger":"http.log.access","msg":"handled request","request":{"remote_ip":"10.0.0.1","client_ip":"2001:db8::3","proto":"HTTP/2.0","method":"GET","host":"sho.rt","uri":"/abc123","headers":{"user-agent":["Mozilla/5.0"],"Referer":["https://mail.example/"]}},"status":301}`,
+			expected: &LogEntry{
+				Time:      clickTime.Add(500 * time.Millisecond),
+				IP:        "2001:db8::3",
+				Method:    "GET",
+				Path:      "/abc123",
+				ShortCode: "abc123",
+				Status:    301,
+				Referrer:  "https://mail.example/",
+				UserAgent: "Mozilla/5.0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := NewLogParser(tt.format)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			result, err := parser.Parse(tt.line)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !result.Time.Equal(tt.expected.Time) {
+				t.Errorf("Time: expected %v, got %v", tt.expected.Time, result.Time)
+			}
+
+			result.Time = tt.expected.Time
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestLogParserMalformed(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		line   string
+	}{
+		{"Invalid IP", FormatCombined, `999.0.0.1 - - [10/Mar/2026:13:55:36 +0000] "GET /abc123 HTTP/1.1" 301 0 "-" "-"`},
+		{"Invalid time", FormatCombined, `192.0.2.1 - - [yesterday] "GET /abc123 HTTP/1.1" 301 0 "-" "-"`},
+		{"Invalid status", FormatCombined, `192.0.2.1 - - [10/Mar/2026:13:55:36 +0000] "GET /abc123 HTTP/1.1" 999 0 "-" "-"`},
+		{"Invalid request line", FormatCombined, `192.0.2.1 - - [10/Mar/2026:13:55:36 +0000] "garbage" 400 0 "-" "-"`},
+		{"JSON line for combined", FormatCombined, `{"status":301}`},
+		{"Broken JSON", FormatJSON, `{"status":`},
+		{"JSON without request", FormatJSON, `{"time":"2026-03-10T13:55:36Z","status":301}`},
+		{"JSON without time", FormatJSON, `{"method":"GET","path":"/abc123"}`},
+		{"Caddy without request", FormatCaddy, `{"ts":1773150936,"status":301}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := NewLogParser(tt.format)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if _, err := parser.Parse(tt.line); !errors.Is(err, ErrMalformedLogEntry) {
+				t.Errorf("Expected ErrMalformedLogEntry, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewLogParserUnknownFormat(t *testing.T) {
+	if _, err := NewLogParser("syslog"); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
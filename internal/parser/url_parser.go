@@ -68,33 +68,11 @@ func (parser *URLParser) Parse(rawURL string) (*URLParseResult, error) {
 		IsValid:     true,
 	}, nil
 }
-func (parser *URLParser) ParseLogEntry(logEntry string) (map[string]string, error) {
-	// Example log format: [timestamp] "GET /abc123 HTTP/1.1" 301 "Mozilla/5.0 ..." "192.168.1.1" "referrer"
-	data := make(map[string]string)
 
-	shortCodeRegex := regexp.MustCompile(`GET /([a-zA-Z0-9]+)`)
-	matches := shortCodeRegex.FindStringSubmatch(logEntry)
-	if len(matches) > 1 {
-		data["shortcode"] = matches[1]
-	}
-
-	ipRegex := regexp.MustCompile(`"(\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3})"`)
-	ipMatches := ipRegex.FindStringSubmatch(logEntry)
-	if len(ipMatches) > 1 {
-		data["ip"] = ipMatches[1]
-	}
-
-	uaRegex := regexp.MustCompile(`"(Mozilla[^"]*)"`)
-	uaMatches := uaRegex.FindStringSubmatch(logEntry)
-	if len(uaMatches) > 1 {
-		data["user_agent"] = uaMatches[1]
-	}
-
-	tsRegex := regexp.MustCompile(`\[(.*?)\]`)
-	tsMatches := tsRegex.FindStringSubmatch(logEntry)
-	if len(tsMatches) > 1 {
-		data["timestamp"] = tsMatches[1]
-	}
-
-	return data, nil
+// ParseLogEntry parses an access log line in any of the supported formats,
+// detected from the shape of the line.
+func (parser *URLParser) ParseLogEntry(logEntry string) (*LogEntry, error) {
+	return autoLogParser.Parse(logEntry)
 }
+
+var autoLogParser = &LogParser{format: FormatAuto}
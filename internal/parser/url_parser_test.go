@@ -1,8 +1,10 @@
 package parser
 
 import (
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestURLParserParse(t *testing.T) {
@@ -133,52 +135,84 @@ func TestURLParserParseLogEntry(t *testing.T) {
 	parser := NewURLParser()
 
 	tests := []struct {
-		name     string
-		logEntry string
-		expected map[string]string
+		name        string
+		logEntry    string
+		expected    *LogEntry
+		expectError bool
 	}{
 		{
 			name:     "Valid log entry",
 			logEntry: `[2023-06-10 12:34:56] "GET /abc123 HTTP/1.1" 301 "Mozilla/5.0 (Windows NT 10.0; Win64; x64)" "192.168.1.1" "https://referrer.com"`,
-			expected: map[string]string{
-				"shortcode":  "abc123",
-				"ip":         "192.168.1.1",
-				"user_agent": "Mozilla/5.0 (Windows NT 10.0; Win64; x64)",
-				"timestamp":  "2023-06-10 12:34:56",
+			expected: &LogEntry{
+				Time:      time.Date(2023, 6, 10, 12, 34, 56, 0, time.UTC),
+				IP:        "192.168.1.1",
+				Method:    "GET",
+				Path:      "/abc123",
+				ShortCode: "abc123",
+				Status:    301,
+				Referrer:  "https://referrer.com",
+				UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64)",
 			},
 		},
 		{
 			name:     "Missing parts",
 			logEntry: `[2023-06-10 12:34:56] "GET /abc123 HTTP/1.1" 301`,
-			expected: map[string]string{
-				"shortcode": "abc123",
-				"timestamp": "2023-06-10 12:34:56",
+			expected: &LogEntry{
+				Time:      time.Date(2023, 6, 10, 12, 34, 56, 0, time.UTC),
+				Method:    "GET",
+				Path:      "/abc123",
+				ShortCode: "abc123",
+				Status:    301,
 			},
 		},
 		{
-			name:     "Empty log entry",
-			logEntry: "",
-			expected: map[string]string{},
+			name:     "Combined log format",
+			logEntry: `192.168.1.1 - - [10/Jun/2023:12:34:56 +0000] "GET /abc123 HTTP/1.1" 301 0 "https://referrer.com" "curl/8.0"`,
+			expected: &LogEntry{
+				Time:      time.Date(2023, 6, 10, 12, 34, 56, 0, time.UTC),
+				IP:        "192.168.1.1",
+				Method:    "GET",
+				Path:      "/abc123",
+				ShortCode: "abc123",
+				Status:    301,
+				Referrer:  "https://referrer.com",
+				UserAgent: "curl/8.0",
+			},
+		},
+		{
+			name:        "Empty log entry",
+			logEntry:    "",
+			expectError: true,
+		},
+		{
+			name:        "Malformed log entry",
+			logEntry:    "not a log line",
+			expectError: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result, err := parser.ParseLogEntry(tt.logEntry)
+			if tt.expectError {
+				if !errors.Is(err, ErrMalformedLogEntry) {
+					t.Errorf("Expected ErrMalformedLogEntry, got %v", err)
+				}
+				return
+			}
 
 			if err != nil {
 				t.Errorf("Unexpected error: %v", err)
 				return
 			}
 
-			for key, expectedValue := range tt.expected {
-				if result[key] != expectedValue {
-					t.Errorf("For key %s: expected %s, got %s", key, expectedValue, result[key])
-				}
+			if !result.Time.Equal(tt.expected.Time) {
+				t.Errorf("Time: expected %v, got %v", tt.expected.Time, result.Time)
 			}
 
-			if len(result) != len(tt.expected) {
-				t.Errorf("Expected %d keys, got %d keys", len(tt.expected), len(result))
+			result.Time = tt.expected.Time
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, result)
 			}
 		})
 	}
@@ -0,0 +1,180 @@
+package parser
+
+import "strings"
+
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+// UserAgent is what could be derived from a User-Agent header. Unknown parts
+// are left empty; for bots Browser holds the bot name.
+type UserAgent struct {
+	Browser string
+	OS      string
+	Device  string
+	IsBot   bool
+}
+
+type uaRule struct {
+	token string
+	name  string
+}
+
+// knownBots maps lowercase user agent tokens to bot names. Link preview
+// crawlers come first as they are the most common source of fake clicks.
+var knownBots = []uaRule{
+	{"slackbot", "Slackbot"},
+	{"twitterbot", "Twitterbot"},
+	{"facebookexternalhit", "Facebook"},
+	{"facebookcatalog", "Facebook"},
+	{"linkedinbot", "LinkedInBot"},
+	{"discordbot", "Discordbot"},
+	{"telegrambot", "TelegramBot"},
+	{"whatsapp", "WhatsApp"},
+	{"skypeuripreview", "Skype"},
+	{"pinterestbot", "Pinterestbot"},
+	{"redditbot", "Redditbot"},
+	{"embedly", "Embedly"},
+	{"googlebot", "Googlebot"},
+	{"bingbot", "Bingbot"},
+	{"applebot", "Applebot"},
+	{"duckduckbot", "DuckDuckBot"},
+	{"yandexbot", "YandexBot"},
+	{"baiduspider", "Baiduspider"},
+	{"uptimerobot", "UptimeRobot"},
+	{"pingdom", "Pingdom"},
+	{"statuscake", "StatusCake"},
+	{"site24x7", "Site24x7"},
+	{"datadogsynthetics", "Datadog"},
+	{"newrelicpinger", "New Relic"},
+	{"headlesschrome", "HeadlessChrome"},
+	{"curl/", "curl"},
+	{"wget/", "Wget"},
+	{"python-requests", "python-requests"},
+	{"go-http-client", "Go-http-client"},
+	{"okhttp", "okhttp"},
+	{"node-fetch", "node-fetch"},
+}
+
+// botHints flag the bots that are not listed by name. A hint only counts at
+// the end of a product token, as in "ExampleBot/1.0" or "(compatible;
+// examplebot)", so device models such as "CUBOT X30" are not mistaken for
+// bots.
+var botHints = []string{"bot", "crawler", "spider", "crawl", "slurp", "monitor", "preview", "fetcher", "scraper"}
+
+// botHintEnds are the characters that may follow a bot hint.
+const botHintEnds = "/;)-_+"
+
+// browserRules are checked in order; Chromium based browsers also send
+// "Chrome/" and Chrome also sends "Safari/", so the more specific come first.
+var browserRules = []uaRule{
+	{"edg/", "Edge"},
+	{"edga/", "Edge"},
+	{"edgios/", "Edge"},
+	{"edge/", "Edge"},
+	{"opr/", "Opera"},
+	{"opera", "Opera"},
+	{"samsungbrowser/", "Samsung Internet"},
+	{"yabrowser/", "Yandex Browser"},
+	{"vivaldi/", "Vivaldi"},
+	{"firefox/", "Firefox"},
+	{"fxios/", "Firefox"},
+	{"crios/", "Chrome"},
+	{"chrome/", "Chrome"},
+	{"safari/", "Safari"},
+	{"msie ", "Internet Explorer"},
+	{"trident/", "Internet Explorer"},
+}
+
+var osRules = []uaRule{
+	{"windows phone", "Windows Phone"},
+	{"windows", "Windows"},
+	{"iphone", "iOS"},
+	{"ipad", "iOS"},
+	{"ipod", "iOS"},
+	{"android", "Android"},
+	{"cros ", "ChromeOS"},
+	{"mac os x", "macOS"},
+	{"macintosh", "macOS"},
+	{"linux", "Linux"},
+}
+
+// ParseUserAgent classifies a User-Agent header by matching well-known
+// tokens. It is deliberately approximate: it aims at grouping clicks, not at
+// identifying exact versions.
+func ParseUserAgent(header string) UserAgent {
+	ua := strings.ToLower(header)
+	if strings.TrimSpace(ua) == "" {
+		return UserAgent{}
+	}
+
+	if name, ok := botName(ua); ok {
+		return UserAgent{Browser: name, OS: matchRule(ua, osRules), Device: DeviceBot, IsBot: true}
+	}
+
+	os := matchRule(ua, osRules)
+
+	return UserAgent{
+		Browser: matchRule(ua, browserRules),
+		OS:      os,
+		Device:  deviceType(ua, os),
+	}
+}
+
+func botName(ua string) (string, bool) {
+	if name := matchRule(ua, knownBots); name != "" {
+		return name, true
+	}
+
+	for _, hint := range botHints {
+		if endsToken(ua, hint) {
+			return "Other bot", true
+		}
+	}
+
+	return "", false
+}
+
+// endsToken reports whether hint occurs in ua followed by the end of the
+// string or one of botHintEnds.
+func endsToken(ua, hint string) bool {
+	for rest := ua; ; {
+		i := strings.Index(rest, hint)
+		if i < 0 {
+			return false
+		}
+
+		rest = rest[i+len(hint):]
+		if rest == "" || strings.IndexByte(botHintEnds, rest[0]) >= 0 {
+			return true
+		}
+	}
+}
+
+func deviceType(ua, os string) string {
+	switch {
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet"):
+		return DeviceTablet
+	case os == "Android" && !strings.Contains(ua, "mobile"):
+		return DeviceTablet
+	case strings.Contains(ua, "mobile") || os == "iOS" || os == "Android" || os == "Windows Phone":
+		return DeviceMobile
+	case os == "Windows" || os == "macOS" || os == "Linux" || os == "ChromeOS":
+		return DeviceDesktop
+	default:
+		return ""
+	}
+}
+
+func matchRule(ua string, rules []uaRule) string {
+	for _, rule := range rules {
+		if strings.Contains(ua, rule.token) {
+			return rule.name
+		}
+	}
+
+	return ""
+}
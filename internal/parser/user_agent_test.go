@@ -0,0 +1,116 @@
+package parser
+
+import "testing"
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected UserAgent
+	}{
+		{
+			name:     "Chrome on Windows",
+			header:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			expected: UserAgent{Browser: "Chrome", OS: "Windows", Device: DeviceDesktop},
+		},
+		{
+			name:     "Edge on Windows",
+			header:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.51",
+			expected: UserAgent{Browser: "Edge", OS: "Windows", Device: DeviceDesktop},
+		},
+		{
+			name:     "Firefox on Linux",
+			header:   "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+			expected: UserAgent{Browser: "Firefox", OS: "Linux", Device: DeviceDesktop},
+		},
+		{
+			name:     "Safari on macOS",
+			header:   "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_4_1) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4.1 Safari/605.1.15",
+			expected: UserAgent{Browser: "Safari", OS: "macOS", Device: DeviceDesktop},
+		},
+		{
+			name:     "Safari on iPhone",
+			header:   "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+			expected: UserAgent{Browser: "Safari", OS: "iOS", Device: DeviceMobile},
+		},
+		{
+			name:     "Chrome on iPad",
+			header:   "Mozilla/5.0 (iPad; CPU OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/124.0.6367.88 Mobile/15E148 Safari/604.1",
+			expected: UserAgent{Browser: "Chrome", OS: "iOS", Device: DeviceTablet},
+		},
+		{
+			name:     "Samsung Internet on Android phone",
+			header:   "Mozilla/5.0 (Linux; Android 14; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/24.0 Chrome/117.0.0.0 Mobile Safari/537.36",
+			expected: UserAgent{Browser: "Samsung Internet", OS: "Android", Device: DeviceMobile},
+		},
+		{
+			name:     "Chrome on Android tablet",
+			header:   "Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			expected: UserAgent{Browser: "Chrome", OS: "Android", Device: DeviceTablet},
+		},
+		{
+			name:     "Slack link preview",
+			header:   "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)",
+			expected: UserAgent{Browser: "Slackbot", Device: DeviceBot, IsBot: true},
+		},
+		{
+			name:     "Twitter card crawler",
+			header:   "Twitterbot/1.0",
+			expected: UserAgent{Browser: "Twitterbot", Device: DeviceBot, IsBot: true},
+		},
+		{
+			name:     "Facebook crawler",
+			header:   "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)",
+			expected: UserAgent{Browser: "Facebook", Device: DeviceBot, IsBot: true},
+		},
+		{
+			name:     "Googlebot smartphone",
+			header:   "Mozilla/5.0 (Linux; Android 6.0.1; Nexus 5X Build/MMB29P) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			expected: UserAgent{Browser: "Googlebot", OS: "Android", Device: DeviceBot, IsBot: true},
+		},
+		{
+			name:     "Uptime monitor",
+			header:   "Mozilla/5.0+(compatible; UptimeRobot/2.0; http://www.uptimerobot.com/)",
+			expected: UserAgent{Browser: "UptimeRobot", Device: DeviceBot, IsBot: true},
+		},
+		{
+			name:     "curl",
+			header:   "curl/8.5.0",
+			expected: UserAgent{Browser: "curl", Device: DeviceBot, IsBot: true},
+		},
+		{
+			name:     "Unnamed crawler",
+			header:   "ExampleCrawler/2.0 (+https://crawler.example)",
+			expected: UserAgent{Browser: "Other bot", Device: DeviceBot, IsBot: true},
+		},
+		{
+			name:     "Unnamed bot in compatible section",
+			header:   "Mozilla/5.0 (compatible; ExampleBot; +https://bot.example)",
+			expected: UserAgent{Browser: "Other bot", Device: DeviceBot, IsBot: true},
+		},
+		{
+			name:     "Phone model containing bot",
+			header:   "Mozilla/5.0 (Linux; Android 10; CUBOT X30 Build/QP1A.190711.020) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36",
+			expected: UserAgent{Browser: "Chrome", OS: "Android", Device: DeviceMobile},
+		},
+		{
+			name:     "Device model containing monitor",
+			header:   "Mozilla/5.0 (Linux; Android 11; Smart Monitor M8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			expected: UserAgent{Browser: "Chrome", OS: "Android", Device: DeviceTablet},
+		},
+		{
+			name:     "Empty",
+			header:   "",
+			expected: UserAgent{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ParseUserAgent(tt.header)
+			if result != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+// Package qr renders QR codes as PNG or SVG images, in process. Encoding is
+// left to go-qrcode; this package only lays the modules out, so the size,
+// quiet zone and colors are fully configurable.
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// Error correction levels, recovering about 7, 15, 25 and 30% of the code.
+const (
+	LevelLow      = "L"
+	LevelMedium   = "M"
+	LevelQuartile = "Q"
+	LevelHigh     = "H"
+)
+
+const (
+	DefaultSize   = 256
+	MinSize       = 32
+	MaxSize       = 2048
+	DefaultMargin = 4 // the quiet zone the QR specification asks for
+	MaxMargin     = 32
+)
+
+var levels = map[string]qrcode.RecoveryLevel{
+	LevelLow:      qrcode.Low,
+	LevelMedium:   qrcode.Medium,
+	LevelQuartile: qrcode.High,
+	LevelHigh:     qrcode.Highest,
+}
+
+// Options describe the image. Size is the width and height in pixels and
+// Margin the quiet zone around the code, in modules.
+type Options struct {
+	Format     string
+	Size       int
+	Level      string
+	Margin     int
+	Foreground color.NRGBA
+	Background color.NRGBA
+}
+
+// DefaultOptions is a 256 pixel black on white PNG with medium error
+// correction.
+func DefaultOptions() Options {
+	return Options{
+		Format:     FormatPNG,
+		Size:       DefaultSize,
+		Level:      LevelMedium,
+		Margin:     DefaultMargin,
+		Foreground: color.NRGBA{A: 0xff},
+		Background: color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	}
+}
+
+func (opts Options) Validate() error {
+	if opts.Format != FormatPNG && opts.Format != FormatSVG {
+		return fmt.Errorf("format must be png or svg")
+	}
+	if opts.Size < MinSize || opts.Size > MaxSize {
+		return fmt.Errorf("size must be between %d and %d pixels", MinSize, MaxSize)
+	}
+	if _, ok := levels[opts.Level]; !ok {
+		return fmt.Errorf("level must be one of L, M, Q or H")
+	}
+	if opts.Margin < 0 || opts.Margin > MaxMargin {
+		return fmt.Errorf("margin must be between 0 and %d modules", MaxMargin)
+	}
+
+	return nil
+}
+
+// ContentType returns the media type of images in format.
+func ContentType(format string) string {
+	if format == FormatSVG {
+		return "image/svg+xml"
+	}
+
+	return "image/png"
+}
+
+// ParseColor reads a hex color: RGB, RRGGBB or RRGGBBAA, with or without a
+// leading #.
+func ParseColor(value string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 8 || err != nil {
+		return color.NRGBA{}, fmt.Errorf("%q is not a hex color", value)
+	}
+
+	return color.NRGBA{R: uint8(n >> 24), G: uint8(n >> 16), B: uint8(n >> 8), A: uint8(n)}, nil
+}
+
+// Render encodes content as a QR code image.
+func Render(content string, opts Options) ([]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	code, err := qrcode.New(content, levels[opts.Level])
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	code.DisableBorder = true
+	modules := code.Bitmap()
+
+	if opts.Format == FormatSVG {
+		return renderSVG(modules, opts), nil
+	}
+
+	return renderPNG(modules, opts)
+}
+
+// layout fits the code and its margin into size pixels with a whole number
+// of pixels per module, so every module is the same size. What does not
+// divide evenly is added to the margin. A code too large for size gets one
+// pixel per module and a larger image.
+func layout(modules, margin, size int) (scale, offset, total int) {
+	span := modules + 2*margin
+	scale = max(size/span, 1)
+	total = max(size, span*scale)
+
+	return scale, (total - modules*scale) / 2, total
+}
+
+func renderPNG(modules [][]bool, opts Options) ([]byte, error) {
+	scale, offset, total := layout(len(modules), opts.Margin, opts.Size)
+
+	img := image.NewPaletted(image.Rect(0, 0, total, total), color.Palette{opts.Background, opts.Foreground})
+	for y, row := range modules {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+
+			for py := offset + y*scale; py < offset+(y+1)*scale; py++ {
+				start := img.PixOffset(offset+x*scale, py)
+				for i := start; i < start+scale; i++ {
+					img.Pix[i] = 1
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// renderSVG draws the dark modules as one path, a rectangle per horizontal
+// run, in a view box measured in modules.
+func renderSVG(modules [][]bool, opts Options) []byte {
+	span := len(modules) + 2*opts.Margin
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, span, span)
+	if opts.Background.A > 0 {
+		fmt.Fprintf(&buf, `<rect width="100%%" height="100%%"%s/>`, svgFill(opts.Background))
+	}
+
+	buf.WriteString(`<path d="`)
+	for y, row := range modules {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+
+			run := 1
+			for x+run < len(row) && row[x+run] {
+				run++
+			}
+			fmt.Fprintf(&buf, "M%d %dh%dv1h-%dz", x+opts.Margin, y+opts.Margin, run, run)
+			x += run
+		}
+	}
+	fmt.Fprintf(&buf, `"%s/></svg>`, svgFill(opts.Foreground))
+
+	return buf.Bytes()
+}
+
+func svgFill(c color.NRGBA) string {
+	fill := fmt.Sprintf(` fill="#%02x%02x%02x"`, c.R, c.G, c.B)
+	if c.A < 0xff {
+		fill += fmt.Sprintf(` fill-opacity="%.3g"`, float64(c.A)/0xff)
+	}
+
+	return fill
+}
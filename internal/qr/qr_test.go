@@ -0,0 +1,151 @@
+package qr
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+	"testing"
+
+	qrcode "github.com/skip2/go-qrcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testContent = "https://sho.rt/abc123"
+
+func testModules(t *testing.T, level qrcode.RecoveryLevel) [][]bool {
+	t.Helper()
+
+	code, err := qrcode.New(testContent, level)
+	require.NoError(t, err)
+	code.DisableBorder = true
+
+	return code.Bitmap()
+}
+
+func TestRender_PNG(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Size = 300
+	opts.Foreground = color.NRGBA{R: 0x11, G: 0x22, B: 0x33, A: 0xff}
+
+	data, err := Render(testContent, opts)
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 300, img.Bounds().Dx())
+	assert.Equal(t, 300, img.Bounds().Dy())
+
+	// Every module is drawn as a square of scale pixels, after the margin.
+	modules := testModules(t, qrcode.Medium)
+	scale, offset, _ := layout(len(modules), DefaultMargin, 300)
+	assert.GreaterOrEqual(t, offset, DefaultMargin*scale)
+
+	for y, row := range modules {
+		for x, dark := range row {
+			want := color.NRGBAModel.Convert(opts.Background)
+			if dark {
+				want = color.NRGBAModel.Convert(opts.Foreground)
+			}
+			got := color.NRGBAModel.Convert(img.At(offset+x*scale+scale/2, offset+y*scale+scale/2))
+			require.Equal(t, want, got, "module %d,%d", x, y)
+		}
+	}
+
+	assert.Equal(t, color.NRGBAModel.Convert(opts.Background), color.NRGBAModel.Convert(img.At(offset-1, offset-1)))
+}
+
+func TestRender_SVG(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Format = FormatSVG
+	opts.Margin = 2
+	opts.Level = LevelHigh
+	opts.Background = color.NRGBA{}
+
+	data, err := Render(testContent, opts)
+	require.NoError(t, err)
+	svg := string(data)
+
+	span := len(testModules(t, qrcode.Highest)) + 4
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="256" height="256"`))
+	assert.Contains(t, svg, `viewBox="0 0 `+strconv.Itoa(span)+" "+strconv.Itoa(span)+`"`)
+	// A transparent background is left out; the finder pattern starts with a
+	// run of seven dark modules inside the margin.
+	assert.NotContains(t, svg, "<rect")
+	assert.Contains(t, svg, `<path d="M2 2h7v1h-7z`)
+	assert.True(t, strings.HasSuffix(svg, `" fill="#000000"/></svg>`))
+}
+
+func TestRender_LevelsChangeTheCode(t *testing.T) {
+	low := DefaultOptions()
+	low.Level = LevelLow
+	high := DefaultOptions()
+	high.Level = LevelHigh
+
+	lowPNG, err := Render(testContent, low)
+	require.NoError(t, err)
+	highPNG, err := Render(testContent, high)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, lowPNG, highPNG)
+}
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Options)
+	}{
+		{"format", func(o *Options) { o.Format = "gif" }},
+		{"small", func(o *Options) { o.Size = MinSize - 1 }},
+		{"large", func(o *Options) { o.Size = MaxSize + 1 }},
+		{"level", func(o *Options) { o.Level = "X" }},
+		{"margin", func(o *Options) { o.Margin = -1 }},
+	}
+
+	assert.NoError(t, DefaultOptions().Validate())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			tt.modify(&opts)
+			assert.Error(t, opts.Validate())
+
+			_, err := Render(testContent, opts)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	for value, want := range map[string]color.NRGBA{
+		"#000":      {A: 0xff},
+		"fff":       {R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		"#1a2b3c":   {R: 0x1a, G: 0x2b, B: 0x3c, A: 0xff},
+		"1A2B3C80":  {R: 0x1a, G: 0x2b, B: 0x3c, A: 0x80},
+		"#00000000": {},
+	} {
+		got, err := ParseColor(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, want, got, value)
+	}
+
+	for _, value := range []string{"", "#12", "#12345", "black", "#gggggg", "+12345"} {
+		_, err := ParseColor(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestLayout(t *testing.T) {
+	// 21 modules and a margin of 4 make 29; 256 / 29 = 8 pixels per module.
+	scale, offset, total := layout(21, 4, 256)
+	assert.Equal(t, 8, scale)
+	assert.Equal(t, (256-21*8)/2, offset)
+	assert.Equal(t, 256, total)
+
+	// Too small: one pixel per module, and the image grows to fit.
+	scale, offset, total = layout(57, 4, 32)
+	assert.Equal(t, 1, scale)
+	assert.Equal(t, 4, offset)
+	assert.Equal(t, 65, total)
+}
@@ -0,0 +1,134 @@
+// Package ratelimit meters requests with token buckets. Each client key owns a
+// bucket holding up to Burst tokens that refills at Rate tokens per second; a
+// request spends its cost in tokens, usually one, and is refused when the
+// bucket holds fewer.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is the allowance of one client. A zero Rate disables the limit.
+type Limit struct {
+	Rate  float64 // tokens added per second
+	Burst int     // bucket size, the most tokens spent at once
+}
+
+// PerMinute allows requests per minute on average and bursts of burst
+// requests. A burst below one is raised to one.
+func PerMinute(requests, burst int) Limit {
+	if requests <= 0 {
+		return Limit{}
+	}
+
+	return Limit{Rate: float64(requests) / 60, Burst: max(burst, 1)}
+}
+
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// Result describes the bucket after a request.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until the refused request would be allowed;
+	// zero when it was.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// Store keeps buckets and spends their tokens. A cost above the burst is
+// never allowed.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit, cost int) (Result, error)
+}
+
+// take refills a bucket holding tokens, last updated elapsed ago, and spends
+// cost tokens from it when they are available.
+func take(tokens float64, elapsed time.Duration, limit Limit, cost int) (float64, Result) {
+	burst := float64(limit.Burst)
+	tokens = math.Min(burst, tokens+elapsed.Seconds()*limit.Rate)
+
+	result := Result{Limit: limit.Burst}
+	if tokens >= float64(cost) {
+		tokens -= float64(cost)
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((float64(cost) - tokens) / limit.Rate)
+	}
+
+	result.Remaining = int(tokens)
+	result.Reset = secondsToDuration((burst - tokens) / limit.Rate)
+
+	return tokens, result
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
+
+// MemoryStore keeps buckets in process memory, so each server instance
+// enforces its own limits.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	fullAt    time.Time
+}
+
+// sweepInterval is how often buckets that have refilled are dropped; a full
+// bucket is the same as no bucket.
+const sweepInterval = time.Minute
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit, cost int) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updatedAt: now}
+		s.buckets[key] = b
+	}
+
+	tokens, result := take(b.tokens, now.Sub(b.updatedAt), limit, cost)
+	b.tokens = tokens
+	b.updatedAt = now
+	b.fullAt = now.Add(result.Reset)
+
+	return result, nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if !now.Before(b.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+
+	s.lastSweep = now
+}
@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Two requests per second, three at once.
+var testLimit = Limit{Rate: 2, Burst: 3}
+
+func TestPerMinute(t *testing.T) {
+	assert.Equal(t, Limit{Rate: 1, Burst: 10}, PerMinute(60, 10))
+	assert.Equal(t, Limit{Rate: 0.5, Burst: 1}, PerMinute(30, 0))
+	assert.False(t, PerMinute(0, 10).Enabled())
+}
+
+// exerciseStore runs the same sequence against any store; advance moves the
+// store's clock forward.
+func exerciseStore(t *testing.T, store Store, advance func(time.Duration)) {
+	t.Helper()
+	ctx := context.Background()
+
+	for remaining := 2; remaining >= 0; remaining-- {
+		result, err := store.Take(ctx, "alice", testLimit, 1)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3, result.Limit)
+		assert.Equal(t, remaining, result.Remaining)
+		assert.Zero(t, result.RetryAfter)
+	}
+
+	result, err := store.Take(ctx, "alice", testLimit, 1)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.InDelta(t, 500*time.Millisecond, result.RetryAfter, float64(10*time.Millisecond))
+	assert.InDelta(t, 1500*time.Millisecond, result.Reset, float64(10*time.Millisecond))
+
+	// Other clients have their own bucket.
+	result, err = store.Take(ctx, "bob", testLimit, 1)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	advance(500 * time.Millisecond)
+	result, err = store.Take(ctx, "alice", testLimit, 1)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// A long pause refills the bucket, but only up to the burst.
+	advance(time.Hour)
+	result, err = store.Take(ctx, "alice", testLimit, 1)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+
+	// A request may cost several tokens, and is refused whole when the
+	// bucket holds fewer.
+	result, err = store.Take(ctx, "alice", testLimit, 3)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+	assert.InDelta(t, 500*time.Millisecond, result.RetryAfter, float64(10*time.Millisecond))
+
+	result, err = store.Take(ctx, "alice", testLimit, 2)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	exerciseStore(t, store, func(d time.Duration) { now = now.Add(d) })
+}
+
+func TestMemoryStore_SweepsFullBuckets(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	_, err := store.Take(context.Background(), "alice", testLimit, 1)
+	require.NoError(t, err)
+	assert.Len(t, store.buckets, 1)
+
+	now = now.Add(sweepInterval)
+	_, err = store.Take(context.Background(), "bob", testLimit, 1)
+	require.NoError(t, err)
+	assert.Len(t, store.buckets, 1)
+	assert.Contains(t, store.buckets, "bob")
+}
+
+func TestRedisStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	now := time.Now()
+	server.SetTime(now)
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	store := NewRedisStore(client, "ratelimit:")
+	exerciseStore(t, store, func(d time.Duration) {
+		now = now.Add(d)
+		server.SetTime(now)
+	})
+
+	assert.True(t, server.Exists("ratelimit:alice"))
+	assert.Positive(t, server.TTL("ratelimit:alice"))
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript is take run inside Redis, so every server instance spends from
+// the same bucket. Time comes from the Redis server to keep instances with
+// skewed clocks consistent. Token counts cross the script boundary in
+// thousandths, as Redis truncates Lua numbers to integers.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+
+local clock = redis.call("TIME")
+local now = tonumber(clock[1]) + tonumber(clock[2]) / 1000000
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated_at")
+local tokens = tonumber(state[1])
+local updated_at = tonumber(state[2])
+if tokens == nil or updated_at == nil then
+	tokens = burst
+	updated_at = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - updated_at) * rate)
+
+local allowed = 0
+if tokens >= cost then
+	tokens = tokens - cost
+	allowed = 1
+end
+
+local reset = (burst - tokens) / rate
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated_at", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil(reset * 1000) + 1000)
+
+return {allowed, math.floor(tokens * 1000)}
+`)
+
+// RedisStore keeps buckets in Redis, so that all server instances share
+// them. Keys are prefixed to keep them apart from other data.
+type RedisStore struct {
+	client redis.Scripter
+	prefix string
+}
+
+func NewRedisStore(client redis.Scripter, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit, cost int) (Result, error) {
+	values, err := takeScript.Run(ctx, s.client, []string{s.prefix + key}, limit.Rate, limit.Burst, cost).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit script result %v", values)
+	}
+
+	tokens := float64(values[1]) / 1000
+	result := Result{
+		Allowed:   values[0] == 1,
+		Limit:     limit.Burst,
+		Remaining: int(tokens),
+		Reset:     secondsToDuration((float64(limit.Burst) - tokens) / limit.Rate),
+	}
+	if !result.Allowed {
+		result.RetryAfter = secondsToDuration((float64(cost) - tokens) / limit.Rate)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+const (
+	// APIKeyScheme starts every key so leaked keys are easy to recognise.
+	APIKeyScheme = "usk"
+
+	apiKeyIDLength     = 8
+	apiKeySecretLength = 32
+	maxKeyAttempts     = 3
+)
+
+var errInvalidAPIKey = &Error{Kind: ErrUnauthorized, Message: "invalid API key"}
+
+// APIKeyService issues and checks API keys. A key reads
+// "usk_<id>_<secret>"; "usk_<id>" is its prefix, stored in clear to find the
+// key, and only the SHA-256 hash of the whole key is kept. The secret is
+// random enough that a slow password hash would add nothing.
+type APIKeyService struct {
+	ctx    context.Context
+	store  store.APIKeyStore
+	random *RandomCodeGenerator
+}
+
+func NewAPIKeyService(ctx context.Context, keyStore store.APIKeyStore) *APIKeyService {
+	return &APIKeyService{
+		ctx:    ctx,
+		store:  keyStore,
+		random: &RandomCodeGenerator{alphabet: base62Alphabet},
+	}
+}
+
+// CreateKey issues a key acting as owner in workspace. The plain key is only
+// returned here; it cannot be recovered later.
+func (service *APIKeyService) CreateKey(name, owner, workspace string) (*models.APIKey, string, error) {
+	owner = strings.TrimSpace(owner)
+	if owner == "" {
+		return nil, "", newError(ErrInvalid, "owner is required")
+	}
+
+	secret, err := service.random.Generate(service.ctx, apiKeySecretLength)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for attempt := 0; attempt < maxKeyAttempts; attempt++ {
+		id, err := service.random.Generate(service.ctx, apiKeyIDLength)
+		if err != nil {
+			return nil, "", err
+		}
+
+		prefix := APIKeyScheme + "_" + id
+		rawKey := prefix + "_" + secret
+
+		key := &models.APIKey{
+			Name:      strings.TrimSpace(name),
+			Owner:     owner,
+			Workspace: models.WorkspaceOrDefault(workspace),
+			Prefix:    prefix,
+			Hash:      hashAPIKey(rawKey),
+			CreatedAt: time.Now(),
+		}
+
+		err = service.store.InsertAPIKey(service.ctx, key)
+		if err == nil {
+			return key, rawKey, nil
+		} else if !errors.Is(err, store.ErrDuplicateKey) {
+			return nil, "", unavailable(err)
+		}
+	}
+
+	return nil, "", newError(ErrUnavailable, "failed to generate a unique API key")
+}
+
+// Authenticate returns the active key matching rawKey.
+func (service *APIKeyService) Authenticate(rawKey string) (*models.APIKey, error) {
+	prefix, ok := apiKeyPrefix(rawKey)
+	if !ok {
+		return nil, errInvalidAPIKey
+	}
+
+	key, err := service.store.GetAPIKey(service.ctx, prefix)
+	if err != nil {
+		if errors.Is(err, store.ErrKeyNotFound) {
+			return nil, errInvalidAPIKey
+		}
+
+		return nil, unavailable(err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(rawKey)), []byte(key.Hash)) != 1 {
+		return nil, errInvalidAPIKey
+	}
+
+	if key.IsRevoked() {
+		return nil, newError(ErrUnauthorized, "API key has been revoked")
+	}
+
+	return key, nil
+}
+
+// ListKeys returns the keys of owner, or every key when owner is empty.
+func (service *APIKeyService) ListKeys(owner string) ([]models.APIKey, error) {
+	keys, err := service.store.ListAPIKeys(service.ctx, owner)
+	if err != nil {
+		return nil, unavailable(err)
+	}
+
+	return keys, nil
+}
+
+// RevokeKey disables the key with the given prefix. Revoking a key twice keeps
+// the first revocation time.
+func (service *APIKeyService) RevokeKey(prefix string) error {
+	if err := service.store.RevokeAPIKey(service.ctx, prefix, time.Now()); err != nil {
+		if errors.Is(err, store.ErrKeyNotFound) {
+			return newError(ErrNotFound, "API key %q not found", prefix)
+		}
+
+		return unavailable(err)
+	}
+
+	return nil
+}
+
+// apiKeyPrefix splits the prefix off a key, rejecting anything that does not
+// have the key layout.
+func apiKeyPrefix(rawKey string) (string, bool) {
+	parts := strings.Split(rawKey, "_")
+	if len(parts) != 3 || parts[0] != APIKeyScheme || len(parts[1]) != apiKeyIDLength || len(parts[2]) != apiKeySecretLength {
+		return "", false
+	}
+
+	return parts[0] + "_" + parts[1], true
+}
+
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+func TestAPIKeyService_CreateAndAuthenticate(t *testing.T) {
+	keyStore := store.NewMemoryAPIKeyStore()
+	service := NewAPIKeyService(context.Background(), keyStore)
+
+	key, rawKey, err := service.CreateKey("ci", "alice", "")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(rawKey, key.Prefix+"_"))
+	assert.Equal(t, "alice", key.Owner)
+
+	stored, err := keyStore.GetAPIKey(context.Background(), key.Prefix)
+	require.NoError(t, err)
+	assert.NotContains(t, stored.Hash, rawKey[len(key.Prefix)+1:])
+
+	authenticated, err := service.Authenticate(rawKey)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, authenticated.ID)
+}
+
+func TestAPIKeyService_RejectsInvalidKeys(t *testing.T) {
+	service := NewAPIKeyService(context.Background(), store.NewMemoryAPIKeyStore())
+
+	key, rawKey, err := service.CreateKey("", "alice", "")
+	require.NoError(t, err)
+
+	tampered := rawKey[:len(rawKey)-1] + "x"
+	if tampered == rawKey {
+		tampered = rawKey[:len(rawKey)-1] + "y"
+	}
+
+	for _, candidate := range []string{"", "nonsense", key.Prefix, tampered, "usk_AAAAAAAA_" + strings.Repeat("a", 32)} {
+		_, err := service.Authenticate(candidate)
+		assert.ErrorIs(t, err, ErrUnauthorized, candidate)
+	}
+}
+
+func TestAPIKeyService_Revoke(t *testing.T) {
+	service := NewAPIKeyService(context.Background(), store.NewMemoryAPIKeyStore())
+
+	key, rawKey, err := service.CreateKey("", "alice", "")
+	require.NoError(t, err)
+
+	require.NoError(t, service.RevokeKey(key.Prefix))
+	require.NoError(t, service.RevokeKey(key.Prefix))
+
+	_, err = service.Authenticate(rawKey)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	assert.ErrorIs(t, service.RevokeKey("usk_missing"), ErrNotFound)
+}
+
+func TestAPIKeyService_RequiresOwner(t *testing.T) {
+	service := NewAPIKeyService(context.Background(), store.NewMemoryAPIKeyStore())
+
+	_, _, err := service.CreateKey("ci", " ", "")
+	assert.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestAPIKeyService_ListKeys(t *testing.T) {
+	service := NewAPIKeyService(context.Background(), store.NewMemoryAPIKeyStore())
+
+	_, _, err := service.CreateKey("", "alice", "")
+	require.NoError(t, err)
+	_, _, err = service.CreateKey("", "bob", "")
+	require.NoError(t, err)
+
+	keys, err := service.ListKeys("alice")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "alice", keys[0].Owner)
+
+	keys, err = service.ListKeys("")
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+}
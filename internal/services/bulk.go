@@ -0,0 +1,151 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+)
+
+// ShortenURLs shortens entries in the workspace of access, which needs the
+// editor role, following the rules of ShortenURL for each of them. Entries
+// fail one by one: the result of each tells whether it was created, reused
+// or rejected. Only problems with the request as a whole, such as too many
+// entries or a failed bulk insert, are returned as the error.
+func (service *URLService) ShortenURLs(access models.Access, entries []models.BulkEntry) ([]models.BulkResult, error) {
+	if err := authorize(access, models.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, newError(ErrInvalid, "no links to shorten")
+	}
+	if limit := service.config.MaxBulkLinks; limit > 0 && len(entries) > limit {
+		return nil, newError(ErrInvalid, "a bulk request may hold at most %d links", limit)
+	}
+
+	now := time.Now()
+
+	budget, err := service.quotaBudget(access.User, access.Workspace, now)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.BulkResult, len(entries))
+	var (
+		pending []int         // entries to insert, by index
+		urls    []*models.URL // the links of pending
+	)
+	// Plain entries repeating an earlier one of the batch share its outcome,
+	// as they would when sent one at a time.
+	firstOf := make(map[string]int)
+	repeats := make(map[int]int)
+
+	for i, entry := range entries {
+		opts := entry.Options
+
+		if err := service.validateCustomCode(opts.CustomCode); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		expiresAt, err := service.resolveExpiry(now, opts)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		redirectType, err := service.resolveRedirectType(opts.RedirectType)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		if !hasExpiryOverride(opts) {
+			key := fmt.Sprintf("%d %s", redirectType, entry.OriginalURL)
+			if first, ok := firstOf[key]; ok {
+				repeats[i] = first
+				continue
+			}
+			firstOf[key] = i
+
+			existingURL, err := service.reusableLink(entry.OriginalURL, access.Workspace, redirectType, now)
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+			if existingURL != nil {
+				results[i] = models.BulkResult{URL: existingURL, Existing: true}
+				continue
+			}
+		}
+
+		check := quotaCheck{custom: opts.CustomCode != "", created: true}
+		if err := budget.reserve(check); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		url := &models.URL{
+			OriginalURL:  entry.OriginalURL,
+			ShortCode:    opts.CustomCode,
+			CustomCode:   opts.CustomCode != "",
+			ExpiresAt:    expiresAt,
+			RedirectType: redirectType,
+			CreatedBy:    access.User,
+			Workspace:    access.Workspace,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+
+		if !url.CustomCode {
+			if url.ShortCode, err = service.generator.Generate(service.ctx, service.config.CodeLength); err != nil {
+				budget.release(check)
+				results[i].Err = unavailable(err)
+				continue
+			}
+		}
+
+		pending = append(pending, i)
+		urls = append(urls, url)
+	}
+
+	duplicates, err := service.store.InsertMany(service.ctx, urls)
+	if err != nil {
+		return nil, unavailable(err)
+	}
+
+	duplicate := make(map[int]bool, len(duplicates))
+	for _, j := range duplicates {
+		duplicate[j] = true
+	}
+
+	for j, i := range pending {
+		url := urls[j]
+
+		if duplicate[j] {
+			// A taken custom code fails its entry; a generated one that
+			// collided is retried alone with fresh codes.
+			if url.CustomCode {
+				results[i].Err = ErrCodeTaken
+				continue
+			}
+
+			if err := service.insertWithGeneratedCode(url); err != nil {
+				results[i].Err = unavailable(err)
+				continue
+			}
+		}
+
+		results[i].URL = url
+	}
+
+	for i, first := range repeats {
+		results[i] = results[first]
+		if results[i].URL != nil {
+			results[i].Existing = true
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yan-cerqueira-unvoid/url-shortener/config"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+func TestShortenURLs_PartialSuccess(t *testing.T) {
+	urlStore := store.NewMemoryURLStore()
+	service := NewURLService(context.Background(), urlStore, testConfig, testGenerator)
+
+	existing, err := service.ShortenURL(testAccess, "https://example.com/existing", models.ShortenOptions{})
+	require.NoError(t, err)
+	_, err = service.ShortenURL(testAccess, "https://example.com/taken", models.ShortenOptions{CustomCode: "taken"})
+	require.NoError(t, err)
+
+	results, err := service.ShortenURLs(testAccess, []models.BulkEntry{
+		{OriginalURL: "https://example.com/1"},
+		{OriginalURL: "https://example.com/existing"},
+		{OriginalURL: "https://example.com/2", Options: models.ShortenOptions{CustomCode: "taken"}},
+		{OriginalURL: "https://example.com/3", Options: models.ShortenOptions{RedirectType: 999}},
+		{OriginalURL: "https://example.com/4", Options: models.ShortenOptions{CustomCode: "four"}},
+		{OriginalURL: "https://example.com/1"},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 6)
+
+	require.NoError(t, results[0].Err)
+	assert.False(t, results[0].Existing)
+	stored, err := urlStore.GetByCode(context.Background(), results[0].URL.ShortCode)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/1", stored.OriginalURL)
+	assert.Equal(t, testAccess.User, stored.CreatedBy)
+
+	require.NoError(t, results[1].Err)
+	assert.True(t, results[1].Existing)
+	assert.Equal(t, existing.ShortCode, results[1].URL.ShortCode)
+
+	assert.ErrorIs(t, results[2].Err, ErrCodeTaken)
+	assert.ErrorIs(t, results[3].Err, ErrInvalid)
+
+	require.NoError(t, results[4].Err)
+	assert.Equal(t, "four", results[4].URL.ShortCode)
+	assert.True(t, results[4].URL.CustomCode)
+
+	// A repeat of an earlier entry reuses the link created for it.
+	require.NoError(t, results[5].Err)
+	assert.True(t, results[5].Existing)
+	assert.Equal(t, results[0].URL.ShortCode, results[5].URL.ShortCode)
+}
+
+func TestShortenURLs_DuplicateCustomCodesInBatch(t *testing.T) {
+	service := NewURLService(context.Background(), store.NewMemoryURLStore(), testConfig, testGenerator)
+
+	results, err := service.ShortenURLs(testAccess, []models.BulkEntry{
+		{OriginalURL: "https://example.com/1", Options: models.ShortenOptions{CustomCode: "same"}},
+		{OriginalURL: "https://example.com/2", Options: models.ShortenOptions{CustomCode: "same"}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, ErrCodeTaken)
+}
+
+func TestShortenURLs_InvalidCustomCode(t *testing.T) {
+	service := NewURLService(context.Background(), store.NewMemoryURLStore(), testConfig, testGenerator)
+
+	results, err := service.ShortenURLs(testAccess, []models.BulkEntry{
+		{OriginalURL: "https://example.com/1", Options: models.ShortenOptions{CustomCode: "a/b"}},
+		{OriginalURL: "https://example.com/2", Options: models.ShortenOptions{CustomCode: "api"}},
+		{OriginalURL: "https://example.com/3", Options: models.ShortenOptions{CustomCode: "valid"}},
+	})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, results[0].Err, ErrInvalid)
+	assert.ErrorIs(t, results[1].Err, ErrInvalid)
+	require.NoError(t, results[2].Err)
+	assert.Equal(t, "valid", results[2].URL.ShortCode)
+}
+
+func TestShortenURLs_Quotas(t *testing.T) {
+	service, _ := newQuotaService(t, config.QuotaConfig{User: config.QuotaLimits{ActiveLinks: 2}})
+
+	results, err := service.ShortenURLs(testAccess, []models.BulkEntry{
+		{OriginalURL: "https://example.com/1"},
+		{OriginalURL: "https://example.com/2"},
+		{OriginalURL: "https://example.com/3"},
+		{OriginalURL: "https://example.com/1"},
+	})
+	require.NoError(t, err)
+
+	assert.NoError(t, results[0].Err)
+	assert.NoError(t, results[1].Err)
+	assert.ErrorIs(t, results[2].Err, ErrQuotaExceeded)
+	assert.NoError(t, results[3].Err)
+}
+
+func TestShortenURLs_RejectsRequest(t *testing.T) {
+	cfg := testConfig
+	cfg.MaxBulkLinks = 2
+	service := NewURLService(context.Background(), store.NewMemoryURLStore(), cfg, testGenerator)
+	entries := []models.BulkEntry{
+		{OriginalURL: "https://example.com/1"},
+		{OriginalURL: "https://example.com/2"},
+		{OriginalURL: "https://example.com/3"},
+	}
+
+	_, err := service.ShortenURLs(testAccess, entries)
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	_, err = service.ShortenURLs(testAccess, nil)
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	viewer := models.Access{User: "carol", Workspace: models.DefaultWorkspace, Role: models.RoleViewer}
+	_, err = service.ShortenURLs(viewer, entries[:1])
+	assert.ErrorIs(t, err, ErrForbidden)
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/sqids/sqids-go"
+	"github.com/yan-cerqueira-unvoid/url-shortener/config"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+const (
+	StrategyRandom  = "random"
+	StrategyCounter = "counter"
+	StrategySqids   = "sqids"
+
+	base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	sqidsAlphabet  = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	codeSequenceName = "short_codes"
+)
+
+// CodeGenerator produces candidate short codes. length is the minimum length
+// the service wants; it grows after repeated collisions.
+type CodeGenerator interface {
+	Generate(ctx context.Context, length int) (string, error)
+}
+
+// NewCodeGenerator builds the generator selected by cfg.CodeStrategy. The
+// counter and sqids strategies draw numbers from the store's sequence.
+func NewCodeGenerator(cfg config.URLShortenerConfig, sequencer store.Sequencer) (CodeGenerator, error) {
+	if cfg.CodeAlphabet != "" {
+		if err := validateAlphabet(cfg.CodeAlphabet); err != nil {
+			return nil, err
+		}
+	}
+
+	switch cfg.CodeStrategy {
+	case StrategyRandom, "":
+		return &RandomCodeGenerator{alphabet: alphabetOrDefault(cfg.CodeAlphabet)}, nil
+	case StrategyCounter:
+		return &CounterCodeGenerator{sequencer: sequencer, alphabet: alphabetOrDefault(cfg.CodeAlphabet)}, nil
+	case StrategySqids:
+		return NewSqidsCodeGenerator(sequencer, cfg.CodeAlphabet, cfg.CodeLength)
+	default:
+		return nil, fmt.Errorf("unknown short code strategy %q", cfg.CodeStrategy)
+	}
+}
+
+func alphabetOrDefault(alphabet string) string {
+	if alphabet == "" {
+		return base62Alphabet
+	}
+
+	return alphabet
+}
+
+func validateAlphabet(alphabet string) error {
+	if len(alphabet) < 2 {
+		return fmt.Errorf("code alphabet must have at least 2 characters")
+	}
+
+	seen := make(map[rune]bool, len(alphabet))
+	for _, r := range alphabet {
+		if r > 127 {
+			return fmt.Errorf("code alphabet must only contain ASCII characters")
+		}
+		if seen[r] {
+			return fmt.Errorf("code alphabet must not repeat %q", r)
+		}
+		seen[r] = true
+	}
+
+	return nil
+}
+
+// RandomCodeGenerator picks each character uniformly from the alphabet using
+// crypto/rand.
+type RandomCodeGenerator struct {
+	alphabet string
+}
+
+func (g *RandomCodeGenerator) Generate(ctx context.Context, length int) (string, error) {
+	size := big.NewInt(int64(len(g.alphabet)))
+	code := make([]byte, length)
+
+	for i := range code {
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", err
+		}
+		code[i] = g.alphabet[n.Int64()]
+	}
+
+	return string(code), nil
+}
+
+// CounterCodeGenerator encodes a monotonically increasing sequence number,
+// left-padded to the requested length.
+type CounterCodeGenerator struct {
+	sequencer store.Sequencer
+	alphabet  string
+}
+
+func (g *CounterCodeGenerator) Generate(ctx context.Context, length int) (string, error) {
+	n, err := g.sequencer.NextSequence(ctx, codeSequenceName)
+	if err != nil {
+		return "", err
+	}
+
+	code := encodeBase(uint64(n), g.alphabet)
+	if len(code) < length {
+		code = strings.Repeat(g.alphabet[:1], length-len(code)) + code
+	}
+
+	return code, nil
+}
+
+// SqidsCodeGenerator obfuscates sequence numbers with Sqids, so codes are
+// unique without looking sequential. Anyone knowing the alphabet can decode a
+// code back to its sequence number and enumerate the links, so it needs a
+// shuffled alphabet of its own.
+type SqidsCodeGenerator struct {
+	sequencer store.Sequencer
+	sqids     *sqids.Sqids
+}
+
+func NewSqidsCodeGenerator(sequencer store.Sequencer, alphabet string, minLength int) (*SqidsCodeGenerator, error) {
+	switch alphabet {
+	case "":
+		return nil, fmt.Errorf("the sqids strategy needs a shuffled code alphabet")
+	case base62Alphabet, sqidsAlphabet:
+		return nil, fmt.Errorf("the sqids strategy needs a shuffled code alphabet, not the default one")
+	}
+	if err := validateAlphabet(alphabet); err != nil {
+		return nil, err
+	}
+
+	s, err := sqids.New(sqids.Options{
+		Alphabet:  alphabet,
+		MinLength: uint8(min(max(minLength, 0), 255)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SqidsCodeGenerator{sequencer: sequencer, sqids: s}, nil
+}
+
+func (g *SqidsCodeGenerator) Generate(ctx context.Context, length int) (string, error) {
+	n, err := g.sequencer.NextSequence(ctx, codeSequenceName)
+	if err != nil {
+		return "", err
+	}
+
+	return g.sqids.Encode([]uint64{uint64(n)})
+}
+
+func encodeBase(n uint64, alphabet string) string {
+	base := uint64(len(alphabet))
+	if n == 0 {
+		return alphabet[:1]
+	}
+
+	var code []byte
+	for n > 0 {
+		code = append(code, alphabet[n%base])
+		n /= base
+	}
+
+	for i, j := 0, len(code)-1; i < j; i, j = i+1, j-1 {
+		code[i], code[j] = code[j], code[i]
+	}
+
+	return string(code)
+}
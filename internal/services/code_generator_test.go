@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yan-cerqueira-unvoid/url-shortener/config"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+const testSqidsAlphabet = "kJ3xQ7bWn0aZ5mYcR1tV9uH2sLd8fG4pE6"
+
+func TestRandomCodeGenerator(t *testing.T) {
+	generator, err := NewCodeGenerator(config.URLShortenerConfig{CodeStrategy: StrategyRandom}, nil)
+	require.NoError(t, err)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		code, err := generator.Generate(context.Background(), 8)
+		require.NoError(t, err)
+		assert.Len(t, code, 8)
+
+		for _, r := range code {
+			assert.True(t, strings.ContainsRune(base62Alphabet, r), "unexpected character %q", r)
+		}
+		seen[code] = true
+	}
+
+	assert.Len(t, seen, 100)
+}
+
+func TestCounterCodeGenerator(t *testing.T) {
+	generator, err := NewCodeGenerator(config.URLShortenerConfig{CodeStrategy: StrategyCounter}, store.NewMemoryURLStore())
+	require.NoError(t, err)
+
+	first, err := generator.Generate(context.Background(), 4)
+	require.NoError(t, err)
+	second, err := generator.Generate(context.Background(), 4)
+	require.NoError(t, err)
+
+	assert.Equal(t, "0001", first)
+	assert.Equal(t, "0002", second)
+}
+
+func TestSqidsCodeGenerator(t *testing.T) {
+	sequencer := store.NewMemoryURLStore()
+	generator, err := NewCodeGenerator(config.URLShortenerConfig{CodeStrategy: StrategySqids, CodeAlphabet: testSqidsAlphabet, CodeLength: 6}, sequencer)
+	require.NoError(t, err)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		code, err := generator.Generate(context.Background(), 6)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, len(code), 6)
+		assert.False(t, seen[code], "duplicate code %s", code)
+		seen[code] = true
+	}
+}
+
+func TestSqidsCodeGenerator_AlphabetChangesCodes(t *testing.T) {
+	first, err := NewSqidsCodeGenerator(store.NewMemoryURLStore(), testSqidsAlphabet, 6)
+	require.NoError(t, err)
+	second, err := NewSqidsCodeGenerator(store.NewMemoryURLStore(), "Zp4Kq8wN2xB6vM0cL5jT9hR1gF3dS7aY", 6)
+	require.NoError(t, err)
+
+	a, _ := first.Generate(context.Background(), 6)
+	b, _ := second.Generate(context.Background(), 6)
+
+	assert.NotEqual(t, a, b)
+}
+
+func TestSqidsCodeGenerator_ClampsMinLength(t *testing.T) {
+	// A negative length must not wrap around to a long minimum.
+	generator, err := NewSqidsCodeGenerator(store.NewMemoryURLStore(), testSqidsAlphabet, -1)
+	require.NoError(t, err)
+
+	code, err := generator.Generate(context.Background(), 6)
+	require.NoError(t, err)
+	assert.Less(t, len(code), 6)
+
+	generator, err = NewSqidsCodeGenerator(store.NewMemoryURLStore(), testSqidsAlphabet, 1000)
+	require.NoError(t, err)
+
+	code, err = generator.Generate(context.Background(), 6)
+	require.NoError(t, err)
+	assert.Len(t, code, 255)
+}
+
+func TestNewCodeGenerator_InvalidConfig(t *testing.T) {
+	_, err := NewCodeGenerator(config.URLShortenerConfig{CodeStrategy: "nope"}, nil)
+	assert.NotNil(t, err)
+
+	_, err = NewCodeGenerator(config.URLShortenerConfig{CodeAlphabet: "aa"}, nil)
+	assert.NotNil(t, err)
+
+	for _, alphabet := range []string{"", base62Alphabet, sqidsAlphabet, "kJ3xQ7kJ", "ab"} {
+		_, err = NewCodeGenerator(config.URLShortenerConfig{CodeStrategy: StrategySqids, CodeAlphabet: alphabet}, store.NewMemoryURLStore())
+		assert.NotNil(t, err, alphabet)
+	}
+}
+
+func TestEncodeBase(t *testing.T) {
+	assert.Equal(t, "0", encodeBase(0, base62Alphabet))
+	assert.Equal(t, "z", encodeBase(61, base62Alphabet))
+	assert.Equal(t, "10", encodeBase(62, base62Alphabet))
+}
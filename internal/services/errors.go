@@ -0,0 +1,60 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error kinds returned by the services. Callers match them with errors.Is; the
+// handlers map each kind to an HTTP status.
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrExpired       = errors.New("expired")
+	ErrConflict      = errors.New("conflict")
+	ErrInvalid       = errors.New("invalid")
+	ErrUnavailable   = errors.New("unavailable")
+	ErrUnauthorized  = errors.New("unauthorized")
+	ErrForbidden     = errors.New("forbidden")
+	ErrQuotaExceeded = errors.New("quota exceeded")
+)
+
+var ErrCodeTaken = &Error{Kind: ErrConflict, Message: "custom short code already in use"}
+
+// Error is a service error with a client-safe message. Kind is one of the
+// sentinel errors above and Err, when set, is the underlying cause.
+type Error struct {
+	Kind    error
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+
+	return e.Message
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+
+	return []error{e.Kind}
+}
+
+func newError(kind error, format string, args ...any) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+// unavailable wraps an unexpected storage failure so it is reported as a
+// temporary outage rather than a client error.
+func unavailable(err error) error {
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return err
+	}
+
+	return &Error{Kind: ErrUnavailable, Message: "storage unavailable", Err: err}
+}
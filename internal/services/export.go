@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/export"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+// ExportService streams the links and clicks of a workspace for loading into
+// other systems.
+type ExportService struct {
+	ctx    context.Context
+	urls   store.URLStore
+	clicks store.ClickStore
+}
+
+func NewExportService(ctx context.Context, urlStore store.URLStore, clickStore store.ClickStore) *ExportService {
+	return &ExportService{
+		ctx:    ctx,
+		urls:   urlStore,
+		clicks: clickStore,
+	}
+}
+
+// ExportLinks writes the links of the workspace of access, which needs the
+// viewer role, to w. filter.Workspace is ignored.
+func (service *ExportService) ExportLinks(access models.Access, filter export.Filter, w export.Writer) (int64, error) {
+	if err := service.check(access, filter); err != nil {
+		return 0, err
+	}
+
+	filter.Workspace = access.Workspace
+	written, err := export.Links(service.ctx, service.urls, filter, w)
+	if err != nil {
+		return written, unavailable(err)
+	}
+
+	return written, nil
+}
+
+// ExportClicks writes the clicks on links of the workspace of access, which
+// needs the viewer role, to w. filter.Workspace is ignored.
+func (service *ExportService) ExportClicks(access models.Access, filter export.Filter, w export.Writer) (int64, error) {
+	if err := service.check(access, filter); err != nil {
+		return 0, err
+	}
+
+	filter.Workspace = access.Workspace
+	written, err := export.Clicks(service.ctx, service.clicks, filter, w)
+	if err != nil {
+		return written, unavailable(err)
+	}
+
+	return written, nil
+}
+
+func (service *ExportService) check(access models.Access, filter export.Filter) error {
+	if err := authorize(access, models.RoleViewer); err != nil {
+		return err
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return newError(ErrInvalid, "from must be before to")
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/export"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+func TestExportService_ScopesToWorkspace(t *testing.T) {
+	ctx := context.Background()
+	urls := store.NewMemoryURLStore()
+	clicks := store.NewMemoryClickStore(urls)
+	service := NewExportService(ctx, urls, clicks)
+	now := time.Now()
+
+	require.NoError(t, urls.Insert(ctx, &models.URL{OriginalURL: "https://example.com/1", ShortCode: "mine", CreatedAt: now}))
+	require.NoError(t, urls.Insert(ctx, &models.URL{OriginalURL: "https://example.com/2", ShortCode: "theirs", Workspace: "sales", CreatedAt: now}))
+	require.NoError(t, clicks.InsertClicks(ctx, []models.Click{
+		{ShortCode: "mine", Timestamp: now},
+		{ShortCode: "theirs", Timestamp: now},
+	}))
+
+	var buf bytes.Buffer
+	w, err := export.NewWriter(&buf, export.FormatJSONL, export.LinkColumns)
+	require.NoError(t, err)
+
+	// The filter cannot reach into another workspace.
+	written, err := service.ExportLinks(testAccess, export.Filter{Workspace: "sales"}, w)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.EqualValues(t, 1, written)
+	assert.Contains(t, buf.String(), `"short_code":"mine"`)
+
+	written, err = service.ExportClicks(testAccess, export.Filter{}, nopWriter{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, written)
+}
+
+func TestExportService_Rejects(t *testing.T) {
+	service := NewExportService(context.Background(), store.NewMemoryURLStore(), store.NewMemoryClickStore(nil))
+	now := time.Now()
+
+	_, err := service.ExportLinks(models.Access{}, export.Filter{}, nopWriter{})
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = service.ExportClicks(testAccess, export.Filter{From: now, To: now.Add(-time.Hour)}, nopWriter{})
+	assert.ErrorIs(t, err, ErrInvalid)
+}
+
+type nopWriter struct{}
+
+func (nopWriter) Write(values []any) error { return nil }
+func (nopWriter) Close() error             { return nil }
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// GetLink returns the stored link, including deleted and expired ones,
+// without counting a click.
+func (service *URLService) GetLink(access models.Access, shortCode string) (*models.URL, error) {
+	if err := authorize(access, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return service.findLink(access, shortCode)
+}
+
+// findLink looks a link up in the workspace of access. Links of other
+// workspaces are reported as missing so their codes do not leak.
+func (service *URLService) findLink(access models.Access, shortCode string) (*models.URL, error) {
+	url, err := service.store.GetByCode(service.ctx, shortCode)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, newError(ErrNotFound, "link %q not found", shortCode)
+		}
+
+		return nil, unavailable(err)
+	}
+
+	if !inWorkspace(access, url) {
+		return nil, newError(ErrNotFound, "link %q not found", shortCode)
+	}
+
+	return url, nil
+}
+
+// ClampListOptions applies the default page size and caps it at MaxListLimit.
+func ClampListOptions(opts store.ListOptions) store.ListOptions {
+	if opts.Limit == 0 {
+		opts.Limit = DefaultListLimit
+	}
+	if opts.Limit > MaxListLimit {
+		opts.Limit = MaxListLimit
+	}
+
+	return opts
+}
+
+// ListLinks returns one page of the workspace links matching opts and the
+// total number of matches. opts.Workspace is replaced by the workspace of
+// access.
+func (service *URLService) ListLinks(access models.Access, opts store.ListOptions) ([]models.URL, int64, error) {
+	if err := authorize(access, models.RoleViewer); err != nil {
+		return nil, 0, err
+	}
+	opts.Workspace = access.Workspace
+
+	switch opts.Status {
+	case "", store.StatusActive, store.StatusExpired, store.StatusDeleted:
+	default:
+		return nil, 0, newError(ErrInvalid, "unknown status %q", opts.Status)
+	}
+
+	if opts.Limit < 0 || opts.Offset < 0 {
+		return nil, 0, newError(ErrInvalid, "limit and offset must not be negative")
+	}
+	opts = ClampListOptions(opts)
+
+	urls, err := service.store.List(service.ctx, opts)
+	if err != nil {
+		return nil, 0, unavailable(err)
+	}
+
+	total, err := service.store.Count(service.ctx, opts)
+	if err != nil {
+		return nil, 0, unavailable(err)
+	}
+
+	return urls, total, nil
+}
+
+// UpdateLink changes the destination and/or expiry of a link. Deleted links
+// must be restored before they can be edited.
+func (service *URLService) UpdateLink(access models.Access, shortCode string, update models.LinkUpdate) (*models.URL, error) {
+	if err := authorize(access, models.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	url, err := service.findLink(access, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if url.IsDeleted() {
+		return nil, newError(ErrConflict, "link %q is deleted, restore it before editing", shortCode)
+	}
+
+	now := time.Now()
+
+	if update.OriginalURL != "" {
+		url.OriginalURL = update.OriginalURL
+	}
+
+	if update.RedirectType != 0 {
+		url.RedirectType, err = service.resolveRedirectType(update.RedirectType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	expiryOpts := models.ShortenOptions{
+		ExpiresAt:    update.ExpiresAt,
+		TTL:          update.TTL,
+		NeverExpires: update.NeverExpires,
+	}
+	if hasExpiryOverride(expiryOpts) {
+		expiresAt, err := service.resolveExpiry(now, expiryOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		// Extending an expired link makes it active again, so it counts
+		// against the quotas of its creator and workspace, as on restore.
+		if url.IsExpired(now) && (expiresAt == nil || !now.After(*expiresAt)) {
+			check := quotaCheck{custom: url.CustomCode}
+			if err := service.checkQuotas(url.CreatedBy, models.WorkspaceOrDefault(url.Workspace), check, now); err != nil {
+				return nil, err
+			}
+		}
+
+		url.ExpiresAt = expiresAt
+	}
+
+	url.UpdatedAt = now
+
+	if err := service.saveLink(url); err != nil {
+		return nil, err
+	}
+
+	return url, nil
+}
+
+// DeleteLink soft-deletes a link: it stops redirecting but stays in storage
+// so it can be restored. Deleting an already deleted link is a no-op.
+func (service *URLService) DeleteLink(access models.Access, shortCode string) error {
+	if err := authorize(access, models.RoleEditor); err != nil {
+		return err
+	}
+
+	url, err := service.findLink(access, shortCode)
+	if err != nil {
+		return err
+	}
+
+	if url.IsDeleted() {
+		return nil
+	}
+
+	now := time.Now()
+	url.DeletedAt = &now
+	url.UpdatedAt = now
+
+	return service.saveLink(url)
+}
+
+func (service *URLService) RestoreLink(access models.Access, shortCode string) (*models.URL, error) {
+	if err := authorize(access, models.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	url, err := service.findLink(access, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if !url.IsDeleted() {
+		return url, nil
+	}
+
+	now := time.Now()
+
+	// A restored link is active again, so it counts against the quotas of
+	// its creator and workspace once more.
+	if !url.IsExpired(now) {
+		check := quotaCheck{custom: url.CustomCode}
+		if err := service.checkQuotas(url.CreatedBy, models.WorkspaceOrDefault(url.Workspace), check, now); err != nil {
+			return nil, err
+		}
+	}
+
+	url.DeletedAt = nil
+	url.UpdatedAt = now
+
+	if err := service.saveLink(url); err != nil {
+		return nil, err
+	}
+
+	return url, nil
+}
+
+func (service *URLService) saveLink(url *models.URL) error {
+	if err := service.store.Update(service.ctx, url); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return newError(ErrNotFound, "link %q not found", url.ShortCode)
+		}
+
+		return unavailable(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+func newMemoryService(t *testing.T) *URLService {
+	t.Helper()
+
+	return NewURLService(context.Background(), store.NewMemoryURLStore(), testConfig, testGenerator)
+}
+
+func TestGetLink_DoesNotCountClick(t *testing.T) {
+	service := newMemoryService(t)
+
+	created, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{})
+	require.NoError(t, err)
+
+	url, err := service.GetLink(testAccess, created.ShortCode)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), url.Clicks)
+
+	_, err = service.GetLink(testAccess, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestUpdateLink(t *testing.T) {
+	service := newMemoryService(t)
+
+	created, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{})
+	require.NoError(t, err)
+
+	updated, err := service.UpdateLink(testAccess, created.ShortCode, models.LinkUpdate{
+		OriginalURL:  "https://example.org",
+		NeverExpires: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.org", updated.OriginalURL)
+	assert.Nil(t, updated.ExpiresAt)
+
+	redirect, err := service.GetURL(created.ShortCode)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.org", redirect.OriginalURL)
+
+	_, err = service.UpdateLink(testAccess, created.ShortCode, models.LinkUpdate{TTL: -time.Hour})
+	assert.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestUpdateLink_KeepsExpiryWhenNotGiven(t *testing.T) {
+	service := newMemoryService(t)
+
+	created, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{TTL: time.Hour})
+	require.NoError(t, err)
+
+	updated, err := service.UpdateLink(testAccess, created.ShortCode, models.LinkUpdate{OriginalURL: "https://example.org"})
+	require.NoError(t, err)
+	assert.Equal(t, created.ExpiresAt, updated.ExpiresAt)
+}
+
+func TestDeleteAndRestoreLink(t *testing.T) {
+	service := newMemoryService(t)
+
+	created, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, service.DeleteLink(testAccess, created.ShortCode))
+	require.NoError(t, service.DeleteLink(testAccess, created.ShortCode))
+
+	_, err = service.GetURL(created.ShortCode)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = service.UpdateLink(testAccess, created.ShortCode, models.LinkUpdate{OriginalURL: "https://example.org"})
+	assert.ErrorIs(t, err, ErrConflict)
+
+	deleted, err := service.GetLink(testAccess, created.ShortCode)
+	require.NoError(t, err)
+	assert.NotNil(t, deleted.DeletedAt)
+
+	// A deleted link is not reused for the same destination.
+	recreated, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{})
+	require.NoError(t, err)
+	assert.NotEqual(t, created.ShortCode, recreated.ShortCode)
+
+	restored, err := service.RestoreLink(testAccess, created.ShortCode)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+
+	_, err = service.GetURL(created.ShortCode)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, service.DeleteLink(testAccess, "missing"), ErrNotFound)
+}
+
+func TestListLinks(t *testing.T) {
+	service := newMemoryService(t)
+
+	for _, u := range []string{"https://example.com/a", "https://example.com/b", "https://other.com/c"} {
+		_, err := service.ShortenURL(testAccess, u, models.ShortenOptions{})
+		require.NoError(t, err)
+	}
+
+	urls, total, err := service.ListLinks(testAccess, store.ListOptions{Search: "EXAMPLE.com", Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, urls, 1)
+	assert.Equal(t, int64(2), total)
+
+	_, _, err = service.ListLinks(testAccess, store.ListOptions{Status: "bogus"})
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	assert.Equal(t, int64(MaxListLimit), ClampListOptions(store.ListOptions{Limit: 1000}).Limit)
+	assert.Equal(t, int64(DefaultListLimit), ClampListOptions(store.ListOptions{}).Limit)
+}
+
+func TestLinks_ScopedToWorkspace(t *testing.T) {
+	service := newMemoryService(t)
+
+	created, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{})
+	require.NoError(t, err)
+
+	other := models.Access{User: "bob", Workspace: "sales", Role: models.RoleOwner}
+
+	_, err = service.GetLink(other, created.ShortCode)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, service.DeleteLink(other, created.ShortCode), ErrNotFound)
+
+	urls, total, err := service.ListLinks(other, store.ListOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, urls)
+	assert.Equal(t, int64(0), total)
+
+	viewer := models.Access{User: "carol", Workspace: models.DefaultWorkspace, Role: models.RoleViewer}
+
+	_, err = service.GetLink(viewer, created.ShortCode)
+	require.NoError(t, err)
+	assert.ErrorIs(t, service.DeleteLink(viewer, created.ShortCode), ErrForbidden)
+}
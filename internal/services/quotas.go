@@ -0,0 +1,204 @@
+package services
+
+import (
+	"time"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/config"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+// quotaCheck says which quotas a new or revived link counts against.
+type quotaCheck struct {
+	custom  bool // the link has a custom short code
+	created bool // the link is newly created this month
+}
+
+// Usage reports the quotas of the user and workspace of access, which needs
+// the viewer role.
+func (service *URLService) Usage(access models.Access) (*models.UsageReport, error) {
+	if err := authorize(access, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	quotas := service.config.Quotas
+	report := &models.UsageReport{
+		User:        access.User,
+		Workspace:   access.Workspace,
+		PeriodStart: monthStart(now),
+	}
+
+	var err error
+	if report.UserUsage, err = service.usage(store.ListOptions{CreatedBy: access.User}, quotas.User, now); err != nil {
+		return nil, err
+	}
+	if report.WorkspaceUsage, err = service.usage(store.ListOptions{Workspace: access.Workspace}, quotas.Workspace, now); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (service *URLService) usage(scope store.ListOptions, limits config.QuotaLimits, now time.Time) (models.Usage, error) {
+	usage := models.Usage{
+		ActiveLinks:  models.QuotaUsage{Limit: limits.ActiveLinks},
+		CustomCodes:  models.QuotaUsage{Limit: limits.CustomCodes},
+		MonthlyLinks: models.QuotaUsage{Limit: limits.MonthlyLinks},
+	}
+
+	var err error
+	if usage.ActiveLinks.Used, err = service.countActive(scope, false); err != nil {
+		return models.Usage{}, err
+	}
+	if usage.CustomCodes.Used, err = service.countActive(scope, true); err != nil {
+		return models.Usage{}, err
+	}
+	if usage.MonthlyLinks.Used, err = service.countCreatedSince(scope, monthStart(now)); err != nil {
+		return models.Usage{}, err
+	}
+
+	return usage, nil
+}
+
+// quotaBudget tracks the quota usage of a user and a workspace, counted once
+// so that a batch of links can be checked against it one by one.
+type quotaBudget []*quotaScope
+
+type quotaScope struct {
+	owner  string
+	limits config.QuotaLimits
+	used   models.Usage
+}
+
+// checkQuotas fails when one more link by user in workspace would go over a
+// quota. Concurrent requests may overshoot a quota by the few links created
+// while they are counted.
+func (service *URLService) checkQuotas(user, workspace string, check quotaCheck, now time.Time) error {
+	budget, err := service.quotaBudget(user, workspace, now)
+	if err != nil {
+		return err
+	}
+
+	return budget.reserve(check)
+}
+
+// quotaBudget counts the current usage of user and workspace. Only the
+// configured quotas are counted.
+func (service *URLService) quotaBudget(user, workspace string, now time.Time) (quotaBudget, error) {
+	quotas := service.config.Quotas
+
+	userScope, err := service.quotaScope(store.ListOptions{CreatedBy: user}, quotas.User, now, "user "+user)
+	if err != nil {
+		return nil, err
+	}
+	workspaceScope, err := service.quotaScope(store.ListOptions{Workspace: workspace}, quotas.Workspace, now, "workspace "+workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	return quotaBudget{userScope, workspaceScope}, nil
+}
+
+func (service *URLService) quotaScope(scope store.ListOptions, limits config.QuotaLimits, now time.Time, owner string) (*quotaScope, error) {
+	quota := &quotaScope{owner: owner, limits: limits}
+
+	var err error
+	if limits.ActiveLinks > 0 {
+		if quota.used.ActiveLinks.Used, err = service.countActive(scope, false); err != nil {
+			return nil, err
+		}
+	}
+	if limits.CustomCodes > 0 {
+		if quota.used.CustomCodes.Used, err = service.countActive(scope, true); err != nil {
+			return nil, err
+		}
+	}
+	if limits.MonthlyLinks > 0 {
+		if quota.used.MonthlyLinks.Used, err = service.countCreatedSince(scope, monthStart(now)); err != nil {
+			return nil, err
+		}
+	}
+
+	return quota, nil
+}
+
+// reserve takes one link of the kind described by check from every scope of
+// the budget, or from none when one of them is used up.
+func (budget quotaBudget) reserve(check quotaCheck) error {
+	for _, quota := range budget {
+		if err := quota.check(check); err != nil {
+			return err
+		}
+	}
+
+	budget.add(check, 1)
+
+	return nil
+}
+
+// release gives back a link reserved with check that was not created after all.
+func (budget quotaBudget) release(check quotaCheck) {
+	budget.add(check, -1)
+}
+
+func (budget quotaBudget) add(check quotaCheck, delta int64) {
+	for _, quota := range budget {
+		quota.used.ActiveLinks.Used += delta
+		if check.custom {
+			quota.used.CustomCodes.Used += delta
+		}
+		if check.created {
+			quota.used.MonthlyLinks.Used += delta
+		}
+	}
+}
+
+func (quota *quotaScope) check(check quotaCheck) error {
+	limits := quota.limits
+
+	if limits.ActiveLinks > 0 && quota.used.ActiveLinks.Used >= limits.ActiveLinks {
+		return newError(ErrQuotaExceeded, "%s has reached its quota of %d active links", quota.owner, limits.ActiveLinks)
+	}
+	if check.custom && limits.CustomCodes > 0 && quota.used.CustomCodes.Used >= limits.CustomCodes {
+		return newError(ErrQuotaExceeded, "%s has reached its quota of %d custom short codes", quota.owner, limits.CustomCodes)
+	}
+	if check.created && limits.MonthlyLinks > 0 && quota.used.MonthlyLinks.Used >= limits.MonthlyLinks {
+		return newError(ErrQuotaExceeded, "%s has reached its quota of %d links this month", quota.owner, limits.MonthlyLinks)
+	}
+
+	return nil
+}
+
+func (service *URLService) countActive(scope store.ListOptions, custom bool) (int64, error) {
+	scope.Status = store.StatusActive
+	scope.CustomCode = custom
+
+	count, err := service.store.Count(service.ctx, scope)
+	if err != nil {
+		return 0, unavailable(err)
+	}
+
+	return count, nil
+}
+
+// countCreatedSince counts deleted links too: deleting a link does not give
+// back its monthly creation.
+func (service *URLService) countCreatedSince(scope store.ListOptions, from time.Time) (int64, error) {
+	scope.Status = store.StatusAll
+	scope.CreatedFrom = from
+
+	count, err := service.store.Count(service.ctx, scope)
+	if err != nil {
+		return 0, unavailable(err)
+	}
+
+	return count, nil
+}
+
+// monthStart returns the first instant of the month of t, in UTC.
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yan-cerqueira-unvoid/url-shortener/config"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+func newQuotaService(t *testing.T, quotas config.QuotaConfig) (*URLService, *store.MemoryURLStore) {
+	t.Helper()
+
+	cfg := testConfig
+	cfg.Quotas = quotas
+	urlStore := store.NewMemoryURLStore()
+
+	return NewURLService(context.Background(), urlStore, cfg, testGenerator), urlStore
+}
+
+func TestQuotas_ActiveLinks(t *testing.T) {
+	service, _ := newQuotaService(t, config.QuotaConfig{User: config.QuotaLimits{ActiveLinks: 2}})
+
+	first, err := service.ShortenURL(testAccess, "https://example.com/1", models.ShortenOptions{})
+	require.NoError(t, err)
+	_, err = service.ShortenURL(testAccess, "https://example.com/2", models.ShortenOptions{})
+	require.NoError(t, err)
+
+	_, err = service.ShortenURL(testAccess, "https://example.com/3", models.ShortenOptions{})
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+	// Reusing a link creates nothing, so it is still allowed.
+	reused, err := service.ShortenURL(testAccess, "https://example.com/1", models.ShortenOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, first.ShortCode, reused.ShortCode)
+
+	// The quota is per user.
+	bob := models.Access{User: "bob", Workspace: models.DefaultWorkspace, Role: models.RoleEditor}
+	_, err = service.ShortenURL(bob, "https://example.com/3", models.ShortenOptions{})
+	require.NoError(t, err)
+
+	// Deleting a link frees its slot, and restoring it needs one again.
+	require.NoError(t, service.DeleteLink(testAccess, first.ShortCode))
+	_, err = service.ShortenURL(testAccess, "https://example.com/4", models.ShortenOptions{})
+	require.NoError(t, err)
+
+	_, err = service.RestoreLink(testAccess, first.ShortCode)
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+}
+
+func TestQuotas_ExtendingExpiredLink(t *testing.T) {
+	service, urlStore := newQuotaService(t, config.QuotaConfig{User: config.QuotaLimits{ActiveLinks: 1}})
+
+	expired := time.Now().Add(-time.Hour)
+	require.NoError(t, urlStore.Insert(context.Background(), &models.URL{
+		OriginalURL: "https://example.com/old",
+		ShortCode:   "old",
+		ExpiresAt:   &expired,
+		CreatedBy:   testAccess.User,
+		CreatedAt:   expired.Add(-time.Hour),
+	}))
+
+	// Expired links do not count, so the only active slot is free.
+	_, err := service.ShortenURL(testAccess, "https://example.com/1", models.ShortenOptions{})
+	require.NoError(t, err)
+
+	// Extending the expired link would make two active links.
+	_, err = service.UpdateLink(testAccess, "old", models.LinkUpdate{NeverExpires: true})
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+	stored, err := urlStore.GetByCode(context.Background(), "old")
+	require.NoError(t, err)
+	assert.True(t, stored.IsExpired(time.Now()))
+
+	// Other edits of an expired link need no slot.
+	_, err = service.UpdateLink(testAccess, "old", models.LinkUpdate{OriginalURL: "https://example.com/new"})
+	require.NoError(t, err)
+}
+
+func TestQuotas_CustomCodesAndWorkspace(t *testing.T) {
+	service, _ := newQuotaService(t, config.QuotaConfig{Workspace: config.QuotaLimits{CustomCodes: 1}})
+
+	_, err := service.ShortenURL(testAccess, "https://example.com/1", models.ShortenOptions{CustomCode: "first"})
+	require.NoError(t, err)
+
+	bob := models.Access{User: "bob", Workspace: models.DefaultWorkspace, Role: models.RoleEditor}
+	_, err = service.ShortenURL(bob, "https://example.com/2", models.ShortenOptions{CustomCode: "second"})
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+	// Generated codes are not custom codes.
+	_, err = service.ShortenURL(bob, "https://example.com/2", models.ShortenOptions{})
+	require.NoError(t, err)
+
+	// Other workspaces have their own quota.
+	sales := models.Access{User: "bob", Workspace: "sales", Role: models.RoleEditor}
+	_, err = service.ShortenURL(sales, "https://example.com/2", models.ShortenOptions{CustomCode: "second"})
+	require.NoError(t, err)
+}
+
+func TestQuotas_MonthlyLinksCountDeletedLinks(t *testing.T) {
+	service, urlStore := newQuotaService(t, config.QuotaConfig{User: config.QuotaLimits{MonthlyLinks: 2}})
+
+	// Links from earlier months do not count.
+	lastMonth := monthStart(time.Now()).Add(-time.Hour)
+	require.NoError(t, urlStore.Insert(context.Background(), &models.URL{
+		OriginalURL: "https://example.com/old",
+		ShortCode:   "old",
+		CreatedBy:   testAccess.User,
+		CreatedAt:   lastMonth,
+		UpdatedAt:   lastMonth,
+	}))
+
+	first, err := service.ShortenURL(testAccess, "https://example.com/1", models.ShortenOptions{})
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteLink(testAccess, first.ShortCode))
+
+	_, err = service.ShortenURL(testAccess, "https://example.com/2", models.ShortenOptions{})
+	require.NoError(t, err)
+
+	_, err = service.ShortenURL(testAccess, "https://example.com/3", models.ShortenOptions{})
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+}
+
+func TestUsage(t *testing.T) {
+	service, _ := newQuotaService(t, config.QuotaConfig{
+		User:      config.QuotaLimits{ActiveLinks: 10, MonthlyLinks: 100},
+		Workspace: config.QuotaLimits{CustomCodes: 5},
+	})
+
+	bob := models.Access{User: "bob", Workspace: models.DefaultWorkspace, Role: models.RoleEditor}
+	_, err := service.ShortenURL(testAccess, "https://example.com/1", models.ShortenOptions{CustomCode: "mine"})
+	require.NoError(t, err)
+	deleted, err := service.ShortenURL(testAccess, "https://example.com/2", models.ShortenOptions{})
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteLink(testAccess, deleted.ShortCode))
+	_, err = service.ShortenURL(bob, "https://example.com/3", models.ShortenOptions{})
+	require.NoError(t, err)
+
+	report, err := service.Usage(testAccess)
+	require.NoError(t, err)
+	assert.Equal(t, monthStart(time.Now()), report.PeriodStart)
+	assert.Equal(t, models.Usage{
+		ActiveLinks:  models.QuotaUsage{Used: 1, Limit: 10},
+		CustomCodes:  models.QuotaUsage{Used: 1},
+		MonthlyLinks: models.QuotaUsage{Used: 2, Limit: 100},
+	}, report.UserUsage)
+	assert.Equal(t, models.Usage{
+		ActiveLinks:  models.QuotaUsage{Used: 2},
+		CustomCodes:  models.QuotaUsage{Used: 1, Limit: 5},
+		MonthlyLinks: models.QuotaUsage{Used: 3},
+	}, report.WorkspaceUsage)
+
+	_, err = service.Usage(models.Access{})
+	assert.ErrorIs(t, err, ErrForbidden)
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+const (
+	DefaultStatsRange = 7 * 24 * time.Hour
+	DefaultStatsTop   = 10
+	MaxStatsTop       = 100
+	// MaxStatsBuckets bounds the series length, e.g. a year of days but not a
+	// year of hours.
+	MaxStatsBuckets = 1000
+)
+
+// StatsService aggregates the stored click history of links.
+type StatsService struct {
+	ctx    context.Context
+	urls   store.URLStore
+	clicks store.ClickStore
+}
+
+func NewStatsService(ctx context.Context, urlStore store.URLStore, clickStore store.ClickStore) *StatsService {
+	return &StatsService{
+		ctx:    ctx,
+		urls:   urlStore,
+		clicks: clickStore,
+	}
+}
+
+// LinkStats aggregates the clicks of a link between opts.From and opts.To.
+// Unique visitors are distinct IP and user agent pairs. Clicks with an empty
+// dimension (no referrer, unknown country...) are left out of the top lists,
+// and bot clicks are only counted in BotClicks unless opts.IncludeBots is set.
+func (service *StatsService) LinkStats(access models.Access, shortCode string, opts models.StatsOptions) (*models.LinkStats, error) {
+	if err := authorize(access, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	opts, err := resolveStatsOptions(time.Now(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := service.urls.GetByCode(service.ctx, shortCode)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, newError(ErrNotFound, "link %q not found", shortCode)
+		}
+
+		return nil, unavailable(err)
+	}
+
+	if link.IsDeleted() || !inWorkspace(access, link) {
+		return nil, newError(ErrNotFound, "link %q not found", shortCode)
+	}
+
+	bucket := 24 * time.Hour
+	if opts.Interval == models.IntervalHour {
+		bucket = time.Hour
+	}
+
+	filter := store.ClickFilter{ShortCode: shortCode, From: opts.From, To: opts.To}
+	summary, err := service.clicks.SummarizeClicks(service.ctx, filter, store.SummaryOptions{Bucket: bucket, IncludeBots: opts.IncludeBots})
+	if err != nil {
+		return nil, unavailable(err)
+	}
+
+	step := intervalStep(opts.Interval)
+	first := bucketStart(opts.From, opts.Interval)
+
+	series := []models.StatsBucket{}
+	for start := first; start.Before(opts.To); start = start.Add(step) {
+		series = append(series, models.StatsBucket{Start: start})
+	}
+	// Weeks are made of the daily buckets of the store.
+	for start, clicks := range summary.Series {
+		index := int(bucketStart(start, opts.Interval).Sub(first) / step)
+		if index >= 0 && index < len(series) {
+			series[index].Clicks += clicks
+		}
+	}
+
+	referrers := make(map[string]int64, len(summary.Referrers))
+	for referrer, clicks := range summary.Referrers {
+		referrers[referrerHost(referrer)] += clicks
+	}
+
+	return &models.LinkStats{
+		ShortCode:      link.ShortCode,
+		TotalClicks:    link.Clicks,
+		RangeClicks:    summary.Clicks,
+		BotClicks:      summary.Bots,
+		UniqueVisitors: summary.Visitors,
+		From:           opts.From,
+		To:             opts.To,
+		Interval:       opts.Interval,
+		Series:         series,
+		TopReferrers:   topValues(referrers, opts.Top),
+		TopCountries:   topValues(summary.Countries, opts.Top),
+		TopRegions:     topValues(summary.Regions, opts.Top),
+		TopCities:      topValues(summary.Cities, opts.Top),
+		TopBrowsers:    topValues(summary.Browsers, opts.Top),
+		TopOS:          topValues(summary.OS, opts.Top),
+	}, nil
+}
+
+// resolveStatsOptions fills in the defaults (the last week, by day, top 10)
+// and rejects ranges that are inverted or would produce too many buckets.
+func resolveStatsOptions(now time.Time, opts models.StatsOptions) (models.StatsOptions, error) {
+	if opts.To.IsZero() {
+		opts.To = now
+	}
+	if opts.From.IsZero() {
+		opts.From = opts.To.Add(-DefaultStatsRange)
+	}
+	opts.From = opts.From.UTC()
+	opts.To = opts.To.UTC()
+
+	if !opts.From.Before(opts.To) {
+		return opts, newError(ErrInvalid, "from must be before to")
+	}
+
+	switch opts.Interval {
+	case "":
+		opts.Interval = models.IntervalDay
+	case models.IntervalHour, models.IntervalDay, models.IntervalWeek:
+	default:
+		return opts, newError(ErrInvalid, "interval must be one of %s, %s or %s", models.IntervalHour, models.IntervalDay, models.IntervalWeek)
+	}
+
+	step := intervalStep(opts.Interval)
+	buckets := (opts.To.Sub(bucketStart(opts.From, opts.Interval)) + step - 1) / step
+	if buckets > MaxStatsBuckets {
+		return opts, newError(ErrInvalid, "range too large for interval %s, at most %d buckets", opts.Interval, MaxStatsBuckets)
+	}
+
+	if opts.Top < 0 {
+		return opts, newError(ErrInvalid, "top must not be negative")
+	}
+	if opts.Top == 0 {
+		opts.Top = DefaultStatsTop
+	}
+	if opts.Top > MaxStatsTop {
+		opts.Top = MaxStatsTop
+	}
+
+	return opts, nil
+}
+
+func intervalStep(interval string) time.Duration {
+	switch interval {
+	case models.IntervalHour:
+		return time.Hour
+	case models.IntervalWeek:
+		return 7 * 24 * time.Hour
+	default:
+		return 24 * time.Hour
+	}
+}
+
+// bucketStart truncates t to the start of its bucket in UTC. Weeks start on
+// Monday.
+func bucketStart(t time.Time, interval string) time.Time {
+	t = t.UTC()
+
+	switch interval {
+	case models.IntervalHour:
+		return t.Truncate(time.Hour)
+	case models.IntervalWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// referrerHost reduces a referrer to its host so that different pages of the
+// same site are counted together.
+func referrerHost(referrer string) string {
+	parsed, err := url.Parse(referrer)
+	if err != nil || parsed.Hostname() == "" {
+		return referrer
+	}
+
+	return parsed.Hostname()
+}
+
+// topValues returns the n most frequent values, ties broken alphabetically.
+func topValues(counts map[string]int64, n int) []models.CountByValue {
+	values := make([]models.CountByValue, 0, len(counts))
+	for value, clicks := range counts {
+		values = append(values, models.CountByValue{Value: value, Clicks: clicks})
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Clicks != values[j].Clicks {
+			return values[i].Clicks > values[j].Clicks
+		}
+
+		return values[i].Value < values[j].Value
+	})
+
+	if len(values) > n {
+		values = values[:n]
+	}
+
+	return values
+}
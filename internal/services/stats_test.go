@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/mocks"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+func TestLinkStats(t *testing.T) {
+	ctx := context.Background()
+	urlStore := store.NewMemoryURLStore()
+	clickStore := store.NewMemoryClickStore(urlStore)
+	service := NewStatsService(ctx, urlStore, clickStore)
+
+	require.NoError(t, urlStore.Insert(ctx, &models.URL{ShortCode: "abc123", OriginalURL: "https://example.com", Clicks: 42}))
+
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC) // a Monday
+	require.NoError(t, clickStore.InsertClicks(ctx, []models.Click{
+		{ShortCode: "abc123", Timestamp: day.Add(time.Hour), IP: "192.0.2.1", UserAgent: "a", Referrer: "https://news.example/item?id=1", Country: "BR", Region: "BR-SP", City: "São Paulo", Browser: "Firefox", OS: "Linux"},
+		{ShortCode: "abc123", Timestamp: day.Add(2 * time.Hour), IP: "192.0.2.1", UserAgent: "a", Referrer: "https://news.example/item?id=2", Country: "BR", Region: "BR-SP", City: "São Paulo", Browser: "Firefox", OS: "Linux"},
+		{ShortCode: "abc123", Timestamp: day.Add(26 * time.Hour), IP: "192.0.2.2", UserAgent: "b", Referrer: "https://social.example/", Country: "US", Region: "US-OR", City: "Portland", Browser: "Chrome", OS: "Windows"},
+		{ShortCode: "abc123", Timestamp: day.Add(50 * time.Hour), IP: "192.0.2.3", UserAgent: "c"},
+		{ShortCode: "abc123", Timestamp: day.Add(-time.Hour), IP: "192.0.2.4"},
+		{ShortCode: "abc123", Timestamp: day.Add(3 * time.Hour), IP: "192.0.2.6", Browser: "Slackbot", Bot: true},
+		{ShortCode: "other", Timestamp: day.Add(time.Hour), IP: "192.0.2.5"},
+	}))
+
+	stats, err := service.LinkStats(testAccess, "abc123", models.StatsOptions{From: day, To: day.AddDate(0, 0, 3)})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(42), stats.TotalClicks)
+	assert.Equal(t, int64(4), stats.RangeClicks)
+	assert.Equal(t, int64(1), stats.BotClicks)
+	assert.Equal(t, int64(3), stats.UniqueVisitors)
+	assert.Equal(t, models.IntervalDay, stats.Interval)
+	assert.Equal(t, []models.StatsBucket{
+		{Start: day, Clicks: 2},
+		{Start: day.AddDate(0, 0, 1), Clicks: 1},
+		{Start: day.AddDate(0, 0, 2), Clicks: 1},
+	}, stats.Series)
+	assert.Equal(t, []models.CountByValue{{Value: "news.example", Clicks: 2}, {Value: "social.example", Clicks: 1}}, stats.TopReferrers)
+	assert.Equal(t, []models.CountByValue{{Value: "BR", Clicks: 2}, {Value: "US", Clicks: 1}}, stats.TopCountries)
+	assert.Equal(t, []models.CountByValue{{Value: "BR-SP", Clicks: 2}, {Value: "US-OR", Clicks: 1}}, stats.TopRegions)
+	assert.Equal(t, []models.CountByValue{{Value: "São Paulo, BR", Clicks: 2}, {Value: "Portland, US", Clicks: 1}}, stats.TopCities)
+	assert.Equal(t, []models.CountByValue{{Value: "Firefox", Clicks: 2}, {Value: "Chrome", Clicks: 1}}, stats.TopBrowsers)
+	assert.Equal(t, []models.CountByValue{{Value: "Linux", Clicks: 2}, {Value: "Windows", Clicks: 1}}, stats.TopOS)
+
+	hourly, err := service.LinkStats(testAccess, "abc123", models.StatsOptions{From: day, To: day.Add(3 * time.Hour), Interval: models.IntervalHour, Top: 1})
+	require.NoError(t, err)
+	assert.Len(t, hourly.Series, 3)
+	assert.Equal(t, int64(0), hourly.Series[0].Clicks)
+	assert.Equal(t, int64(1), hourly.Series[1].Clicks)
+	assert.Equal(t, int64(1), hourly.Series[2].Clicks)
+	assert.Len(t, hourly.TopReferrers, 1)
+
+	weekly, err := service.LinkStats(testAccess, "abc123", models.StatsOptions{From: day.Add(12 * time.Hour), To: day.AddDate(0, 0, 8), Interval: models.IntervalWeek})
+	require.NoError(t, err)
+	require.Len(t, weekly.Series, 2)
+	assert.Equal(t, day, weekly.Series[0].Start)
+	assert.Equal(t, int64(2), weekly.Series[0].Clicks)
+}
+
+func TestLinkStats_IncludeBots(t *testing.T) {
+	ctx := context.Background()
+	urlStore := store.NewMemoryURLStore()
+	clickStore := store.NewMemoryClickStore(urlStore)
+	service := NewStatsService(ctx, urlStore, clickStore)
+
+	now := time.Now()
+	require.NoError(t, urlStore.Insert(ctx, &models.URL{ShortCode: "abc123", OriginalURL: "https://example.com"}))
+	require.NoError(t, clickStore.InsertClicks(ctx, []models.Click{
+		{ShortCode: "abc123", Timestamp: now.Add(-time.Hour), IP: "192.0.2.1", Browser: "Firefox"},
+		{ShortCode: "abc123", Timestamp: now.Add(-time.Hour), IP: "192.0.2.2", Browser: "Twitterbot", Bot: true},
+	}))
+
+	stats, err := service.LinkStats(testAccess, "abc123", models.StatsOptions{IncludeBots: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(2), stats.RangeClicks)
+	assert.Equal(t, int64(1), stats.BotClicks)
+	assert.Equal(t, int64(2), stats.UniqueVisitors)
+	assert.Len(t, stats.TopBrowsers, 2)
+}
+
+func TestLinkStats_InvalidOptions(t *testing.T) {
+	service := NewStatsService(context.Background(), store.NewMemoryURLStore(), store.NewMemoryClickStore(nil))
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		opts models.StatsOptions
+	}{
+		{"Inverted range", models.StatsOptions{From: now, To: now.Add(-time.Hour)}},
+		{"Unknown interval", models.StatsOptions{Interval: "month"}},
+		{"Too many buckets", models.StatsOptions{From: now.AddDate(-1, 0, 0), To: now, Interval: models.IntervalHour}},
+		{"Negative top", models.StatsOptions{Top: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.LinkStats(testAccess, "abc123", tt.opts)
+			assert.ErrorIs(t, err, ErrInvalid)
+		})
+	}
+}
+
+func TestLinkStats_NotFound(t *testing.T) {
+	ctx := context.Background()
+	urlStore := store.NewMemoryURLStore()
+	service := NewStatsService(ctx, urlStore, store.NewMemoryClickStore(urlStore))
+
+	deletedAt := time.Now()
+	require.NoError(t, urlStore.Insert(ctx, &models.URL{ShortCode: "gone", DeletedAt: &deletedAt}))
+
+	_, err := service.LinkStats(testAccess, "missing", models.StatsOptions{})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = service.LinkStats(testAccess, "gone", models.StatsOptions{})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLinkStats_ClickStoreError(t *testing.T) {
+	urlStore := new(mocks.URLStore)
+	clickStore := new(mocks.ClickStore)
+	service := NewStatsService(context.Background(), urlStore, clickStore)
+
+	urlStore.On("GetByCode", mock.Anything, "abc123").Return(&models.URL{ShortCode: "abc123"}, nil)
+	clickStore.On("SummarizeClicks", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+
+	_, err := service.LinkStats(testAccess, "abc123", models.StatsOptions{})
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/yan-cerqueira-unvoid/url-shortener/config"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/jwks"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+)
+
+// signingAlgorithms are the asymmetric algorithms tokens may be signed with.
+// HMAC is left out: a public key set cannot hold the shared secret.
+var signingAlgorithms = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// TokenVerifier validates bearer tokens issued by an identity provider and
+// maps their claims to a user and workspace.
+type TokenVerifier struct {
+	ctx    context.Context
+	keys   *jwks.Provider
+	parser *jwt.Parser
+	cfg    config.JWTConfig
+}
+
+func NewTokenVerifier(ctx context.Context, keys *jwks.Provider, cfg config.JWTConfig) (*TokenVerifier, error) {
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return nil, errors.New("JWT issuer and audience are required to accept tokens")
+	}
+	if cfg.UserClaim == "" {
+		return nil, errors.New("JWT user claim is required")
+	}
+
+	return &TokenVerifier{
+		ctx:  ctx,
+		keys: keys,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods(signingAlgorithms),
+			jwt.WithIssuer(cfg.Issuer),
+			jwt.WithAudience(cfg.Audience),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(cfg.Leeway),
+		),
+		cfg: cfg,
+	}, nil
+}
+
+// Verify checks the token signature, issuer, audience and lifetime, and
+// returns the user and workspace it names. Tokens without the workspace claim
+// act in the default workspace.
+func (verifier *TokenVerifier) Verify(rawToken string) (user, workspace string, err error) {
+	claims := jwt.MapClaims{}
+	if _, err := verifier.parser.ParseWithClaims(rawToken, claims, verifier.key); err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return "", "", newError(ErrUnauthorized, "token has expired")
+		}
+
+		return "", "", &Error{Kind: ErrUnauthorized, Message: "invalid token", Err: err}
+	}
+
+	user, err = stringClaim(claims, verifier.cfg.UserClaim)
+	if err != nil || user == "" {
+		return "", "", newError(ErrUnauthorized, "token has no %q claim", verifier.cfg.UserClaim)
+	}
+
+	workspace = models.DefaultWorkspace
+	if verifier.cfg.WorkspaceClaim != "" {
+		value, err := stringClaim(claims, verifier.cfg.WorkspaceClaim)
+		if err != nil {
+			return "", "", newError(ErrUnauthorized, "token claim %q must be a string", verifier.cfg.WorkspaceClaim)
+		}
+		workspace = models.WorkspaceOrDefault(value)
+	}
+
+	return user, workspace, nil
+}
+
+// key finds the key the token names and makes sure it suits the token's
+// algorithm, so a key published for one algorithm cannot verify another.
+func (verifier *TokenVerifier) key(token *jwt.Token) (any, error) {
+	id, _ := token.Header["kid"].(string)
+
+	key, err := verifier.keys.Key(verifier.ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm := token.Method.Alg()
+	if key.Algorithm != "" && key.Algorithm != algorithm {
+		return nil, fmt.Errorf("key %q is not for %s", key.ID, algorithm)
+	}
+
+	switch key.Public.(type) {
+	case *rsa.PublicKey:
+		if algorithm[:2] == "RS" || algorithm[:2] == "PS" {
+			return key.Public, nil
+		}
+	case *ecdsa.PublicKey:
+		if algorithm[:2] == "ES" {
+			return key.Public, nil
+		}
+	case ed25519.PublicKey:
+		if algorithm == "EdDSA" {
+			return key.Public, nil
+		}
+	}
+
+	return nil, fmt.Errorf("key %q cannot verify %s", key.ID, algorithm)
+}
+
+func stringClaim(claims jwt.MapClaims, name string) (string, error) {
+	value, ok := claims[name]
+	if !ok || value == nil {
+		return "", nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("claim %q is not a string", name)
+	}
+
+	return text, nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yan-cerqueira-unvoid/url-shortener/config"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/jwks"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/jwks/jwkstest"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+)
+
+var testJWTConfig = config.JWTConfig{
+	Issuer:         "https://id.example.com",
+	Audience:       "url-shortener",
+	UserClaim:      "sub",
+	WorkspaceClaim: "workspace",
+}
+
+func newTokenVerifier(t *testing.T, signers ...*jwkstest.Signer) *TokenVerifier {
+	t.Helper()
+
+	keys, err := jwks.Open(context.Background(), jwkstest.WriteKeySet(t, signers...))
+	require.NoError(t, err)
+
+	verifier, err := NewTokenVerifier(context.Background(), keys, testJWTConfig)
+	require.NoError(t, err)
+
+	return verifier
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":       testJWTConfig.Issuer,
+		"aud":       testJWTConfig.Audience,
+		"sub":       "alice",
+		"workspace": "marketing",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestTokenVerifier_Verify(t *testing.T) {
+	signer := jwkstest.NewSigner(t, "key-1")
+	verifier := newTokenVerifier(t, signer)
+
+	user, workspace, err := verifier.Verify(signer.Sign(t, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", user)
+	assert.Equal(t, "marketing", workspace)
+
+	claims := validClaims()
+	delete(claims, "workspace")
+	claims["aud"] = []string{"other", testJWTConfig.Audience}
+
+	_, workspace, err = verifier.Verify(signer.Sign(t, claims))
+	require.NoError(t, err)
+	assert.Equal(t, models.DefaultWorkspace, workspace)
+}
+
+func TestTokenVerifier_Rejects(t *testing.T) {
+	signer := jwkstest.NewSigner(t, "key-1")
+	stranger := jwkstest.NewSigner(t, "key-1")
+	verifier := newTokenVerifier(t, signer)
+
+	with := func(name string, value any) jwt.MapClaims {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+
+	hmac, err := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims()).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	// Alice's signature over Bob's claims.
+	alice := strings.Split(signer.Sign(t, validClaims()), ".")
+	bob := strings.Split(signer.Sign(t, with("sub", "bob")), ".")
+	tampered := alice[0] + "." + bob[1] + "." + alice[2]
+
+	for name, token := range map[string]string{
+		"garbage":         "not.a.token",
+		"unknown signer":  stranger.Sign(t, validClaims()),
+		"hmac":            hmac,
+		"expired":         signer.Sign(t, with("exp", time.Now().Add(-time.Hour).Unix())),
+		"no expiry":       signer.Sign(t, with("exp", nil)),
+		"not yet valid":   signer.Sign(t, with("nbf", time.Now().Add(time.Hour).Unix())),
+		"wrong issuer":    signer.Sign(t, with("iss", "https://evil.example.com")),
+		"wrong audience":  signer.Sign(t, with("aud", "other")),
+		"no subject":      signer.Sign(t, with("sub", nil)),
+		"bad workspace":   signer.Sign(t, with("workspace", 42)),
+		"unknown key id":  jwkstest.NewSigner(t, "key-2").Sign(t, validClaims()),
+		"tampered claims": tampered,
+	} {
+		_, _, err := verifier.Verify(token)
+		assert.ErrorIs(t, err, ErrUnauthorized, name)
+	}
+}
+
+func TestNewTokenVerifier_RequiresIssuerAndAudience(t *testing.T) {
+	cfg := testJWTConfig
+	cfg.Audience = ""
+
+	_, err := NewTokenVerifier(context.Background(), nil, cfg)
+	assert.Error(t, err)
+}
@@ -2,138 +2,324 @@ package services
 
 import (
 	"context"
-	"crypto/md5"
-	"encoding/base64"
 	"errors"
-	"fmt"
-	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/yan-cerqueira-unvoid/url-shortener/config"
 	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
 )
 
+const (
+	defaultCodeLength = 6
+	maxCodeLength     = 32
+
+	// After this many consecutive collisions the generated code grows by one
+	// character, so a crowded keyspace does not keep the loop spinning.
+	collisionsBeforeGrow = 3
+	maxGenerateAttempts  = 12
+
+	minCustomCodeLength = 3
+	maxCustomCodeLength = 64
+)
+
+// reservedCodes are path segments the router uses, so links with these codes
+// could not be told apart from its routes.
+var reservedCodes = map[string]bool{
+	"api":     true,
+	"shorten": true,
+	"qr":      true,
+}
+
 type URLService struct {
-	db         *mongo.Database
-	ctx        *context.Context
-	collection *mongo.Collection
+	ctx       context.Context
+	store     store.URLStore
+	config    config.URLShortenerConfig
+	generator CodeGenerator
 }
 
-func NewURLService(db *mongo.Database, ctx *context.Context) *URLService {
-	collection := db.Collection("urls")
-	indexModel := mongo.IndexModel{
-		Keys:    bson.M{"short_code": 1},
-		Options: options.Index().SetUnique(true),
+func NewURLService(ctx context.Context, urlStore store.URLStore, cfg config.URLShortenerConfig, generator CodeGenerator) *URLService {
+	if cfg.CodeLength <= 0 {
+		cfg.CodeLength = defaultCodeLength
 	}
-	_, err := collection.Indexes().CreateOne(*ctx, indexModel)
-	if err != nil {
-		panic(fmt.Sprintf("Failed to create index: %v", err))
+	if cfg.CodeLength > maxCodeLength {
+		cfg.CodeLength = maxCodeLength
+	}
+	if !models.IsValidRedirectType(cfg.DefaultRedirectType) {
+		cfg.DefaultRedirectType = http.StatusMovedPermanently
+	}
+	// A default beyond the maximum would refuse every link created without
+	// an explicit expiry.
+	if cfg.MaxExpiry > 0 && cfg.DefaultExpiry > cfg.MaxExpiry {
+		cfg.DefaultExpiry = cfg.MaxExpiry
 	}
 
 	return &URLService{
-		db:         db,
-		ctx:        ctx,
-		collection: collection,
+		ctx:       ctx,
+		store:     urlStore,
+		config:    cfg,
+		generator: generator,
 	}
 }
 
-func (service *URLService) ShortenURL(originalURL string, customCode string) (*models.URL, error) {
+// ShortenURL creates a link in the workspace of access, which needs the
+// editor role. Reusing an existing link is always allowed; a new one must fit
+// the quotas of the user and the workspace.
+func (service *URLService) ShortenURL(access models.Access, originalURL string, opts models.ShortenOptions) (*models.URL, error) {
+	if err := authorize(access, models.RoleEditor); err != nil {
+		return nil, err
+	}
 
-	var existingURL models.URL
+	if err := service.validateCustomCode(opts.CustomCode); err != nil {
+		return nil, err
+	}
 
-	err := service.collection.FindOne(*service.ctx, bson.M{"original_url": originalURL}).Decode(&existingURL)
+	now := time.Now()
 
-	if err == nil {
-		return &existingURL, nil
-	} else if err != mongo.ErrNoDocuments {
+	expiresAt, err := service.resolveExpiry(now, opts)
+	if err != nil {
 		return nil, err
 	}
 
-	var shortCode string
-
-	if customCode != "" {
-		var existingCustom models.URL
+	redirectType, err := service.resolveRedirectType(opts.RedirectType)
+	if err != nil {
+		return nil, err
+	}
 
-		err := service.collection.FindOne(*service.ctx, bson.M{"short_code": customCode}).Decode(&existingCustom)
-		if err == nil {
-			return nil, errors.New("custom short code already in use")
-		} else if err != mongo.ErrNoDocuments {
+	if !hasExpiryOverride(opts) {
+		existingURL, err := service.reusableLink(originalURL, access.Workspace, redirectType, now)
+		if err != nil {
 			return nil, err
 		}
+		if existingURL != nil {
+			return existingURL, nil
+		}
+	}
 
-		shortCode = customCode
-	} else {
-		shortCode = service.generateShortCode(originalURL)
+	check := quotaCheck{custom: opts.CustomCode != "", created: true}
+	if err := service.checkQuotas(access.User, access.Workspace, check, now); err != nil {
+		return nil, err
+	}
+
+	url := &models.URL{
+		OriginalURL:  originalURL,
+		Clicks:       0,
+		ExpiresAt:    expiresAt,
+		RedirectType: redirectType,
+		CreatedBy:    access.User,
+		Workspace:    access.Workspace,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
 
-		for {
-			var existingCode models.URL
+	if opts.CustomCode != "" {
+		url.ShortCode = opts.CustomCode
+		url.CustomCode = true
 
-			err := service.collection.FindOne(*service.ctx, bson.M{"short_code": shortCode}).Decode(&existingCode)
-			if err == mongo.ErrNoDocuments {
-				break
+		if err := service.store.Insert(service.ctx, url); err != nil {
+			if errors.Is(err, store.ErrDuplicateCode) {
+				return nil, ErrCodeTaken
 			}
 
-			shortCode = service.generateShortCode(originalURL + fmt.Sprint(rand.Intn(1000)))
+			return nil, unavailable(err)
 		}
+
+		return url, nil
 	}
 
-	now := time.Now()
-	url := models.URL{
-		OriginalURL: originalURL,
-		ShortCode:   shortCode,
-		Clicks:      0,
-		ExpiresAt:   now.AddDate(1, 0, 0), // Expires in 1 year
-		CreatedBy:   "anonymous",          // Would be set from auth in a real app
-		CreatedAt:   now,
-		UpdatedAt:   now,
+	if err := service.insertWithGeneratedCode(url); err != nil {
+		return nil, unavailable(err)
 	}
 
-	_, err = service.collection.InsertOne(*service.ctx, url)
+	return url, nil
+}
+
+// GetURL resolves a short code for redirection. It does not write: clicks are
+// counted asynchronously by the analytics recorder.
+func (service *URLService) GetURL(shortCode string) (*models.URL, error) {
+	url, err := service.store.GetByCode(service.ctx, shortCode)
 	if err != nil {
-		return nil, err
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, newError(ErrNotFound, "short URL not found")
+		}
+
+		return nil, unavailable(err)
+	}
+
+	if url.IsDeleted() {
+		return nil, newError(ErrNotFound, "short URL not found")
+	}
+
+	if url.IsExpired(time.Now()) {
+		return nil, newError(ErrExpired, "URL has expired")
 	}
 
-	return &url, nil
+	url.RedirectType = service.redirectTypeOf(url)
+
+	return url, nil
 }
 
-func (service *URLService) GetURL(shortCode string) (*models.URL, error) {
-	var url models.URL
+// resolveExpiry turns the request options into an expiry time, applying the
+// configured default and maximum. A nil result means the link never expires.
+func (service *URLService) resolveExpiry(now time.Time, opts models.ShortenOptions) (*time.Time, error) {
+	overrides := 0
+	if opts.ExpiresAt != nil {
+		overrides++
+	}
+	if opts.TTL != 0 {
+		overrides++
+	}
+	if opts.NeverExpires {
+		overrides++
+	}
+	if overrides > 1 {
+		return nil, newError(ErrInvalid, "only one of expires_at, ttl or never_expires may be set")
+	}
 
-	err := service.collection.FindOne(*service.ctx, bson.M{"short_code": shortCode}).Decode(&url)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("short URL not found")
+	maxExpiry := service.config.MaxExpiry
+
+	var expiresAt time.Time
+	switch {
+	case opts.ExpiresAt != nil:
+		if !opts.ExpiresAt.After(now) {
+			return nil, newError(ErrInvalid, "expires_at must be in the future")
 		}
+		expiresAt = *opts.ExpiresAt
+	case opts.TTL < 0:
+		return nil, newError(ErrInvalid, "ttl must be positive")
+	case opts.TTL > 0:
+		expiresAt = now.Add(opts.TTL)
+	case opts.NeverExpires:
+		if maxExpiry > 0 {
+			return nil, newError(ErrInvalid, "links must expire within %s", formatDays(maxExpiry))
+		}
+		return nil, nil
+	case service.config.DefaultExpiry > 0:
+		expiresAt = now.Add(service.config.DefaultExpiry)
+	case maxExpiry > 0:
+		expiresAt = now.Add(maxExpiry)
+	default:
+		return nil, nil
+	}
 
-		return nil, err
+	if maxExpiry > 0 && expiresAt.Sub(now) > maxExpiry {
+		return nil, newError(ErrInvalid, "links must expire within %s", formatDays(maxExpiry))
 	}
 
-	if time.Now().After(url.ExpiresAt) {
-		return nil, errors.New("URL has expired")
+	return &expiresAt, nil
+}
+
+// formatDays describes an expiry limit in days, as it is configured, falling
+// back to the duration for limits that are not a whole number of days.
+func formatDays(d time.Duration) string {
+	const day = 24 * time.Hour
+
+	switch {
+	case d == day:
+		return "1 day"
+	case d%day == 0:
+		return strconv.Itoa(int(d/day)) + " days"
+	default:
+		return d.String()
 	}
+}
 
-	_, err = service.collection.UpdateOne(
-		*service.ctx,
-		bson.M{"short_code": shortCode},
-		bson.M{"$inc": bson.M{"clicks": 1}, "$set": bson.M{"updated_at": time.Now()}},
-	)
+// validateCustomCode accepts an empty code, meaning none was asked for, or a
+// code made of the generator's alphabet that does not shadow a route.
+func (service *URLService) validateCustomCode(code string) error {
+	if code == "" {
+		return nil
+	}
 
-	if err != nil {
-		return nil, err
+	if len(code) < minCustomCodeLength || len(code) > maxCustomCodeLength {
+		return newError(ErrInvalid, "custom_code must be %d to %d characters long", minCustomCodeLength, maxCustomCodeLength)
+	}
+
+	alphabet := alphabetOrDefault(service.config.CodeAlphabet)
+	for _, r := range code {
+		if !strings.ContainsRune(alphabet, r) {
+			return newError(ErrInvalid, "custom_code may only contain the characters %q", alphabet)
+		}
+	}
+
+	if reservedCodes[strings.ToLower(code)] {
+		return newError(ErrInvalid, "custom_code %q is reserved", code)
+	}
+
+	return nil
+}
+
+func (service *URLService) resolveRedirectType(redirectType int) (int, error) {
+	if redirectType == 0 {
+		return service.config.DefaultRedirectType, nil
+	}
+
+	if !models.IsValidRedirectType(redirectType) {
+		return 0, newError(ErrInvalid, "redirect_type must be one of 301, 302, 307 or 308")
 	}
 
-	url.Clicks++
+	return redirectType, nil
+}
+
+// redirectTypeOf returns the link's redirect status, falling back to the
+// configured default for links stored before redirect types existed.
+func (service *URLService) redirectTypeOf(url *models.URL) int {
+	if url.RedirectType == 0 {
+		return service.config.DefaultRedirectType
+	}
 
-	return &url, nil
+	return url.RedirectType
 }
 
-func (service *URLService) generateShortCode(url string) string {
-	hasher := md5.New()
+// reusableLink returns the live link of workspace to originalURL that
+// redirects with redirectType, or nil when there is none. An explicit expiry
+// asks for a new link, so only requests without one may reuse a link.
+func (service *URLService) reusableLink(originalURL, workspace string, redirectType int, now time.Time) (*models.URL, error) {
+	existingURL, err := service.store.GetByOriginalURL(service.ctx, originalURL, workspace)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, unavailable(err)
+	}
+
+	if existingURL.IsExpired(now) || service.redirectTypeOf(existingURL) != redirectType {
+		return nil, nil
+	}
+
+	return existingURL, nil
+}
 
-	hasher.Write([]byte(url + time.Now().String()))
-	hash := base64.URLEncoding.EncodeToString(hasher.Sum(nil))
+func hasExpiryOverride(opts models.ShortenOptions) bool {
+	return opts.ExpiresAt != nil || opts.TTL != 0 || opts.NeverExpires
+}
+
+// insertWithGeneratedCode relies on the store's unique index instead of
+// checking for a free code first, so concurrent requests cannot race between
+// the check and the insert. Collisions are retried with a fresh code.
+func (service *URLService) insertWithGeneratedCode(url *models.URL) error {
+	length := service.config.CodeLength
+
+	for attempt := 0; attempt < maxGenerateAttempts; attempt++ {
+		if attempt > 0 && attempt%collisionsBeforeGrow == 0 && length < maxCodeLength {
+			length++
+		}
+
+		shortCode, err := service.generator.Generate(service.ctx, length)
+		if err != nil {
+			return err
+		}
+		url.ShortCode = shortCode
+
+		err = service.store.Insert(service.ctx, url)
+		if err == nil {
+			return nil
+		} else if !errors.Is(err, store.ErrDuplicateCode) {
+			return err
+		}
+	}
 
-	return hash[:6]
+	return newError(ErrUnavailable, "failed to generate a unique short code")
 }
@@ -0,0 +1,437 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yan-cerqueira-unvoid/url-shortener/config"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/mocks"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+var testConfig = config.URLShortenerConfig{
+	DefaultExpiry: 24 * time.Hour,
+	CodeLength:    6,
+}
+
+var testGenerator = &RandomCodeGenerator{alphabet: base62Alphabet}
+
+var testAccess = models.Access{User: "alice", Workspace: models.DefaultWorkspace, Role: models.RoleOwner}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func newShortenMockStore() *mocks.URLStore {
+	mockStore := new(mocks.URLStore)
+	mockStore.On("GetByOriginalURL", mock.Anything, mock.Anything, mock.Anything).Return(nil, store.ErrNotFound)
+	mockStore.On("Insert", mock.Anything, mock.AnythingOfType("*models.URL")).Return(nil)
+
+	return mockStore
+}
+
+func TestShortenURL_ReturnsExistingURL(t *testing.T) {
+	mockStore := new(mocks.URLStore)
+	service := NewURLService(context.Background(), mockStore, testConfig, testGenerator)
+
+	existing := &models.URL{OriginalURL: "https://example.com", ShortCode: "abc123"}
+	mockStore.On("GetByOriginalURL", mock.Anything, "https://example.com", mock.Anything).Return(existing, nil)
+
+	url, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, existing, url)
+	mockStore.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+}
+
+func TestShortenURL_ScopesLinkToWorkspace(t *testing.T) {
+	mockStore := new(mocks.URLStore)
+	service := NewURLService(context.Background(), mockStore, testConfig, testGenerator)
+	access := models.Access{User: "bob", Workspace: "marketing", Role: models.RoleEditor}
+
+	mockStore.On("GetByOriginalURL", mock.Anything, "https://example.com", "marketing").Return(nil, store.ErrNotFound)
+	mockStore.On("Insert", mock.Anything, mock.AnythingOfType("*models.URL")).Return(nil)
+
+	url, err := service.ShortenURL(access, "https://example.com", models.ShortenOptions{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "marketing", url.Workspace)
+	assert.Equal(t, "bob", url.CreatedBy)
+	mockStore.AssertExpectations(t)
+}
+
+func TestShortenURL_RequiresEditor(t *testing.T) {
+	mockStore := new(mocks.URLStore)
+	service := NewURLService(context.Background(), mockStore, testConfig, testGenerator)
+
+	for _, access := range []models.Access{
+		{User: "carol", Workspace: "marketing", Role: models.RoleViewer},
+		{},
+	} {
+		_, err := service.ShortenURL(access, "https://example.com", models.ShortenOptions{})
+		assert.ErrorIs(t, err, ErrForbidden)
+	}
+
+	mockStore.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+}
+
+func TestShortenURL_GeneratesCode(t *testing.T) {
+	mockStore := new(mocks.URLStore)
+	service := NewURLService(context.Background(), mockStore, testConfig, testGenerator)
+
+	mockStore.On("GetByOriginalURL", mock.Anything, "https://example.com", mock.Anything).Return(nil, store.ErrNotFound)
+	mockStore.On("Insert", mock.Anything, mock.AnythingOfType("*models.URL")).Return(nil)
+
+	url, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com", url.OriginalURL)
+	assert.Len(t, url.ShortCode, 6)
+	mockStore.AssertExpectations(t)
+}
+
+func TestShortenURL_UsesConfiguredCodeLength(t *testing.T) {
+	mockStore := new(mocks.URLStore)
+	service := NewURLService(context.Background(), mockStore, config.URLShortenerConfig{CodeLength: 10}, testGenerator)
+
+	mockStore.On("GetByOriginalURL", mock.Anything, "https://example.com", mock.Anything).Return(nil, store.ErrNotFound)
+	mockStore.On("Insert", mock.Anything, mock.AnythingOfType("*models.URL")).Return(nil)
+
+	url, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{})
+
+	assert.Nil(t, err)
+	assert.Len(t, url.ShortCode, 10)
+}
+
+func TestShortenURL_GrowsCodeAfterCollisions(t *testing.T) {
+	mockStore := new(mocks.URLStore)
+	service := NewURLService(context.Background(), mockStore, testConfig, testGenerator)
+
+	mockStore.On("GetByOriginalURL", mock.Anything, "https://example.com", mock.Anything).Return(nil, store.ErrNotFound)
+	mockStore.On("Insert", mock.Anything, mock.AnythingOfType("*models.URL")).Return(store.ErrDuplicateCode).Times(collisionsBeforeGrow)
+	mockStore.On("Insert", mock.Anything, mock.AnythingOfType("*models.URL")).Return(nil).Once()
+
+	url, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{})
+
+	assert.Nil(t, err)
+	assert.Len(t, url.ShortCode, testConfig.CodeLength+1)
+}
+
+func TestShortenURL_GivesUpAfterMaxAttempts(t *testing.T) {
+	mockStore := new(mocks.URLStore)
+	service := NewURLService(context.Background(), mockStore, testConfig, testGenerator)
+
+	mockStore.On("GetByOriginalURL", mock.Anything, "https://example.com", mock.Anything).Return(nil, store.ErrNotFound)
+	mockStore.On("Insert", mock.Anything, mock.AnythingOfType("*models.URL")).Return(store.ErrDuplicateCode)
+
+	url, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{})
+
+	assert.Nil(t, url)
+	assert.NotNil(t, err)
+	mockStore.AssertNumberOfCalls(t, "Insert", maxGenerateAttempts)
+}
+
+func TestShortenURL_CustomCodeInUse(t *testing.T) {
+	mockStore := new(mocks.URLStore)
+	service := NewURLService(context.Background(), mockStore, testConfig, testGenerator)
+
+	mockStore.On("GetByOriginalURL", mock.Anything, "https://example.com", mock.Anything).Return(nil, store.ErrNotFound)
+	mockStore.On("Insert", mock.Anything, mock.AnythingOfType("*models.URL")).Return(store.ErrDuplicateCode)
+
+	url, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{CustomCode: "custom"})
+
+	assert.Nil(t, url)
+	assert.ErrorIs(t, err, ErrCodeTaken)
+	mockStore.AssertNotCalled(t, "GetByCode", mock.Anything, mock.Anything)
+}
+
+func TestShortenURL_InvalidCustomCode(t *testing.T) {
+	mockStore := new(mocks.URLStore)
+	service := NewURLService(context.Background(), mockStore, testConfig, testGenerator)
+
+	for _, code := range []string{"ab", "a/b", "has space", "café", strings.Repeat("a", 65), "api", "QR", "shorten"} {
+		url, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{CustomCode: code})
+
+		assert.Nil(t, url, code)
+		assert.ErrorIs(t, err, ErrInvalid, code)
+	}
+	mockStore.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+
+	restricted := testConfig
+	restricted.CodeAlphabet = "abc"
+	service = NewURLService(context.Background(), mockStore, restricted, testGenerator)
+
+	_, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{CustomCode: "abcd"})
+	assert.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestShortenURL_ConcurrentCustomCode(t *testing.T) {
+	service := NewURLService(context.Background(), store.NewMemoryURLStore(), testConfig, testGenerator)
+
+	var (
+		wg        sync.WaitGroup
+		succeeded atomic.Int32
+		taken     atomic.Int32
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			_, err := service.ShortenURL(testAccess, fmt.Sprintf("https://example.com/%d", i), models.ShortenOptions{CustomCode: "promo"})
+			if err == nil {
+				succeeded.Add(1)
+			} else if errors.Is(err, ErrCodeTaken) {
+				taken.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), succeeded.Load())
+	assert.Equal(t, int32(19), taken.Load())
+}
+
+func TestShortenURL_StoreError(t *testing.T) {
+	mockStore := new(mocks.URLStore)
+	service := NewURLService(context.Background(), mockStore, testConfig, testGenerator)
+
+	mockStore.On("GetByOriginalURL", mock.Anything, "https://example.com", mock.Anything).Return(nil, errors.New("database error"))
+
+	url, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{})
+
+	assert.Nil(t, url)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.ErrorContains(t, err, "database error")
+}
+
+func TestGetURL_DoesNotWriteClicks(t *testing.T) {
+	mockStore := new(mocks.URLStore)
+	service := NewURLService(context.Background(), mockStore, testConfig, testGenerator)
+
+	mockStore.On("GetByCode", mock.Anything, "abc123").Return(&models.URL{
+		OriginalURL: "https://example.com",
+		ShortCode:   "abc123",
+		Clicks:      4,
+		ExpiresAt:   timePtr(time.Now().Add(time.Hour)),
+	}, nil)
+
+	url, err := service.GetURL("abc123")
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), url.Clicks)
+	mockStore.AssertExpectations(t)
+	mockStore.AssertNotCalled(t, "IncrementClicks", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetURL_NotFound(t *testing.T) {
+	mockStore := new(mocks.URLStore)
+	service := NewURLService(context.Background(), mockStore, testConfig, testGenerator)
+
+	mockStore.On("GetByCode", mock.Anything, "missing").Return(nil, store.ErrNotFound)
+
+	url, err := service.GetURL("missing")
+
+	assert.Nil(t, url)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.EqualError(t, err, "short URL not found")
+}
+
+func TestGetURL_Expired(t *testing.T) {
+	mockStore := new(mocks.URLStore)
+	service := NewURLService(context.Background(), mockStore, testConfig, testGenerator)
+
+	mockStore.On("GetByCode", mock.Anything, "old").Return(&models.URL{
+		ShortCode: "old",
+		ExpiresAt: timePtr(time.Now().Add(-time.Hour)),
+	}, nil)
+
+	url, err := service.GetURL("old")
+
+	assert.Nil(t, url)
+	assert.ErrorIs(t, err, ErrExpired)
+	mockStore.AssertNotCalled(t, "IncrementClicks", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestShortenURL_ExpiredExistingURLIsNotReused(t *testing.T) {
+	mockStore := new(mocks.URLStore)
+	service := NewURLService(context.Background(), mockStore, testConfig, testGenerator)
+
+	existing := &models.URL{OriginalURL: "https://example.com", ShortCode: "old", ExpiresAt: timePtr(time.Now().Add(-time.Hour))}
+	mockStore.On("GetByOriginalURL", mock.Anything, "https://example.com", mock.Anything).Return(existing, nil)
+	mockStore.On("Insert", mock.Anything, mock.AnythingOfType("*models.URL")).Return(nil)
+
+	url, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{})
+
+	assert.Nil(t, err)
+	assert.NotEqual(t, "old", url.ShortCode)
+}
+
+func TestShortenURL_Expiry(t *testing.T) {
+	future := time.Now().Add(48 * time.Hour)
+
+	tests := []struct {
+		name        string
+		config      config.URLShortenerConfig
+		opts        models.ShortenOptions
+		expected    time.Duration
+		neverExpire bool
+		expectError bool
+	}{
+		{
+			name:     "Default expiry from config",
+			config:   config.URLShortenerConfig{DefaultExpiry: 24 * time.Hour},
+			expected: 24 * time.Hour,
+		},
+		{
+			name:        "No default expiry never expires",
+			config:      config.URLShortenerConfig{},
+			neverExpire: true,
+		},
+		{
+			name:     "No default expiry falls back to maximum",
+			config:   config.URLShortenerConfig{MaxExpiry: 72 * time.Hour},
+			expected: 72 * time.Hour,
+		},
+		{
+			name:     "TTL",
+			config:   config.URLShortenerConfig{DefaultExpiry: 24 * time.Hour},
+			opts:     models.ShortenOptions{TTL: time.Hour},
+			expected: time.Hour,
+		},
+		{
+			name:     "Explicit expires_at",
+			config:   config.URLShortenerConfig{DefaultExpiry: 24 * time.Hour},
+			opts:     models.ShortenOptions{ExpiresAt: &future},
+			expected: 48 * time.Hour,
+		},
+		{
+			name:        "Never expires",
+			config:      config.URLShortenerConfig{DefaultExpiry: 24 * time.Hour},
+			opts:        models.ShortenOptions{NeverExpires: true},
+			neverExpire: true,
+		},
+		{
+			name:     "Default expiry clamped to maximum",
+			config:   config.URLShortenerConfig{DefaultExpiry: 365 * 24 * time.Hour, MaxExpiry: 30 * 24 * time.Hour},
+			expected: 30 * 24 * time.Hour,
+		},
+		{
+			name:        "Never expires rejected with maximum",
+			config:      config.URLShortenerConfig{MaxExpiry: 24 * time.Hour},
+			opts:        models.ShortenOptions{NeverExpires: true},
+			expectError: true,
+		},
+		{
+			name:        "TTL above maximum",
+			config:      config.URLShortenerConfig{MaxExpiry: 24 * time.Hour},
+			opts:        models.ShortenOptions{TTL: 25 * time.Hour},
+			expectError: true,
+		},
+		{
+			name:        "Negative TTL",
+			opts:        models.ShortenOptions{TTL: -time.Hour},
+			expectError: true,
+		},
+		{
+			name:        "expires_at in the past",
+			opts:        models.ShortenOptions{ExpiresAt: timePtr(time.Now().Add(-time.Hour))},
+			expectError: true,
+		},
+		{
+			name:        "Conflicting options",
+			opts:        models.ShortenOptions{TTL: time.Hour, NeverExpires: true},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewURLService(context.Background(), newShortenMockStore(), tt.config, testGenerator)
+
+			before := time.Now()
+			url, err := service.ShortenURL(testAccess, "https://example.com", tt.opts)
+
+			if tt.expectError {
+				assert.ErrorIs(t, err, ErrInvalid)
+				assert.Nil(t, url)
+				return
+			}
+
+			assert.Nil(t, err)
+			if tt.neverExpire {
+				assert.Nil(t, url.ExpiresAt)
+				return
+			}
+
+			assert.NotNil(t, url.ExpiresAt)
+			assert.WithinDuration(t, before.Add(tt.expected), *url.ExpiresAt, time.Second)
+		})
+	}
+}
+
+func TestShortenURL_ExpiryLimitInDays(t *testing.T) {
+	cfg := config.URLShortenerConfig{MaxExpiry: 30 * 24 * time.Hour}
+	service := NewURLService(context.Background(), newShortenMockStore(), cfg, testGenerator)
+
+	_, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{TTL: 31 * 24 * time.Hour})
+
+	assert.ErrorIs(t, err, ErrInvalid)
+	assert.EqualError(t, err, "links must expire within 30 days")
+}
+
+func TestGetURL_NeverExpires(t *testing.T) {
+	mockStore := new(mocks.URLStore)
+	service := NewURLService(context.Background(), mockStore, testConfig, testGenerator)
+
+	mockStore.On("GetByCode", mock.Anything, "forever").Return(&models.URL{ShortCode: "forever"}, nil)
+
+	url, err := service.GetURL("forever")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "forever", url.ShortCode)
+}
+
+func TestShortenURL_RedirectType(t *testing.T) {
+	cfg := testConfig
+	cfg.DefaultRedirectType = http.StatusFound
+	service := NewURLService(context.Background(), store.NewMemoryURLStore(), cfg, testGenerator)
+
+	byDefault, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusFound, byDefault.RedirectType)
+
+	reused, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{RedirectType: http.StatusFound})
+	assert.Nil(t, err)
+	assert.Equal(t, byDefault.ShortCode, reused.ShortCode)
+
+	permanent, err := service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{RedirectType: http.StatusPermanentRedirect})
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusPermanentRedirect, permanent.RedirectType)
+	assert.NotEqual(t, byDefault.ShortCode, permanent.ShortCode)
+
+	_, err = service.ShortenURL(testAccess, "https://example.com", models.ShortenOptions{RedirectType: http.StatusOK})
+	assert.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestGetURL_LegacyLinkUsesDefaultRedirectType(t *testing.T) {
+	mockStore := new(mocks.URLStore)
+	service := NewURLService(context.Background(), mockStore, config.URLShortenerConfig{DefaultRedirectType: http.StatusTemporaryRedirect}, testGenerator)
+
+	mockStore.On("GetByCode", mock.Anything, "legacy").Return(&models.URL{ShortCode: "legacy"}, nil)
+
+	url, err := service.GetURL("legacy")
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusTemporaryRedirect, url.RedirectType)
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+var (
+	userNamePattern      = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._@-]{0,63}$`)
+	workspaceSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+)
+
+// WorkspaceService manages users, workspaces and their memberships, and
+// resolves the role a user acts with in a workspace.
+type WorkspaceService struct {
+	ctx   context.Context
+	store store.WorkspaceStore
+}
+
+func NewWorkspaceService(ctx context.Context, workspaceStore store.WorkspaceStore) *WorkspaceService {
+	return &WorkspaceService{
+		ctx:   ctx,
+		store: workspaceStore,
+	}
+}
+
+func (service *WorkspaceService) CreateUser(name string) (*models.User, error) {
+	if !userNamePattern.MatchString(name) {
+		return nil, newError(ErrInvalid, "user names are 1 to 64 letters, digits or . _ @ -")
+	}
+
+	user := &models.User{Name: name, CreatedAt: time.Now()}
+	if err := service.store.InsertUser(service.ctx, user); err != nil {
+		if errors.Is(err, store.ErrDuplicateUser) {
+			return nil, newError(ErrConflict, "user %q already exists", name)
+		}
+
+		return nil, unavailable(err)
+	}
+
+	return user, nil
+}
+
+func (service *WorkspaceService) ListUsers() ([]models.User, error) {
+	users, err := service.store.ListUsers(service.ctx)
+	if err != nil {
+		return nil, unavailable(err)
+	}
+
+	return users, nil
+}
+
+// CreateWorkspace creates a workspace with owner as its first owner.
+func (service *WorkspaceService) CreateWorkspace(slug, name, owner string) (*models.Workspace, error) {
+	if !workspaceSlugPattern.MatchString(slug) {
+		return nil, newError(ErrInvalid, "workspace slugs are 1 to 63 lowercase letters, digits or dashes")
+	}
+
+	if err := service.requireUser(owner); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	workspace := &models.Workspace{Slug: slug, Name: name, CreatedAt: now}
+	if err := service.store.InsertWorkspace(service.ctx, workspace); err != nil {
+		if errors.Is(err, store.ErrDuplicateWorkspace) {
+			return nil, newError(ErrConflict, "workspace %q already exists", slug)
+		}
+
+		return nil, unavailable(err)
+	}
+
+	member := models.Membership{Workspace: slug, User: owner, Role: models.RoleOwner, CreatedAt: now}
+	if err := service.store.SetMember(service.ctx, member); err != nil {
+		return nil, unavailable(err)
+	}
+
+	return workspace, nil
+}
+
+func (service *WorkspaceService) ListWorkspaces() ([]models.Workspace, error) {
+	workspaces, err := service.store.ListWorkspaces(service.ctx)
+	if err != nil {
+		return nil, unavailable(err)
+	}
+
+	return workspaces, nil
+}
+
+// Access returns what user may do in workspace. Users that are not members
+// are forbidden.
+func (service *WorkspaceService) Access(user, workspace string) (models.Access, error) {
+	member, err := service.store.GetMember(service.ctx, workspace, user)
+	if err != nil {
+		if errors.Is(err, store.ErrMemberNotFound) {
+			return models.Access{}, newError(ErrForbidden, "%q is not a member of workspace %q", user, workspace)
+		}
+
+		return models.Access{}, unavailable(err)
+	}
+
+	return models.Access{User: user, Workspace: workspace, Role: member.Role}, nil
+}
+
+// ListMembers returns the members of the workspace of access.
+func (service *WorkspaceService) ListMembers(access models.Access) ([]models.Membership, error) {
+	if err := authorize(access, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	members, err := service.store.ListMembers(service.ctx, access.Workspace, "")
+	if err != nil {
+		return nil, unavailable(err)
+	}
+
+	return members, nil
+}
+
+// SetMember adds user to the workspace of access or changes their role. It
+// needs the owner role, and the last owner cannot be demoted.
+func (service *WorkspaceService) SetMember(access models.Access, user, role string) (*models.Membership, error) {
+	if err := authorize(access, models.RoleOwner); err != nil {
+		return nil, err
+	}
+
+	if !models.IsValidRole(role) {
+		return nil, newError(ErrInvalid, "role must be one of owner, editor or viewer")
+	}
+
+	if err := service.requireUser(user); err != nil {
+		return nil, err
+	}
+
+	if role != models.RoleOwner {
+		if err := service.keepAnOwner(access.Workspace, user); err != nil {
+			return nil, err
+		}
+	}
+
+	member := models.Membership{Workspace: access.Workspace, User: user, Role: role, CreatedAt: time.Now()}
+	if err := service.store.SetMember(service.ctx, member); err != nil {
+		return nil, unavailable(err)
+	}
+
+	return &member, nil
+}
+
+// RemoveMember takes user out of the workspace of access. It needs the owner
+// role, and the last owner cannot be removed.
+func (service *WorkspaceService) RemoveMember(access models.Access, user string) error {
+	if err := authorize(access, models.RoleOwner); err != nil {
+		return err
+	}
+
+	if err := service.keepAnOwner(access.Workspace, user); err != nil {
+		return err
+	}
+
+	if err := service.store.RemoveMember(service.ctx, access.Workspace, user); err != nil {
+		if errors.Is(err, store.ErrMemberNotFound) {
+			return newError(ErrNotFound, "%q is not a member of workspace %q", user, access.Workspace)
+		}
+
+		return unavailable(err)
+	}
+
+	return nil
+}
+
+func (service *WorkspaceService) requireUser(name string) error {
+	if _, err := service.store.GetUser(service.ctx, name); err != nil {
+		if errors.Is(err, store.ErrUserNotFound) {
+			return newError(ErrInvalid, "user %q does not exist", name)
+		}
+
+		return unavailable(err)
+	}
+
+	return nil
+}
+
+// keepAnOwner fails when user is the only owner of workspace, as the
+// workspace could no longer be managed without them.
+func (service *WorkspaceService) keepAnOwner(workspace, user string) error {
+	members, err := service.store.ListMembers(service.ctx, workspace, "")
+	if err != nil {
+		return unavailable(err)
+	}
+
+	owners, isOwner := 0, false
+	for _, member := range members {
+		if member.Role == models.RoleOwner {
+			owners++
+			isOwner = isOwner || member.User == user
+		}
+	}
+
+	if isOwner && owners == 1 {
+		return newError(ErrConflict, "%q is the last owner of workspace %q", user, workspace)
+	}
+
+	return nil
+}
+
+// authorize fails unless access holds at least role.
+func authorize(access models.Access, role string) error {
+	if !access.Allows(role) {
+		return newError(ErrForbidden, "this requires the %s role", role)
+	}
+
+	return nil
+}
+
+func inWorkspace(access models.Access, url *models.URL) bool {
+	return models.WorkspaceOrDefault(url.Workspace) == access.Workspace
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/store"
+)
+
+func newWorkspaceService(t *testing.T, users ...string) *WorkspaceService {
+	t.Helper()
+
+	service := NewWorkspaceService(context.Background(), store.NewMemoryWorkspaceStore())
+	for _, user := range users {
+		_, err := service.CreateUser(user)
+		require.NoError(t, err)
+	}
+
+	return service
+}
+
+func TestWorkspaceService_CreateWorkspace(t *testing.T) {
+	service := newWorkspaceService(t, "alice")
+
+	_, err := service.CreateUser("alice")
+	assert.ErrorIs(t, err, ErrConflict)
+	_, err = service.CreateUser("not a name")
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	workspace, err := service.CreateWorkspace("marketing", "Marketing", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "marketing", workspace.Slug)
+
+	_, err = service.CreateWorkspace("marketing", "", "alice")
+	assert.ErrorIs(t, err, ErrConflict)
+	_, err = service.CreateWorkspace("Sales!", "", "alice")
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = service.CreateWorkspace("sales", "", "nobody")
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	access, err := service.Access("alice", "marketing")
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleOwner, access.Role)
+
+	_, err = service.Access("alice", "sales")
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestWorkspaceService_Members(t *testing.T) {
+	service := newWorkspaceService(t, "alice", "bob", "carol")
+
+	_, err := service.CreateWorkspace("marketing", "", "alice")
+	require.NoError(t, err)
+
+	owner, err := service.Access("alice", "marketing")
+	require.NoError(t, err)
+
+	_, err = service.SetMember(owner, "bob", "admin")
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = service.SetMember(owner, "dave", models.RoleViewer)
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	_, err = service.SetMember(owner, "bob", models.RoleViewer)
+	require.NoError(t, err)
+
+	viewer, err := service.Access("bob", "marketing")
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleViewer, viewer.Role)
+
+	members, err := service.ListMembers(viewer)
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+
+	_, err = service.SetMember(viewer, "carol", models.RoleEditor)
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.ErrorIs(t, service.RemoveMember(viewer, "alice"), ErrForbidden)
+
+	require.NoError(t, service.RemoveMember(owner, "bob"))
+	assert.ErrorIs(t, service.RemoveMember(owner, "bob"), ErrNotFound)
+
+	_, err = service.Access("bob", "marketing")
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestWorkspaceService_KeepsAnOwner(t *testing.T) {
+	service := newWorkspaceService(t, "alice", "bob")
+
+	_, err := service.CreateWorkspace("marketing", "", "alice")
+	require.NoError(t, err)
+
+	owner, err := service.Access("alice", "marketing")
+	require.NoError(t, err)
+
+	_, err = service.SetMember(owner, "alice", models.RoleEditor)
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.ErrorIs(t, service.RemoveMember(owner, "alice"), ErrConflict)
+
+	_, err = service.SetMember(owner, "bob", models.RoleOwner)
+	require.NoError(t, err)
+
+	_, err = service.SetMember(owner, "alice", models.RoleEditor)
+	require.NoError(t, err)
+}
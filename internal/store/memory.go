@@ -0,0 +1,263 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryURLStore keeps URLs in process memory. It is meant for local
+// development and tests; nothing survives a restart.
+type MemoryURLStore struct {
+	mu        sync.RWMutex
+	byCode    map[string]*models.URL
+	sequences map[string]int64
+}
+
+func NewMemoryURLStore() *MemoryURLStore {
+	return &MemoryURLStore{
+		byCode:    make(map[string]*models.URL),
+		sequences: make(map[string]int64),
+	}
+}
+
+func (s *MemoryURLStore) GetByCode(ctx context.Context, shortCode string) (*models.URL, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	url, ok := s.byCode[shortCode]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	copied := *url
+	return &copied, nil
+}
+
+func (s *MemoryURLStore) GetByOriginalURL(ctx context.Context, originalURL, workspace string) (*models.URL, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var found *models.URL
+	for _, url := range s.byCode {
+		if url.OriginalURL != originalURL || url.IsDeleted() || models.WorkspaceOrDefault(url.Workspace) != workspace {
+			continue
+		}
+		if found == nil || url.CreatedAt.Before(found.CreatedAt) {
+			found = url
+		}
+	}
+
+	if found == nil {
+		return nil, ErrNotFound
+	}
+
+	copied := *found
+	return &copied, nil
+}
+
+func (s *MemoryURLStore) Insert(ctx context.Context, url *models.URL) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.byCode[url.ShortCode]; exists {
+		return ErrDuplicateCode
+	}
+
+	if url.ID.IsZero() {
+		url.ID = primitive.NewObjectID()
+	}
+
+	stored := *url
+	s.byCode[url.ShortCode] = &stored
+
+	return nil
+}
+
+func (s *MemoryURLStore) InsertMany(ctx context.Context, urls []*models.URL) ([]int, error) {
+	duplicates := []int{}
+	for i, url := range urls {
+		if err := s.Insert(ctx, url); errors.Is(err, ErrDuplicateCode) {
+			duplicates = append(duplicates, i)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	return duplicates, nil
+}
+
+func (s *MemoryURLStore) IncrementClicks(ctx context.Context, shortCode string, delta int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	url, ok := s.byCode[shortCode]
+	if !ok {
+		return ErrNotFound
+	}
+
+	url.Clicks += delta
+	url.UpdatedAt = time.Now()
+
+	return nil
+}
+
+func (s *MemoryURLStore) AddClicks(ctx context.Context, counts map[string]int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for shortCode, delta := range counts {
+		if url, ok := s.byCode[shortCode]; ok {
+			url.Clicks += delta
+			url.UpdatedAt = now
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryURLStore) Update(ctx context.Context, url *models.URL) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.byCode[url.ShortCode]
+	if !ok {
+		return ErrNotFound
+	}
+
+	stored.OriginalURL = url.OriginalURL
+	stored.ExpiresAt = url.ExpiresAt
+	stored.DeletedAt = url.DeletedAt
+	stored.RedirectType = url.RedirectType
+	stored.UpdatedAt = url.UpdatedAt
+
+	return nil
+}
+
+func (s *MemoryURLStore) Delete(ctx context.Context, shortCode string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.byCode[shortCode]; !ok {
+		return ErrNotFound
+	}
+
+	delete(s.byCode, shortCode)
+
+	return nil
+}
+
+func (s *MemoryURLStore) List(ctx context.Context, opts ListOptions) ([]models.URL, error) {
+	urls := s.filter(opts)
+
+	sort.Slice(urls, func(i, j int) bool {
+		return urls[i].CreatedAt.After(urls[j].CreatedAt)
+	})
+
+	return paginate(urls, opts), nil
+}
+
+func (s *MemoryURLStore) ScanURLs(ctx context.Context, opts ListOptions, fn func(models.URL) error) error {
+	urls := s.filter(opts)
+
+	sort.SliceStable(urls, func(i, j int) bool {
+		return urls[i].CreatedAt.Before(urls[j].CreatedAt)
+	})
+
+	for _, url := range urls {
+		if err := fn(url); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryURLStore) Count(ctx context.Context, opts ListOptions) (int64, error) {
+	return int64(len(s.filter(opts))), nil
+}
+
+func (s *MemoryURLStore) NextSequence(ctx context.Context, name string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sequences[name]++
+
+	return s.sequences[name], nil
+}
+
+func (s *MemoryURLStore) filter(opts ListOptions) []models.URL {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	search := strings.ToLower(opts.Search)
+
+	urls := make([]models.URL, 0, len(s.byCode))
+	for _, url := range s.byCode {
+		if !matchesStatus(url, opts.Status, now) {
+			continue
+		}
+		if opts.CreatedBy != "" && url.CreatedBy != opts.CreatedBy {
+			continue
+		}
+		if opts.Workspace != "" && models.WorkspaceOrDefault(url.Workspace) != opts.Workspace {
+			continue
+		}
+		if opts.CustomCode && !url.CustomCode {
+			continue
+		}
+		if !opts.CreatedFrom.IsZero() && url.CreatedAt.Before(opts.CreatedFrom) {
+			continue
+		}
+		if !opts.CreatedTo.IsZero() && !url.CreatedAt.Before(opts.CreatedTo) {
+			continue
+		}
+		if search != "" &&
+			!strings.Contains(strings.ToLower(url.OriginalURL), search) &&
+			!strings.Contains(strings.ToLower(url.ShortCode), search) {
+			continue
+		}
+
+		urls = append(urls, *url)
+	}
+
+	return urls
+}
+
+func matchesStatus(url *models.URL, status string, now time.Time) bool {
+	switch status {
+	case StatusDeleted:
+		return url.IsDeleted()
+	case StatusActive:
+		return !url.IsDeleted() && !url.IsExpired(now)
+	case StatusExpired:
+		return !url.IsDeleted() && url.IsExpired(now)
+	case StatusAll:
+		return true
+	default:
+		return !url.IsDeleted()
+	}
+}
+
+func paginate(urls []models.URL, opts ListOptions) []models.URL {
+	if opts.Offset > 0 {
+		if opts.Offset >= int64(len(urls)) {
+			return []models.URL{}
+		}
+		urls = urls[opts.Offset:]
+	}
+
+	if opts.Limit > 0 && opts.Limit < int64(len(urls)) {
+		urls = urls[:opts.Limit]
+	}
+
+	return urls
+}
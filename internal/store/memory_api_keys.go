@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MemoryAPIKeyStore struct {
+	mu       sync.RWMutex
+	byPrefix map[string]*models.APIKey
+}
+
+func NewMemoryAPIKeyStore() *MemoryAPIKeyStore {
+	return &MemoryAPIKeyStore{byPrefix: make(map[string]*models.APIKey)}
+}
+
+func (s *MemoryAPIKeyStore) InsertAPIKey(ctx context.Context, key *models.APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.byPrefix[key.Prefix]; exists {
+		return ErrDuplicateKey
+	}
+
+	if key.ID.IsZero() {
+		key.ID = primitive.NewObjectID()
+	}
+
+	stored := *key
+	s.byPrefix[key.Prefix] = &stored
+
+	return nil
+}
+
+func (s *MemoryAPIKeyStore) GetAPIKey(ctx context.Context, prefix string) (*models.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.byPrefix[prefix]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	copied := *key
+	return &copied, nil
+}
+
+func (s *MemoryAPIKeyStore) ListAPIKeys(ctx context.Context, owner string) ([]models.APIKey, error) {
+	s.mu.RLock()
+	keys := []models.APIKey{}
+	for _, key := range s.byPrefix {
+		if owner == "" || key.Owner == owner {
+			keys = append(keys, *key)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+func (s *MemoryAPIKeyStore) RevokeAPIKey(ctx context.Context, prefix string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.byPrefix[prefix]
+	if !ok {
+		return ErrKeyNotFound
+	}
+
+	if key.RevokedAt == nil {
+		key.RevokedAt = &at
+	}
+
+	return nil
+}
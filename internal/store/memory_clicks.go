@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MemoryClickStore struct {
+	mu     sync.RWMutex
+	clicks []models.Click
+	ids    map[primitive.ObjectID]bool
+	links  URLStore
+}
+
+// NewMemoryClickStore returns an empty store. links resolves the workspace of
+// clicked links for ClickFilter.Workspace; with nil, filtering by workspace
+// fails.
+func NewMemoryClickStore(links URLStore) *MemoryClickStore {
+	return &MemoryClickStore{ids: make(map[primitive.ObjectID]bool), links: links}
+}
+
+func (s *MemoryClickStore) InsertClicks(ctx context.Context, clicks []models.Click) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, click := range clicks {
+		if click.ID.IsZero() {
+			click.ID = primitive.NewObjectID()
+		}
+		if s.ids[click.ID] {
+			continue
+		}
+		s.ids[click.ID] = true
+		s.clicks = append(s.clicks, click)
+	}
+
+	return nil
+}
+
+func (s *MemoryClickStore) ScanClicks(ctx context.Context, filter ClickFilter, fn func(models.Click) error) error {
+	matched, err := s.matching(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Timestamp.Before(matched[j].Timestamp)
+	})
+
+	for _, click := range matched {
+		if err := fn(click); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryClickStore) SummarizeClicks(ctx context.Context, filter ClickFilter, opts SummaryOptions) (*ClickSummary, error) {
+	matched, err := s.matching(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket := 24 * time.Hour
+	if opts.Bucket == time.Hour {
+		bucket = time.Hour
+	}
+
+	summary := newClickSummary()
+	visitors := map[string]bool{}
+	for _, click := range matched {
+		if click.Bot {
+			summary.Bots++
+			if !opts.IncludeBots {
+				continue
+			}
+		}
+		summary.Clicks++
+
+		summary.Series[click.Timestamp.UTC().Truncate(bucket)]++
+		visitors[click.IP+"|"+click.UserAgent] = true
+		countValue(summary.Referrers, click.Referrer)
+		countValue(summary.Countries, click.Country)
+		countValue(summary.Regions, click.Region)
+		countValue(summary.Cities, cityName(click.City, click.Country))
+		countValue(summary.Browsers, click.Browser)
+		countValue(summary.OS, click.OS)
+	}
+	summary.Visitors = int64(len(visitors))
+
+	return summary, nil
+}
+
+// matching returns the stored clicks matching filter, in insertion order.
+func (s *MemoryClickStore) matching(ctx context.Context, filter ClickFilter) ([]models.Click, error) {
+	s.mu.RLock()
+	matched := []models.Click{}
+	for _, click := range s.clicks {
+		if matchesClick(click, filter) {
+			matched = append(matched, click)
+		}
+	}
+	s.mu.RUnlock()
+
+	if filter.Workspace != "" {
+		return s.inWorkspace(ctx, matched, filter.Workspace)
+	}
+
+	return matched, nil
+}
+
+func countValue(counts map[string]int64, value string) {
+	if value != "" {
+		counts[value]++
+	}
+}
+
+// inWorkspace keeps the clicks on links of workspace.
+func (s *MemoryClickStore) inWorkspace(ctx context.Context, clicks []models.Click, workspace string) ([]models.Click, error) {
+	if s.links == nil {
+		return nil, errors.New("memory click store cannot filter by workspace without a URL store")
+	}
+
+	inside := make(map[string]bool)
+	kept := clicks[:0]
+	for _, click := range clicks {
+		in, ok := inside[click.ShortCode]
+		if !ok {
+			url, err := s.links.GetByCode(ctx, click.ShortCode)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return nil, err
+			}
+			in = err == nil && models.WorkspaceOrDefault(url.Workspace) == workspace
+			inside[click.ShortCode] = in
+		}
+
+		if in {
+			kept = append(kept, click)
+		}
+	}
+
+	return kept, nil
+}
+
+func matchesClick(click models.Click, filter ClickFilter) bool {
+	if filter.ShortCode != "" && click.ShortCode != filter.ShortCode {
+		return false
+	}
+	if !filter.From.IsZero() && click.Timestamp.Before(filter.From) {
+		return false
+	}
+	if !filter.To.IsZero() && !click.Timestamp.Before(filter.To) {
+		return false
+	}
+
+	return true
+}
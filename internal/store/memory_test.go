@@ -0,0 +1,148 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+)
+
+func TestMemoryURLStore_InsertAndGet(t *testing.T) {
+	s := NewMemoryURLStore()
+	ctx := context.Background()
+
+	url := &models.URL{OriginalURL: "https://example.com", ShortCode: "abc123", CreatedAt: time.Now()}
+	assert.Nil(t, s.Insert(ctx, url))
+	assert.False(t, url.ID.IsZero())
+
+	byCode, err := s.GetByCode(ctx, "abc123")
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com", byCode.OriginalURL)
+
+	byOriginal, err := s.GetByOriginalURL(ctx, "https://example.com", models.DefaultWorkspace)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc123", byOriginal.ShortCode)
+
+	_, err = s.GetByCode(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryURLStore_DuplicateCode(t *testing.T) {
+	s := NewMemoryURLStore()
+	ctx := context.Background()
+
+	assert.Nil(t, s.Insert(ctx, &models.URL{OriginalURL: "https://a.com", ShortCode: "same"}))
+	assert.ErrorIs(t, s.Insert(ctx, &models.URL{OriginalURL: "https://b.com", ShortCode: "same"}), ErrDuplicateCode)
+}
+
+func TestMemoryURLStore_InsertMany(t *testing.T) {
+	s := NewMemoryURLStore()
+	ctx := context.Background()
+
+	assert.Nil(t, s.Insert(ctx, &models.URL{OriginalURL: "https://a.com", ShortCode: "taken"}))
+
+	duplicates, err := s.InsertMany(ctx, []*models.URL{
+		{OriginalURL: "https://b.com", ShortCode: "first"},
+		{OriginalURL: "https://c.com", ShortCode: "taken"},
+		{OriginalURL: "https://d.com", ShortCode: "first"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2}, duplicates)
+
+	first, err := s.GetByCode(ctx, "first")
+	assert.Nil(t, err)
+	assert.Equal(t, "https://b.com", first.OriginalURL)
+}
+
+func TestMemoryURLStore_ReturnsCopies(t *testing.T) {
+	s := NewMemoryURLStore()
+	ctx := context.Background()
+
+	assert.Nil(t, s.Insert(ctx, &models.URL{OriginalURL: "https://example.com", ShortCode: "abc123"}))
+
+	url, _ := s.GetByCode(ctx, "abc123")
+	url.OriginalURL = "https://changed.com"
+
+	stored, _ := s.GetByCode(ctx, "abc123")
+	assert.Equal(t, "https://example.com", stored.OriginalURL)
+}
+
+func TestMemoryURLStore_ConcurrentIncrementClicks(t *testing.T) {
+	s := NewMemoryURLStore()
+	ctx := context.Background()
+
+	assert.Nil(t, s.Insert(ctx, &models.URL{OriginalURL: "https://example.com", ShortCode: "abc123"}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = s.IncrementClicks(ctx, "abc123", 1)
+		}()
+	}
+	wg.Wait()
+
+	url, _ := s.GetByCode(ctx, "abc123")
+	assert.Equal(t, int64(100), url.Clicks)
+	assert.ErrorIs(t, s.IncrementClicks(ctx, "missing", 1), ErrNotFound)
+}
+
+func TestMemoryURLStore_DeleteAndList(t *testing.T) {
+	s := NewMemoryURLStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, s.Insert(ctx, &models.URL{
+			OriginalURL: fmt.Sprintf("https://example.com/%d", i),
+			ShortCode:   fmt.Sprintf("code%d", i),
+			CreatedAt:   now.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	assert.Nil(t, s.Delete(ctx, "code4"))
+	assert.ErrorIs(t, s.Delete(ctx, "code4"), ErrNotFound)
+
+	_, err := s.GetByOriginalURL(ctx, "https://example.com/4", models.DefaultWorkspace)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	urls, err := s.List(ctx, ListOptions{Limit: 2, Offset: 1})
+	assert.Nil(t, err)
+	assert.Len(t, urls, 2)
+	assert.Equal(t, "code2", urls[0].ShortCode)
+	assert.Equal(t, "code1", urls[1].ShortCode)
+}
+
+func TestMemoryURLStore_UpdateAndFilters(t *testing.T) {
+	s := NewMemoryURLStore()
+	ctx := context.Background()
+	now := time.Now()
+	past := now.Add(-time.Hour)
+
+	assert.Nil(t, s.Insert(ctx, &models.URL{OriginalURL: "https://example.com/a", ShortCode: "active", CreatedBy: "alice"}))
+	assert.Nil(t, s.Insert(ctx, &models.URL{OriginalURL: "https://example.com/b", ShortCode: "expired", ExpiresAt: &past}))
+	assert.Nil(t, s.Insert(ctx, &models.URL{OriginalURL: "https://other.com/c", ShortCode: "deleted", CreatedBy: "alice"}))
+
+	assert.Nil(t, s.Update(ctx, &models.URL{OriginalURL: "https://other.com/c", ShortCode: "deleted", DeletedAt: &now}))
+
+	_, err := s.GetByOriginalURL(ctx, "https://other.com/c", models.DefaultWorkspace)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	count, _ := s.Count(ctx, ListOptions{})
+	assert.Equal(t, int64(2), count)
+	count, _ = s.Count(ctx, ListOptions{Status: StatusActive})
+	assert.Equal(t, int64(1), count)
+	count, _ = s.Count(ctx, ListOptions{Status: StatusExpired})
+	assert.Equal(t, int64(1), count)
+	count, _ = s.Count(ctx, ListOptions{Status: StatusDeleted, CreatedBy: "alice"})
+	assert.Equal(t, int64(1), count)
+	count, _ = s.Count(ctx, ListOptions{Search: "EXAMPLE.COM/A"})
+	assert.Equal(t, int64(1), count)
+
+	assert.ErrorIs(t, s.Update(ctx, &models.URL{ShortCode: "missing"}), ErrNotFound)
+}
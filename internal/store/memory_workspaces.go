@@ -0,0 +1,182 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type membershipKey struct {
+	workspace string
+	user      string
+}
+
+type MemoryWorkspaceStore struct {
+	mu          sync.RWMutex
+	users       map[string]*models.User
+	workspaces  map[string]*models.Workspace
+	memberships map[membershipKey]models.Membership
+}
+
+func NewMemoryWorkspaceStore() *MemoryWorkspaceStore {
+	return &MemoryWorkspaceStore{
+		users:       make(map[string]*models.User),
+		workspaces:  make(map[string]*models.Workspace),
+		memberships: make(map[membershipKey]models.Membership),
+	}
+}
+
+func (s *MemoryWorkspaceStore) InsertUser(ctx context.Context, user *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.users[user.Name]; exists {
+		return ErrDuplicateUser
+	}
+
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+
+	stored := *user
+	s.users[user.Name] = &stored
+
+	return nil
+}
+
+func (s *MemoryWorkspaceStore) GetUser(ctx context.Context, name string) (*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[name]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	copied := *user
+	return &copied, nil
+}
+
+func (s *MemoryWorkspaceStore) ListUsers(ctx context.Context) ([]models.User, error) {
+	s.mu.RLock()
+	users := make([]models.User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, *user)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Name < users[j].Name
+	})
+
+	return users, nil
+}
+
+func (s *MemoryWorkspaceStore) InsertWorkspace(ctx context.Context, workspace *models.Workspace) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.workspaces[workspace.Slug]; exists {
+		return ErrDuplicateWorkspace
+	}
+
+	if workspace.ID.IsZero() {
+		workspace.ID = primitive.NewObjectID()
+	}
+
+	stored := *workspace
+	s.workspaces[workspace.Slug] = &stored
+
+	return nil
+}
+
+func (s *MemoryWorkspaceStore) GetWorkspace(ctx context.Context, slug string) (*models.Workspace, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	workspace, ok := s.workspaces[slug]
+	if !ok {
+		return nil, ErrWorkspaceNotFound
+	}
+
+	copied := *workspace
+	return &copied, nil
+}
+
+func (s *MemoryWorkspaceStore) ListWorkspaces(ctx context.Context) ([]models.Workspace, error) {
+	s.mu.RLock()
+	workspaces := make([]models.Workspace, 0, len(s.workspaces))
+	for _, workspace := range s.workspaces {
+		workspaces = append(workspaces, *workspace)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].Slug < workspaces[j].Slug
+	})
+
+	return workspaces, nil
+}
+
+func (s *MemoryWorkspaceStore) SetMember(ctx context.Context, member models.Membership) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := membershipKey{workspace: member.Workspace, user: member.User}
+	if existing, ok := s.memberships[key]; ok {
+		member.CreatedAt = existing.CreatedAt
+	}
+	s.memberships[key] = member
+
+	return nil
+}
+
+func (s *MemoryWorkspaceStore) GetMember(ctx context.Context, workspace, user string) (*models.Membership, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	member, ok := s.memberships[membershipKey{workspace: workspace, user: user}]
+	if !ok {
+		return nil, ErrMemberNotFound
+	}
+
+	return &member, nil
+}
+
+func (s *MemoryWorkspaceStore) ListMembers(ctx context.Context, workspace, user string) ([]models.Membership, error) {
+	s.mu.RLock()
+	members := []models.Membership{}
+	for _, member := range s.memberships {
+		if (workspace == "" || member.Workspace == workspace) && (user == "" || member.User == user) {
+			members = append(members, member)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Workspace != members[j].Workspace {
+			return members[i].Workspace < members[j].Workspace
+		}
+
+		return members[i].User < members[j].User
+	})
+
+	return members, nil
+}
+
+func (s *MemoryWorkspaceStore) RemoveMember(ctx context.Context, workspace, user string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := membershipKey{workspace: workspace, user: user}
+	if _, ok := s.memberships[key]; !ok {
+		return ErrMemberNotFound
+	}
+
+	delete(s.memberships, key)
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrate applies every embedded migration for the dialect that has not been
+// recorded in schema_migrations yet. Each migration runs in its own
+// transaction so a failure leaves earlier migrations applied.
+func migrate(ctx context.Context, db *sql.DB, dialect string) error {
+	createTable := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL
+	)`
+	if _, err := db.ExecContext(ctx, createTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	dir := path.Join("migrations", dialect)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return fmt.Errorf("no migrations for dialect %q: %w", dialect, err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".sql") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		version := strings.TrimSuffix(name, ".sql")
+
+		var applied int
+		err := db.QueryRowContext(ctx, rebind(dialect, "SELECT COUNT(*) FROM schema_migrations WHERE version = ?"), version).Scan(&applied)
+		if err != nil {
+			return err
+		}
+		if applied > 0 {
+			continue
+		}
+
+		contents, err := migrationFiles.ReadFile(path.Join(dir, name))
+		if err != nil {
+			return err
+		}
+
+		if err := applyMigration(ctx, db, dialect, version, string(contents)); err != nil {
+			return fmt.Errorf("migration %s failed: %w", version, err)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, dialect, version, contents string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	for _, statement := range strings.Split(contents, ";") {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	insert := rebind(dialect, "INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)")
+	if _, err := tx.ExecContext(ctx, insert, version, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
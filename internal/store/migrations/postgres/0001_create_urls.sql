@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS urls (
+    id           TEXT PRIMARY KEY,
+    original_url TEXT NOT NULL,
+    short_code   TEXT NOT NULL UNIQUE,
+    clicks       BIGINT NOT NULL DEFAULT 0,
+    expires_at   TIMESTAMPTZ,
+    created_by   TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL,
+    updated_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_urls_original_url ON urls (original_url);
+CREATE INDEX IF NOT EXISTS idx_urls_expires_at ON urls (expires_at);
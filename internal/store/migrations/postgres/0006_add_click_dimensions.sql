@@ -0,0 +1,3 @@
+ALTER TABLE clicks ADD COLUMN country TEXT NOT NULL DEFAULT '';
+ALTER TABLE clicks ADD COLUMN browser TEXT NOT NULL DEFAULT '';
+ALTER TABLE clicks ADD COLUMN os TEXT NOT NULL DEFAULT '';
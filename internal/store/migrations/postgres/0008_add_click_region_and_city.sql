@@ -0,0 +1,2 @@
+ALTER TABLE clicks ADD COLUMN region TEXT NOT NULL DEFAULT '';
+ALTER TABLE clicks ADD COLUMN city TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS users (
+    id         TEXT PRIMARY KEY,
+    name       TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS workspaces (
+    id         TEXT PRIMARY KEY,
+    slug       TEXT NOT NULL UNIQUE,
+    name       TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS memberships (
+    workspace  TEXT NOT NULL,
+    user_name  TEXT NOT NULL,
+    role       TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (workspace, user_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_memberships_user_name ON memberships (user_name);
+
+ALTER TABLE urls ADD COLUMN workspace TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE api_keys ADD COLUMN workspace TEXT NOT NULL DEFAULT 'default';
+
+CREATE INDEX IF NOT EXISTS idx_urls_workspace_created_at ON urls (workspace, created_at);
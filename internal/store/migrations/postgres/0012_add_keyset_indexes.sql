@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_clicks_clicked_at_id ON clicks (clicked_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_urls_created_at_id ON urls (created_at, id);
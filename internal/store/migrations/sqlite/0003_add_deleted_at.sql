@@ -0,0 +1,3 @@
+ALTER TABLE urls ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_urls_created_at ON urls (created_at);
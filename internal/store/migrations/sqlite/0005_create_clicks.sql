@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS clicks (
+    id              TEXT PRIMARY KEY,
+    short_code      TEXT NOT NULL,
+    clicked_at      TIMESTAMP NOT NULL,
+    referrer        TEXT NOT NULL DEFAULT '',
+    user_agent      TEXT NOT NULL DEFAULT '',
+    ip              TEXT NOT NULL DEFAULT '',
+    accept_language TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_clicks_short_code_clicked_at ON clicks (short_code, clicked_at);
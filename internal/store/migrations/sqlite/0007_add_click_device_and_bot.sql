@@ -0,0 +1,2 @@
+ALTER TABLE clicks ADD COLUMN device TEXT NOT NULL DEFAULT '';
+ALTER TABLE clicks ADD COLUMN bot BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+ALTER TABLE urls ADD COLUMN custom_code BOOLEAN NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_urls_created_by_created_at ON urls (created_by, created_at);
@@ -0,0 +1,345 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoURLStore struct {
+	collection *mongo.Collection
+	counters   *mongo.Collection
+}
+
+func NewMongoURLStore(ctx context.Context, db *mongo.Database) (*MongoURLStore, error) {
+	collection := db.Collection("urls")
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.M{"short_code": 1},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.M{"original_url": 1},
+		},
+		{
+			Keys: bson.D{{Key: "workspace", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "created_by", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	}
+	if _, err := collection.Indexes().CreateMany(ctx, indexModels); err != nil {
+		return nil, fmt.Errorf("failed to create indexes: %w", err)
+	}
+
+	return &MongoURLStore{
+		collection: collection,
+		counters:   db.Collection("counters"),
+	}, nil
+}
+
+func (s *MongoURLStore) GetByCode(ctx context.Context, shortCode string) (*models.URL, error) {
+	return s.findOne(ctx, bson.M{"short_code": shortCode})
+}
+
+func (s *MongoURLStore) GetByOriginalURL(ctx context.Context, originalURL, workspace string) (*models.URL, error) {
+	return s.findOne(ctx, bson.M{"original_url": originalURL, "deleted_at": nil, "workspace": workspaceFilter(workspace)})
+}
+
+func (s *MongoURLStore) Insert(ctx context.Context, url *models.URL) error {
+	result, err := s.collection.InsertOne(ctx, url)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrDuplicateCode
+		}
+
+		return err
+	}
+
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		url.ID = id
+	}
+
+	return nil
+}
+
+// InsertMany writes urls in one unordered bulk insert, so a taken code only
+// fails its own document.
+func (s *MongoURLStore) InsertMany(ctx context.Context, urls []*models.URL) ([]int, error) {
+	if len(urls) == 0 {
+		return []int{}, nil
+	}
+
+	documents := make([]any, 0, len(urls))
+	for _, url := range urls {
+		if url.ID.IsZero() {
+			url.ID = primitive.NewObjectID()
+		}
+		documents = append(documents, url)
+	}
+
+	duplicates := []int{}
+	_, err := s.collection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+		for _, writeErr := range bulkErr.WriteErrors {
+			if !mongo.IsDuplicateKeyError(writeErr) {
+				return nil, err
+			}
+			duplicates = append(duplicates, writeErr.Index)
+		}
+		sort.Ints(duplicates)
+
+		return duplicates, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return duplicates, nil
+}
+
+func (s *MongoURLStore) IncrementClicks(ctx context.Context, shortCode string, delta int64) error {
+	result, err := s.collection.UpdateOne(
+		ctx,
+		bson.M{"short_code": shortCode},
+		bson.M{"$inc": bson.M{"clicks": delta}, "$set": bson.M{"updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *MongoURLStore) AddClicks(ctx context.Context, counts map[string]int64) error {
+	return addMongoClicks(ctx, s.collection, counts)
+}
+
+func addMongoClicks(ctx context.Context, urls *mongo.Collection, counts map[string]int64) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	writes := make([]mongo.WriteModel, 0, len(counts))
+	for shortCode, delta := range counts {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"short_code": shortCode}).
+			SetUpdate(bson.M{"$inc": bson.M{"clicks": delta}, "$set": bson.M{"updated_at": now}}))
+	}
+
+	_, err := urls.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+
+	return err
+}
+
+func (s *MongoURLStore) Update(ctx context.Context, url *models.URL) error {
+	set := bson.M{
+		"original_url":  url.OriginalURL,
+		"redirect_type": url.RedirectType,
+		"updated_at":    url.UpdatedAt,
+	}
+	unset := bson.M{}
+
+	if url.ExpiresAt != nil {
+		set["expires_at"] = url.ExpiresAt
+	} else {
+		unset["expires_at"] = ""
+	}
+	if url.DeletedAt != nil {
+		set["deleted_at"] = url.DeletedAt
+	} else {
+		unset["deleted_at"] = ""
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	result, err := s.collection.UpdateOne(ctx, bson.M{"short_code": url.ShortCode}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *MongoURLStore) Delete(ctx context.Context, shortCode string) error {
+	result, err := s.collection.DeleteOne(ctx, bson.M{"short_code": shortCode})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *MongoURLStore) List(ctx context.Context, opts ListOptions) ([]models.URL, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if opts.Limit > 0 {
+		findOptions.SetLimit(opts.Limit)
+	}
+	if opts.Offset > 0 {
+		findOptions.SetSkip(opts.Offset)
+	}
+
+	cursor, err := s.collection.Find(ctx, listFilter(opts), findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	urls := []models.URL{}
+	if err := cursor.All(ctx, &urls); err != nil {
+		return nil, err
+	}
+
+	return urls, nil
+}
+
+func (s *MongoURLStore) ScanURLs(ctx context.Context, opts ListOptions, fn func(models.URL) error) error {
+	cursor, err := s.collection.Find(ctx, listFilter(opts), options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var url models.URL
+		if err := cursor.Decode(&url); err != nil {
+			return err
+		}
+
+		if err := fn(url); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+func (s *MongoURLStore) Count(ctx context.Context, opts ListOptions) (int64, error) {
+	return s.collection.CountDocuments(ctx, listFilter(opts))
+}
+
+func (s *MongoURLStore) NextSequence(ctx context.Context, name string) (int64, error) {
+	var counter struct {
+		Value int64 `bson:"value"`
+	}
+
+	err := s.counters.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": name},
+		bson.M{"$inc": bson.M{"value": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return 0, err
+	}
+
+	return counter.Value, nil
+}
+
+func (s *MongoURLStore) findOne(ctx context.Context, filter bson.M) (*models.URL, error) {
+	var url models.URL
+
+	err := s.collection.FindOne(ctx, filter).Decode(&url)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return &url, nil
+}
+
+func listFilter(opts ListOptions) bson.M {
+	now := time.Now()
+	conditions := []bson.M{}
+
+	switch opts.Status {
+	case StatusDeleted:
+		conditions = append(conditions, bson.M{"deleted_at": bson.M{"$ne": nil}})
+	case StatusActive:
+		conditions = append(conditions,
+			bson.M{"deleted_at": nil},
+			bson.M{"$or": []bson.M{{"expires_at": nil}, {"expires_at": bson.M{"$gte": now}}}},
+		)
+	case StatusExpired:
+		conditions = append(conditions, bson.M{"deleted_at": nil}, bson.M{"expires_at": bson.M{"$lt": now}})
+	case StatusAll:
+	default:
+		conditions = append(conditions, bson.M{"deleted_at": nil})
+	}
+
+	if opts.CreatedBy != "" {
+		conditions = append(conditions, bson.M{"created_by": opts.CreatedBy})
+	}
+
+	if opts.Workspace != "" {
+		conditions = append(conditions, bson.M{"workspace": workspaceFilter(opts.Workspace)})
+	}
+
+	if opts.CustomCode {
+		conditions = append(conditions, bson.M{"custom_code": true})
+	}
+
+	if !opts.CreatedFrom.IsZero() {
+		conditions = append(conditions, bson.M{"created_at": bson.M{"$gte": opts.CreatedFrom}})
+	}
+
+	if !opts.CreatedTo.IsZero() {
+		conditions = append(conditions, bson.M{"created_at": bson.M{"$lt": opts.CreatedTo}})
+	}
+
+	if opts.Search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(opts.Search), Options: "i"}
+		conditions = append(conditions, bson.M{"$or": []bson.M{
+			{"original_url": pattern},
+			{"short_code": pattern},
+		}})
+	}
+
+	if len(conditions) == 0 {
+		return bson.M{}
+	}
+
+	return bson.M{"$and": conditions}
+}
+
+// workspaceFilter matches the documents of a workspace. Documents stored
+// before workspaces existed have no workspace field and belong to the
+// default one.
+func workspaceFilter(workspace string) any {
+	if workspace == models.DefaultWorkspace {
+		return bson.M{"$in": bson.A{workspace, nil}}
+	}
+
+	return workspace
+}
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoAPIKeyStore struct {
+	collection *mongo.Collection
+}
+
+func NewMongoAPIKeyStore(ctx context.Context, db *mongo.Database) (*MongoAPIKeyStore, error) {
+	collection := db.Collection("api_keys")
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.M{"prefix": 1},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.M{"owner": 1},
+		},
+	}
+	if _, err := collection.Indexes().CreateMany(ctx, indexModels); err != nil {
+		return nil, fmt.Errorf("failed to create api key indexes: %w", err)
+	}
+
+	return &MongoAPIKeyStore{collection: collection}, nil
+}
+
+func (s *MongoAPIKeyStore) InsertAPIKey(ctx context.Context, key *models.APIKey) error {
+	result, err := s.collection.InsertOne(ctx, key)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrDuplicateKey
+		}
+
+		return err
+	}
+
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		key.ID = id
+	}
+
+	return nil
+}
+
+func (s *MongoAPIKeyStore) GetAPIKey(ctx context.Context, prefix string) (*models.APIKey, error) {
+	var key models.APIKey
+
+	err := s.collection.FindOne(ctx, bson.M{"prefix": prefix}).Decode(&key)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrKeyNotFound
+		}
+
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (s *MongoAPIKeyStore) ListAPIKeys(ctx context.Context, owner string) ([]models.APIKey, error) {
+	filter := bson.M{}
+	if owner != "" {
+		filter["owner"] = owner
+	}
+
+	cursor, err := s.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []models.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (s *MongoAPIKeyStore) RevokeAPIKey(ctx context.Context, prefix string, at time.Time) error {
+	// The pipeline update keeps the first revocation time when a key is
+	// revoked twice.
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"revoked_at": bson.M{"$ifNull": bson.A{"$revoked_at", at}}}}},
+	}
+
+	result, err := s.collection.UpdateOne(ctx, bson.M{"prefix": prefix}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrKeyNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,387 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoClickStore struct {
+	collection *mongo.Collection
+	urls       *mongo.Collection
+	// transactions tells whether the deployment, a replica set or a sharded
+	// cluster, supports multi-document transactions.
+	transactions bool
+}
+
+func NewMongoClickStore(ctx context.Context, db *mongo.Database) (*MongoClickStore, error) {
+	collection := db.Collection("clicks")
+
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: "short_code", Value: 1}, {Key: "timestamp", Value: 1}},
+	}
+	if _, err := collection.Indexes().CreateOne(ctx, indexModel); err != nil {
+		return nil, fmt.Errorf("failed to create click indexes: %w", err)
+	}
+
+	return &MongoClickStore{
+		collection:   collection,
+		urls:         db.Collection("urls"),
+		transactions: supportsTransactions(ctx, db),
+	}, nil
+}
+
+// supportsTransactions asks the server whether it is a replica set member or
+// a mongos router. Servers too old to answer are assumed standalone.
+func supportsTransactions(ctx context.Context, db *mongo.Database) bool {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := db.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false
+	}
+
+	return hello.SetName != "" || hello.Msg == "isdbgrid"
+}
+
+func (s *MongoClickStore) InsertClicks(ctx context.Context, clicks []models.Click) error {
+	_, err := s.insertClicks(ctx, clicks)
+
+	return err
+}
+
+// WriteClicks stores the clicks and counts the new ones. On a replica set or
+// sharded cluster both happen in one transaction, like the SQL stores. A
+// standalone server has no transactions, so the clicks are stored first and
+// counted after: if counting fails, the error reports it and the stored
+// clicks stay uncounted for good, since writing them again finds them
+// already stored.
+func (s *MongoClickStore) WriteClicks(ctx context.Context, clicks []models.Click, counted func(models.Click) bool) ([]int, error) {
+	if s.transactions {
+		return s.writeClicksInTransaction(ctx, clicks, counted)
+	}
+
+	duplicates, err := s.insertClicks(ctx, clicks)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := addMongoClicks(ctx, s.urls, countNewClicks(clicks, duplicates, counted)); err != nil {
+		return nil, fmt.Errorf("clicks stored but not counted: %w", err)
+	}
+
+	return duplicates, nil
+}
+
+// writeClicksInTransaction looks up the clicks already stored instead of
+// relying on duplicate key errors, which would abort the transaction.
+func (s *MongoClickStore) writeClicksInTransaction(ctx context.Context, clicks []models.Click, counted func(models.Click) bool) ([]int, error) {
+	session, err := s.collection.Database().Client().StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	documents := make([]models.Click, len(clicks))
+	ids := make([]primitive.ObjectID, 0, len(clicks))
+	for i, click := range clicks {
+		if click.ID.IsZero() {
+			click.ID = primitive.NewObjectID()
+		}
+		documents[i] = click
+		ids = append(ids, click.ID)
+	}
+
+	var duplicates []int
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (any, error) {
+		stored, err := s.storedIDs(sc, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		duplicates = []int{}
+		var fresh []any
+		for i, click := range documents {
+			if stored[click.ID] {
+				duplicates = append(duplicates, i)
+				continue
+			}
+			stored[click.ID] = true
+			fresh = append(fresh, click)
+		}
+
+		if len(fresh) > 0 {
+			if _, err := s.collection.InsertMany(sc, fresh); err != nil {
+				return nil, err
+			}
+		}
+
+		return nil, addMongoClicks(sc, s.urls, countNewClicks(clicks, duplicates, counted))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return duplicates, nil
+}
+
+func (s *MongoClickStore) storedIDs(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	stored := make(map[primitive.ObjectID]bool)
+	if len(ids) == 0 {
+		return stored, nil
+	}
+
+	cursor, err := s.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var document struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&document); err != nil {
+			return nil, err
+		}
+		stored[document.ID] = true
+	}
+
+	return stored, cursor.Err()
+}
+
+// countNewClicks groups by link the clicks that were not skipped as
+// duplicates and that counted accepts.
+func countNewClicks(clicks []models.Click, duplicates []int, counted func(models.Click) bool) map[string]int64 {
+	skipped := make(map[int]bool, len(duplicates))
+	for _, i := range duplicates {
+		skipped[i] = true
+	}
+
+	counts := make(map[string]int64)
+	for i, click := range clicks {
+		if !skipped[i] && counted(click) {
+			counts[click.ShortCode]++
+		}
+	}
+
+	return counts
+}
+
+// insertClicks inserts clicks, skipping those whose ID is already stored, and
+// returns the indexes of the skipped ones in ascending order.
+func (s *MongoClickStore) insertClicks(ctx context.Context, clicks []models.Click) ([]int, error) {
+	duplicates := []int{}
+	if len(clicks) == 0 {
+		return duplicates, nil
+	}
+
+	documents := make([]any, len(clicks))
+	for i, click := range clicks {
+		if click.ID.IsZero() {
+			click.ID = primitive.NewObjectID()
+		}
+		documents[i] = click
+	}
+
+	_, err := s.collection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+		for _, writeErr := range bulkErr.WriteErrors {
+			if !mongo.IsDuplicateKeyError(writeErr) {
+				return nil, err
+			}
+			duplicates = append(duplicates, writeErr.Index)
+		}
+		sort.Ints(duplicates)
+
+		return duplicates, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return duplicates, nil
+}
+
+func (s *MongoClickStore) ScanClicks(ctx context.Context, filter ClickFilter, fn func(models.Click) error) error {
+	pipeline := matchClicks(filter, true)
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var click models.Click
+		if err := cursor.Decode(&click); err != nil {
+			return err
+		}
+
+		if err := fn(click); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// SummarizeClicks groups the clicks in one aggregation, a $facet running the
+// totals, the series and each dimension over the matching clicks.
+func (s *MongoClickStore) SummarizeClicks(ctx context.Context, filter ClickFilter, opts SummaryOptions) (*ClickSummary, error) {
+	format := "%Y-%m-%dT00:00:00Z"
+	if opts.Bucket == time.Hour {
+		format = "%Y-%m-%dT%H:00:00Z"
+	}
+
+	counted := bson.A{}
+	if !opts.IncludeBots {
+		counted = append(counted, bson.M{"$match": bson.M{"bot": bson.M{"$ne": true}}})
+	}
+	countBy := func(key any) bson.A {
+		return append(slices.Clone(counted), bson.M{"$group": bson.M{"_id": key, "n": bson.M{"$sum": 1}}})
+	}
+	field := func(name string) bson.M {
+		return bson.M{"$ifNull": bson.A{"$" + name, ""}}
+	}
+
+	facets := bson.M{
+		"totals": bson.A{bson.M{"$group": bson.M{
+			"_id":  nil,
+			"n":    bson.M{"$sum": 1},
+			"bots": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$bot", true}}, 1, 0}}},
+		}}},
+		"visitors":  append(countBy(bson.M{"ip": field("ip"), "user_agent": field("user_agent")}), bson.M{"$count": "n"}),
+		"series":    countBy(bson.M{"$dateToString": bson.M{"format": format, "date": "$timestamp", "timezone": "UTC"}}),
+		"referrers": countBy(field("referrer")),
+		"countries": countBy(field("country")),
+		"regions":   countBy(field("region")),
+		"cities": countBy(bson.M{"$cond": bson.A{
+			bson.M{"$or": bson.A{bson.M{"$eq": bson.A{field("city"), ""}}, bson.M{"$eq": bson.A{field("country"), ""}}}},
+			field("city"),
+			bson.M{"$concat": bson.A{"$city", ", ", "$country"}},
+		}}),
+		"browsers": countBy(field("browser")),
+		"os":       countBy(field("os")),
+	}
+
+	pipeline := append(matchClicks(filter, false), bson.D{{Key: "$facet", Value: facets}})
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	type group struct {
+		ID   string `bson:"_id"`
+		N    int64  `bson:"n"`
+		Bots int64  `bson:"bots"`
+	}
+	var result struct {
+		Totals    []group `bson:"totals"`
+		Visitors  []group `bson:"visitors"`
+		Series    []group `bson:"series"`
+		Referrers []group `bson:"referrers"`
+		Countries []group `bson:"countries"`
+		Regions   []group `bson:"regions"`
+		Cities    []group `bson:"cities"`
+		Browsers  []group `bson:"browsers"`
+		OS        []group `bson:"os"`
+	}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&result); err != nil {
+			return nil, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	summary := newClickSummary()
+	if len(result.Totals) > 0 {
+		summary.Clicks = result.Totals[0].N
+		summary.Bots = result.Totals[0].Bots
+		if !opts.IncludeBots {
+			summary.Clicks -= summary.Bots
+		}
+	}
+	if len(result.Visitors) > 0 {
+		summary.Visitors = result.Visitors[0].N
+	}
+
+	for _, bucket := range result.Series {
+		start, err := time.Parse(time.RFC3339, bucket.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid click bucket %q: %w", bucket.ID, err)
+		}
+		summary.Series[start] = bucket.N
+	}
+
+	for _, dimension := range []struct {
+		groups []group
+		counts map[string]int64
+	}{
+		{result.Referrers, summary.Referrers},
+		{result.Countries, summary.Countries},
+		{result.Regions, summary.Regions},
+		{result.Cities, summary.Cities},
+		{result.Browsers, summary.Browsers},
+		{result.OS, summary.OS},
+	} {
+		for _, group := range dimension.groups {
+			if group.ID != "" {
+				dimension.counts[group.ID] = group.N
+			}
+		}
+	}
+
+	return summary, nil
+}
+
+// matchClicks returns a pipeline selecting the clicks matching filter, in
+// timestamp order when sorted is set.
+func matchClicks(filter ClickFilter, sorted bool) mongo.Pipeline {
+	query := bson.M{}
+	if filter.ShortCode != "" {
+		query["short_code"] = filter.ShortCode
+	}
+
+	timestamp := bson.M{}
+	if !filter.From.IsZero() {
+		timestamp["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		timestamp["$lt"] = filter.To
+	}
+	if len(timestamp) > 0 {
+		query["timestamp"] = timestamp
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: query}}}
+	if sorted {
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: bson.M{"timestamp": 1}}})
+	}
+	if filter.Workspace != "" {
+		// Clicks do not carry their workspace; join each one to its link.
+		pipeline = append(pipeline,
+			bson.D{{Key: "$lookup", Value: bson.M{"from": "urls", "localField": "short_code", "foreignField": "short_code", "as": "link"}}},
+			bson.D{{Key: "$match", Value: bson.M{"link.0": bson.M{"$exists": true}, "link.workspace": workspaceFilter(filter.Workspace)}}},
+			bson.D{{Key: "$project", Value: bson.M{"link": 0}}},
+		)
+	}
+
+	return pipeline
+}
@@ -0,0 +1,185 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoWorkspaceStore struct {
+	users       *mongo.Collection
+	workspaces  *mongo.Collection
+	memberships *mongo.Collection
+}
+
+func NewMongoWorkspaceStore(ctx context.Context, db *mongo.Database) (*MongoWorkspaceStore, error) {
+	s := &MongoWorkspaceStore{
+		users:       db.Collection("users"),
+		workspaces:  db.Collection("workspaces"),
+		memberships: db.Collection("memberships"),
+	}
+
+	indexes := []struct {
+		collection *mongo.Collection
+		models     []mongo.IndexModel
+	}{
+		{s.users, []mongo.IndexModel{{Keys: bson.M{"name": 1}, Options: options.Index().SetUnique(true)}}},
+		{s.workspaces, []mongo.IndexModel{{Keys: bson.M{"slug": 1}, Options: options.Index().SetUnique(true)}}},
+		{s.memberships, []mongo.IndexModel{
+			{Keys: bson.D{{Key: "workspace", Value: 1}, {Key: "user", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.M{"user": 1}},
+		}},
+	}
+	for _, index := range indexes {
+		if _, err := index.collection.Indexes().CreateMany(ctx, index.models); err != nil {
+			return nil, fmt.Errorf("failed to create %s indexes: %w", index.collection.Name(), err)
+		}
+	}
+
+	return s, nil
+}
+
+func (s *MongoWorkspaceStore) InsertUser(ctx context.Context, user *models.User) error {
+	result, err := s.users.InsertOne(ctx, user)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrDuplicateUser
+		}
+
+		return err
+	}
+
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		user.ID = id
+	}
+
+	return nil
+}
+
+func (s *MongoWorkspaceStore) GetUser(ctx context.Context, name string) (*models.User, error) {
+	var user models.User
+	if err := findOne(ctx, s.users, bson.M{"name": name}, &user, ErrUserNotFound); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (s *MongoWorkspaceStore) ListUsers(ctx context.Context) ([]models.User, error) {
+	users := []models.User{}
+	if err := findAll(ctx, s.users, bson.M{}, bson.M{"name": 1}, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (s *MongoWorkspaceStore) InsertWorkspace(ctx context.Context, workspace *models.Workspace) error {
+	result, err := s.workspaces.InsertOne(ctx, workspace)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrDuplicateWorkspace
+		}
+
+		return err
+	}
+
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		workspace.ID = id
+	}
+
+	return nil
+}
+
+func (s *MongoWorkspaceStore) GetWorkspace(ctx context.Context, slug string) (*models.Workspace, error) {
+	var workspace models.Workspace
+	if err := findOne(ctx, s.workspaces, bson.M{"slug": slug}, &workspace, ErrWorkspaceNotFound); err != nil {
+		return nil, err
+	}
+
+	return &workspace, nil
+}
+
+func (s *MongoWorkspaceStore) ListWorkspaces(ctx context.Context) ([]models.Workspace, error) {
+	workspaces := []models.Workspace{}
+	if err := findAll(ctx, s.workspaces, bson.M{}, bson.M{"slug": 1}, &workspaces); err != nil {
+		return nil, err
+	}
+
+	return workspaces, nil
+}
+
+func (s *MongoWorkspaceStore) SetMember(ctx context.Context, member models.Membership) error {
+	_, err := s.memberships.UpdateOne(
+		ctx,
+		bson.M{"workspace": member.Workspace, "user": member.User},
+		bson.M{"$set": bson.M{"role": member.Role}, "$setOnInsert": bson.M{"created_at": member.CreatedAt}},
+		options.Update().SetUpsert(true),
+	)
+
+	return err
+}
+
+func (s *MongoWorkspaceStore) GetMember(ctx context.Context, workspace, user string) (*models.Membership, error) {
+	var member models.Membership
+	if err := findOne(ctx, s.memberships, bson.M{"workspace": workspace, "user": user}, &member, ErrMemberNotFound); err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+func (s *MongoWorkspaceStore) ListMembers(ctx context.Context, workspace, user string) ([]models.Membership, error) {
+	filter := bson.M{}
+	if workspace != "" {
+		filter["workspace"] = workspace
+	}
+	if user != "" {
+		filter["user"] = user
+	}
+
+	members := []models.Membership{}
+	if err := findAll(ctx, s.memberships, filter, bson.D{{Key: "workspace", Value: 1}, {Key: "user", Value: 1}}, &members); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (s *MongoWorkspaceStore) RemoveMember(ctx context.Context, workspace, user string) error {
+	result, err := s.memberships.DeleteOne(ctx, bson.M{"workspace": workspace, "user": user})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrMemberNotFound
+	}
+
+	return nil
+}
+
+func findOne(ctx context.Context, collection *mongo.Collection, filter bson.M, result any, notFound error) error {
+	err := collection.FindOne(ctx, filter).Decode(result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return notFound
+	}
+
+	return err
+}
+
+func findAll(ctx context.Context, collection *mongo.Collection, filter, sort any, results any) error {
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(sort))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	return cursor.All(ctx, results)
+}
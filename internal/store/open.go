@@ -0,0 +1,119 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/config"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	BackendMongo  = "mongo"
+	BackendMemory = "memory"
+	BackendSQL    = "sql"
+)
+
+// Stores groups the stores of one backend so they share a connection.
+type Stores struct {
+	URLs       URLStore
+	Clicks     ClickStore
+	APIKeys    APIKeyStore
+	Workspaces WorkspaceStore
+
+	close func(context.Context) error
+}
+
+// Close releases the underlying connection and must be called on shutdown.
+func (s *Stores) Close(ctx context.Context) error {
+	if s.close == nil {
+		return nil
+	}
+
+	return s.close(ctx)
+}
+
+// Open builds the stores of the backend selected by cfg.Storage.Backend.
+func Open(ctx context.Context, cfg *config.Config) (*Stores, error) {
+	switch cfg.Storage.Backend {
+	case BackendMemory:
+		log.Println("Using in-memory storage, data will not survive a restart")
+		urls := NewMemoryURLStore()
+		return &Stores{
+			URLs:       urls,
+			Clicks:     NewMemoryClickStore(urls),
+			APIKeys:    NewMemoryAPIKeyStore(),
+			Workspaces: NewMemoryWorkspaceStore(),
+		}, nil
+	case BackendSQL:
+		db, err := OpenSQL(ctx, cfg.SQL.Dialect, cfg.SQL.DSN)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Connected to %s database successfully", cfg.SQL.Dialect)
+
+		return &Stores{
+			URLs:       NewSQLURLStore(db, cfg.SQL.Dialect),
+			Clicks:     NewSQLClickStore(db, cfg.SQL.Dialect),
+			APIKeys:    NewSQLAPIKeyStore(db, cfg.SQL.Dialect),
+			Workspaces: NewSQLWorkspaceStore(db, cfg.SQL.Dialect),
+			close:      func(context.Context) error { return db.Close() },
+		}, nil
+	case BackendMongo, "":
+		return openMongo(ctx, cfg.MongoDB)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Storage.Backend)
+	}
+}
+
+func openMongo(ctx context.Context, cfg config.MongoDBConfig) (*Stores, error) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.URI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+
+	if err := client.Ping(ctx, nil); err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+	log.Println("Connected to MongoDB successfully")
+
+	db := client.Database(cfg.Database)
+
+	urlStore, err := NewMongoURLStore(ctx, db)
+	if err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, err
+	}
+
+	clickStore, err := NewMongoClickStore(ctx, db)
+	if err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, err
+	}
+
+	apiKeyStore, err := NewMongoAPIKeyStore(ctx, db)
+	if err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, err
+	}
+
+	workspaceStore, err := NewMongoWorkspaceStore(ctx, db)
+	if err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, err
+	}
+
+	return &Stores{
+		URLs:       urlStore,
+		Clicks:     clickStore,
+		APIKeys:    apiKeyStore,
+		Workspaces: workspaceStore,
+		close:      client.Disconnect,
+	}, nil
+}
@@ -0,0 +1,559 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the "pgx" driver
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const (
+	DialectSQLite   = "sqlite"
+	DialectPostgres = "postgres"
+)
+
+// insertManyChunk bounds the rows of one multi-row INSERT, keeping its
+// placeholders well under the limits of SQLite and PostgreSQL.
+const insertManyChunk = 500
+
+const urlColumns = "id, original_url, short_code, clicks, expires_at, created_by, created_at, updated_at, deleted_at, redirect_type, workspace, custom_code"
+
+// SQLURLStore persists URLs in a relational database. Both SQLite and
+// PostgreSQL are supported; queries are written with "?" placeholders and
+// rebound for the active dialect.
+type SQLURLStore struct {
+	db      *sql.DB
+	dialect string
+}
+
+// OpenSQL opens the database for the dialect and runs the embedded schema
+// migrations. The returned handle is shared by the SQL stores.
+func OpenSQL(ctx context.Context, dialect, dsn string) (*sql.DB, error) {
+	var driverName string
+	switch dialect {
+	case DialectSQLite:
+		driverName = "sqlite"
+	case DialectPostgres:
+		driverName = "pgx"
+	default:
+		return nil, fmt.Errorf("unsupported SQL dialect %q", dialect)
+	}
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if dialect == DialectSQLite {
+		// SQLite allows a single writer; serialising access avoids SQLITE_BUSY.
+		db.SetMaxOpenConns(1)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	if err := migrate(ctx, db, dialect); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func NewSQLURLStore(db *sql.DB, dialect string) *SQLURLStore {
+	return &SQLURLStore{db: db, dialect: dialect}
+}
+
+func (s *SQLURLStore) GetByCode(ctx context.Context, shortCode string) (*models.URL, error) {
+	row := s.db.QueryRowContext(ctx, s.rebind("SELECT "+urlColumns+" FROM urls WHERE short_code = ?"), shortCode)
+
+	return scanURL(row)
+}
+
+func (s *SQLURLStore) GetByOriginalURL(ctx context.Context, originalURL, workspace string) (*models.URL, error) {
+	query := "SELECT " + urlColumns + " FROM urls WHERE original_url = ? AND workspace = ? AND deleted_at IS NULL ORDER BY created_at LIMIT 1"
+	row := s.db.QueryRowContext(ctx, s.rebind(query), originalURL, workspace)
+
+	return scanURL(row)
+}
+
+func (s *SQLURLStore) Insert(ctx context.Context, url *models.URL) error {
+	if url.ID.IsZero() {
+		url.ID = primitive.NewObjectID()
+	}
+
+	query := "INSERT INTO urls (" + urlColumns + ") VALUES " + urlPlaceholders
+	_, err := s.db.ExecContext(ctx, s.rebind(query), urlValues(url)...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateCode
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// InsertMany writes urls with multi-row INSERTs in one transaction. Taken
+// codes are skipped by ON CONFLICT rather than failing the statement, and
+// found by comparing the codes the database returns.
+func (s *SQLURLStore) InsertMany(ctx context.Context, urls []*models.URL) ([]int, error) {
+	duplicates := []int{}
+	pending := make([]int, 0, len(urls))
+	seen := make(map[string]bool, len(urls))
+	for i, url := range urls {
+		if seen[url.ShortCode] {
+			duplicates = append(duplicates, i)
+			continue
+		}
+		seen[url.ShortCode] = true
+		pending = append(pending, i)
+
+		if url.ID.IsZero() {
+			url.ID = primitive.NewObjectID()
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	for start := 0; start < len(pending); start += insertManyChunk {
+		chunk := pending[start:min(start+insertManyChunk, len(pending))]
+
+		placeholders := make([]string, 0, len(chunk))
+		args := make([]any, 0, len(chunk)*strings.Count(urlPlaceholders, "?"))
+		for _, i := range chunk {
+			placeholders = append(placeholders, urlPlaceholders)
+			args = append(args, urlValues(urls[i])...)
+		}
+
+		query := "INSERT INTO urls (" + urlColumns + ") VALUES " + strings.Join(placeholders, ", ") +
+			" ON CONFLICT (short_code) DO NOTHING RETURNING short_code"
+		rows, err := tx.QueryContext(ctx, s.rebind(query), args...)
+		if err != nil {
+			return nil, err
+		}
+
+		inserted := make(map[string]bool, len(chunk))
+		for rows.Next() {
+			var shortCode string
+			if err := rows.Scan(&shortCode); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			inserted[shortCode] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		for _, i := range chunk {
+			if !inserted[urls[i].ShortCode] {
+				duplicates = append(duplicates, i)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	sort.Ints(duplicates)
+
+	return duplicates, nil
+}
+
+func (s *SQLURLStore) IncrementClicks(ctx context.Context, shortCode string, delta int64) error {
+	query := "UPDATE urls SET clicks = clicks + ?, updated_at = ? WHERE short_code = ?"
+	result, err := s.db.ExecContext(ctx, s.rebind(query), delta, time.Now().UTC(), shortCode)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+func (s *SQLURLStore) AddClicks(ctx context.Context, counts map[string]int64) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := addClicks(ctx, tx, s.dialect, counts); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func addClicks(ctx context.Context, tx *sql.Tx, dialect string, counts map[string]int64) error {
+	statement, err := tx.PrepareContext(ctx, rebind(dialect, "UPDATE urls SET clicks = clicks + ?, updated_at = ? WHERE short_code = ?"))
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	now := time.Now().UTC()
+	for shortCode, delta := range counts {
+		if _, err := statement.ExecContext(ctx, delta, now, shortCode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLURLStore) Update(ctx context.Context, url *models.URL) error {
+	query := "UPDATE urls SET original_url = ?, expires_at = ?, deleted_at = ?, redirect_type = ?, updated_at = ? WHERE short_code = ?"
+	result, err := s.db.ExecContext(ctx, s.rebind(query),
+		url.OriginalURL,
+		nullTime(url.ExpiresAt),
+		nullTime(url.DeletedAt),
+		url.RedirectType,
+		url.UpdatedAt.UTC(),
+		url.ShortCode,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+func (s *SQLURLStore) Delete(ctx context.Context, shortCode string) error {
+	result, err := s.db.ExecContext(ctx, s.rebind("DELETE FROM urls WHERE short_code = ?"), shortCode)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+func (s *SQLURLStore) List(ctx context.Context, opts ListOptions) ([]models.URL, error) {
+	where, args := listWhere(opts)
+	query := "SELECT " + urlColumns + " FROM urls" + where + " ORDER BY created_at DESC"
+
+	if opts.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, opts.Limit)
+	} else if opts.Offset > 0 && s.dialect == DialectSQLite {
+		// SQLite only accepts OFFSET after a LIMIT clause.
+		query += " LIMIT -1"
+	}
+	if opts.Offset > 0 {
+		query += " OFFSET ?"
+		args = append(args, opts.Offset)
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	urls := []models.URL{}
+	for rows.Next() {
+		url, err := scanURL(rows)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, *url)
+	}
+
+	return urls, rows.Err()
+}
+
+func (s *SQLURLStore) ScanURLs(ctx context.Context, opts ListOptions, fn func(models.URL) error) error {
+	where, args := listWhere(opts)
+
+	return scanPages(ctx, s.db, s.dialect, "SELECT "+urlColumns+" FROM urls", where, args, "created_at", scanURL,
+		func(url models.URL) (time.Time, string) { return url.CreatedAt, url.ID.Hex() }, fn)
+}
+
+func (s *SQLURLStore) Count(ctx context.Context, opts ListOptions) (int64, error) {
+	where, args := listWhere(opts)
+
+	var count int64
+	if err := s.db.QueryRowContext(ctx, s.rebind("SELECT COUNT(*) FROM urls"+where), args...).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (s *SQLURLStore) NextSequence(ctx context.Context, name string) (int64, error) {
+	query := `INSERT INTO sequences (name, value) VALUES (?, 1)
+		ON CONFLICT (name) DO UPDATE SET value = sequences.value + 1
+		RETURNING value`
+
+	var value int64
+	if err := s.db.QueryRowContext(ctx, s.rebind(query), name).Scan(&value); err != nil {
+		return 0, err
+	}
+
+	return value, nil
+}
+
+const urlPlaceholders = "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+// urlValues lists the values of url in urlColumns order.
+func urlValues(url *models.URL) []any {
+	return []any{
+		url.ID.Hex(),
+		url.OriginalURL,
+		url.ShortCode,
+		url.Clicks,
+		nullTime(url.ExpiresAt),
+		url.CreatedBy,
+		url.CreatedAt.UTC(),
+		url.UpdatedAt.UTC(),
+		nullTime(url.DeletedAt),
+		url.RedirectType,
+		models.WorkspaceOrDefault(url.Workspace),
+		url.CustomCode,
+	}
+}
+
+func (s *SQLURLStore) rebind(query string) string {
+	return rebind(s.dialect, query)
+}
+
+// scanPageSize is how many rows scanPages reads per query.
+var scanPageSize = 1000
+
+// scanPages streams the rows of query in (timeColumn, id) order, reading
+// them in pages keyed on the last row seen, which an index on (timeColumn,
+// id) serves without sorting. Each page is read in full and its rows closed
+// before fn sees them, so a slow consumer such as an export to a stalled
+// client never holds a connection, of which SQLite has only one.
+func scanPages[T any](ctx context.Context, db *sql.DB, dialect, query, where string, args []any, timeColumn string,
+	scan func(rowScanner) (*T, error), key func(T) (time.Time, string), fn func(T) error) error {
+	var (
+		lastTime time.Time
+		lastID   string
+		page     = make([]T, 0, scanPageSize)
+	)
+
+	for first := true; ; first = false {
+		pageWhere, pageArgs := where, slices.Clip(args)
+		if !first {
+			keyset := "(" + timeColumn + ", id) > (?, ?)"
+			if pageWhere == "" {
+				pageWhere = " WHERE " + keyset
+			} else {
+				pageWhere += " AND " + keyset
+			}
+			pageArgs = append(pageArgs, lastTime.UTC(), lastID)
+		}
+
+		pageQuery := query + pageWhere + " ORDER BY " + timeColumn + ", id LIMIT " + strconv.Itoa(scanPageSize)
+		if err := readPage(ctx, db, rebind(dialect, pageQuery), pageArgs, scan, &page); err != nil {
+			return err
+		}
+
+		for _, item := range page {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+
+		if len(page) < scanPageSize {
+			return nil
+		}
+		lastTime, lastID = key(page[len(page)-1])
+	}
+}
+
+func readPage[T any](ctx context.Context, db *sql.DB, query string, args []any, scan func(rowScanner) (*T, error), page *[]T) error {
+	*page = (*page)[:0]
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return err
+		}
+		*page = append(*page, *item)
+	}
+
+	return rows.Err()
+}
+
+// rebind rewrites "?" placeholders into the "$n" form expected by PostgreSQL.
+func rebind(dialect, query string) string {
+	if dialect != DialectPostgres {
+		return query
+	}
+
+	var builder strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			builder.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
+
+func listWhere(opts ListOptions) (string, []any) {
+	now := time.Now().UTC()
+	conditions := []string{}
+	args := []any{}
+
+	switch opts.Status {
+	case StatusDeleted:
+		conditions = append(conditions, "deleted_at IS NOT NULL")
+	case StatusActive:
+		conditions = append(conditions, "deleted_at IS NULL", "(expires_at IS NULL OR expires_at >= ?)")
+		args = append(args, now)
+	case StatusExpired:
+		conditions = append(conditions, "deleted_at IS NULL", "expires_at < ?")
+		args = append(args, now)
+	case StatusAll:
+	default:
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+
+	if opts.CreatedBy != "" {
+		conditions = append(conditions, "created_by = ?")
+		args = append(args, opts.CreatedBy)
+	}
+
+	if opts.Workspace != "" {
+		conditions = append(conditions, "workspace = ?")
+		args = append(args, opts.Workspace)
+	}
+
+	if opts.CustomCode {
+		conditions = append(conditions, "custom_code = ?")
+		args = append(args, true)
+	}
+
+	if !opts.CreatedFrom.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, opts.CreatedFrom.UTC())
+	}
+
+	if !opts.CreatedTo.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, opts.CreatedTo.UTC())
+	}
+
+	if opts.Search != "" {
+		pattern := "%" + likeEscaper.Replace(strings.ToLower(opts.Search)) + "%"
+		conditions = append(conditions, `(LOWER(original_url) LIKE ? ESCAPE '\' OR LOWER(short_code) LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanURL(row rowScanner) (*models.URL, error) {
+	var (
+		url       models.URL
+		id        string
+		expiresAt sql.NullTime
+		deletedAt sql.NullTime
+	)
+
+	err := row.Scan(&id, &url.OriginalURL, &url.ShortCode, &url.Clicks, &expiresAt, &url.CreatedBy, &url.CreatedAt, &url.UpdatedAt, &deletedAt, &url.RedirectType, &url.Workspace, &url.CustomCode)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	url.ID, err = primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id %q: %w", id, err)
+	}
+
+	if expiresAt.Valid {
+		url.ExpiresAt = &expiresAt.Time
+	}
+	if deletedAt.Valid {
+		url.DeletedAt = &deletedAt.Time
+	}
+
+	return &url, nil
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func expectAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505"
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+
+	return false
+}
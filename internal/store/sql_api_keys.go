@@ -0,0 +1,129 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const apiKeyColumns = "id, name, owner, prefix, hash, created_at, revoked_at, workspace"
+
+type SQLAPIKeyStore struct {
+	db      *sql.DB
+	dialect string
+}
+
+func NewSQLAPIKeyStore(db *sql.DB, dialect string) *SQLAPIKeyStore {
+	return &SQLAPIKeyStore{db: db, dialect: dialect}
+}
+
+func (s *SQLAPIKeyStore) InsertAPIKey(ctx context.Context, key *models.APIKey) error {
+	if key.ID.IsZero() {
+		key.ID = primitive.NewObjectID()
+	}
+
+	query := "INSERT INTO api_keys (" + apiKeyColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+	_, err := s.db.ExecContext(ctx, rebind(s.dialect, query),
+		key.ID.Hex(),
+		key.Name,
+		key.Owner,
+		key.Prefix,
+		key.Hash,
+		key.CreatedAt.UTC(),
+		nullTime(key.RevokedAt),
+		models.WorkspaceOrDefault(key.Workspace),
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateKey
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *SQLAPIKeyStore) GetAPIKey(ctx context.Context, prefix string) (*models.APIKey, error) {
+	row := s.db.QueryRowContext(ctx, rebind(s.dialect, "SELECT "+apiKeyColumns+" FROM api_keys WHERE prefix = ?"), prefix)
+
+	return scanAPIKey(row)
+}
+
+func (s *SQLAPIKeyStore) ListAPIKeys(ctx context.Context, owner string) ([]models.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys"
+	args := []any{}
+	if owner != "" {
+		query += " WHERE owner = ?"
+		args = append(args, owner)
+	}
+	query += " ORDER BY created_at DESC"
+
+	rows, err := s.db.QueryContext(ctx, rebind(s.dialect, query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (s *SQLAPIKeyStore) RevokeAPIKey(ctx context.Context, prefix string, at time.Time) error {
+	query := "UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ?) WHERE prefix = ?"
+	result, err := s.db.ExecContext(ctx, rebind(s.dialect, query), at.UTC(), prefix)
+	if err != nil {
+		return err
+	}
+
+	if err := expectAffected(result); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrKeyNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var (
+		key       models.APIKey
+		id        string
+		revokedAt sql.NullTime
+	)
+
+	err := row.Scan(&id, &key.Name, &key.Owner, &key.Prefix, &key.Hash, &key.CreatedAt, &revokedAt, &key.Workspace)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrKeyNotFound
+		}
+
+		return nil, err
+	}
+
+	key.ID, err = primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid api key id %q: %w", id, err)
+	}
+
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+
+	return &key, nil
+}
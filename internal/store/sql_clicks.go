@@ -0,0 +1,262 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const clickColumns = "id, short_code, clicked_at, referrer, user_agent, ip, accept_language, country, browser, os, device, bot, region, city"
+
+type SQLClickStore struct {
+	db      *sql.DB
+	dialect string
+}
+
+func NewSQLClickStore(db *sql.DB, dialect string) *SQLClickStore {
+	return &SQLClickStore{db: db, dialect: dialect}
+}
+
+func (s *SQLClickStore) InsertClicks(ctx context.Context, clicks []models.Click) error {
+	_, err := s.WriteClicks(ctx, clicks, func(models.Click) bool { return false })
+
+	return err
+}
+
+// WriteClicks inserts clicks and increments the counters of the counted ones
+// in one transaction, the clicks and urls tables sharing a database.
+func (s *SQLClickStore) WriteClicks(ctx context.Context, clicks []models.Click, counted func(models.Click) bool) ([]int, error) {
+	duplicates := []int{}
+	if len(clicks) == 0 {
+		return duplicates, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	query := "INSERT INTO clicks (" + clickColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (id) DO NOTHING"
+	statement, err := tx.PrepareContext(ctx, rebind(s.dialect, query))
+	if err != nil {
+		return nil, err
+	}
+	defer statement.Close()
+
+	counts := make(map[string]int64)
+	for i, click := range clicks {
+		if click.ID.IsZero() {
+			click.ID = primitive.NewObjectID()
+		}
+
+		result, err := statement.ExecContext(ctx,
+			click.ID.Hex(),
+			click.ShortCode,
+			click.Timestamp.UTC(),
+			click.Referrer,
+			click.UserAgent,
+			click.IP,
+			click.AcceptLanguage,
+			click.Country,
+			click.Browser,
+			click.OS,
+			click.Device,
+			click.Bot,
+			click.Region,
+			click.City,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if inserted, err := result.RowsAffected(); err != nil {
+			return nil, err
+		} else if inserted == 0 {
+			duplicates = append(duplicates, i)
+			continue
+		}
+
+		if counted(click) {
+			counts[click.ShortCode]++
+		}
+	}
+
+	if err := addClicks(ctx, tx, s.dialect, counts); err != nil {
+		return nil, err
+	}
+
+	return duplicates, tx.Commit()
+}
+
+func (s *SQLClickStore) ScanClicks(ctx context.Context, filter ClickFilter, fn func(models.Click) error) error {
+	where, args := clickConditions(filter, nil)
+
+	return scanPages(ctx, s.db, s.dialect, "SELECT "+clickColumns+" FROM clicks", where, args, "clicked_at", scanClick,
+		func(click models.Click) (time.Time, string) { return click.Timestamp, click.ID.Hex() }, fn)
+}
+
+// clickBucketLayout is the text form of the bucket starts SummarizeClicks
+// groups by.
+const clickBucketLayout = "2006-01-02 15:04:05"
+
+// SummarizeClicks groups the clicks with one query for the totals and one per
+// series or dimension, each reading the (short_code, clicked_at) index range
+// of the link.
+func (s *SQLClickStore) SummarizeClicks(ctx context.Context, filter ClickFilter, opts SummaryOptions) (*ClickSummary, error) {
+	summary := newClickSummary()
+
+	where, args := clickConditions(filter, nil)
+	visitor := "ip || '|' || user_agent"
+	if !opts.IncludeBots {
+		visitor = "CASE WHEN NOT bot THEN " + visitor + " END"
+	}
+	query := "SELECT COUNT(*), COALESCE(SUM(CASE WHEN bot THEN 1 ELSE 0 END), 0), COUNT(DISTINCT " + visitor + ") FROM clicks" + where
+	var total int64
+	err := s.db.QueryRowContext(ctx, rebind(s.dialect, query), args...).Scan(&total, &summary.Bots, &summary.Visitors)
+	if err != nil {
+		return nil, err
+	}
+	summary.Clicks = total
+	if !opts.IncludeBots {
+		summary.Clicks -= summary.Bots
+	}
+	if summary.Clicks == 0 {
+		return summary, nil
+	}
+
+	var extra []string
+	if !opts.IncludeBots {
+		extra = []string{"NOT bot"}
+	}
+
+	series := map[string]int64{}
+	groups := []struct {
+		expression string
+		counts     map[string]int64
+	}{
+		{s.bucketExpression(opts.Bucket), series},
+		{"referrer", summary.Referrers},
+		{"country", summary.Countries},
+		{"region", summary.Regions},
+		{"CASE WHEN city = '' OR country = '' THEN city ELSE city || ', ' || country END", summary.Cities},
+		{"browser", summary.Browsers},
+		{"os", summary.OS},
+	}
+	where, args = clickConditions(filter, extra)
+	for _, group := range groups {
+		query := "SELECT " + group.expression + ", COUNT(*) FROM clicks" + where + " GROUP BY 1"
+		if err := s.countGroups(ctx, query, args, group.counts); err != nil {
+			return nil, err
+		}
+	}
+
+	for start, clicks := range series {
+		at, err := time.Parse(clickBucketLayout, start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid click bucket %q: %w", start, err)
+		}
+		summary.Series[at] = clicks
+	}
+
+	return summary, nil
+}
+
+// bucketExpression truncates clicked_at to the start of its hour or UTC day,
+// formatted with clickBucketLayout. SQLite keeps clicked_at as text in UTC,
+// "2006-01-02 15:04:05.999999999 +0000 UTC", which its date functions cannot
+// read, so the bucket is a prefix of it.
+func (s *SQLClickStore) bucketExpression(bucket time.Duration) string {
+	if s.dialect == DialectPostgres {
+		if bucket == time.Hour {
+			return "to_char(clicked_at AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:00:00')"
+		}
+
+		return "to_char(clicked_at AT TIME ZONE 'UTC', 'YYYY-MM-DD 00:00:00')"
+	}
+
+	if bucket == time.Hour {
+		return "substr(clicked_at, 1, 13) || ':00:00'"
+	}
+
+	return "substr(clicked_at, 1, 10) || ' 00:00:00'"
+}
+
+// countGroups adds the (value, count) rows of query to counts, leaving out
+// empty values.
+func (s *SQLClickStore) countGroups(ctx context.Context, query string, args []any, counts map[string]int64) error {
+	rows, err := s.db.QueryContext(ctx, rebind(s.dialect, query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			value  sql.NullString
+			clicks int64
+		)
+		if err := rows.Scan(&value, &clicks); err != nil {
+			return err
+		}
+		if value.String != "" {
+			counts[value.String] += clicks
+		}
+	}
+
+	return rows.Err()
+}
+
+// clickConditions renders filter, and any extra conditions, as a WHERE clause.
+func clickConditions(filter ClickFilter, extra []string) (string, []any) {
+	conditions := []string{}
+	args := []any{}
+
+	if filter.ShortCode != "" {
+		conditions = append(conditions, "short_code = ?")
+		args = append(args, filter.ShortCode)
+	}
+	if filter.Workspace != "" {
+		conditions = append(conditions, "short_code IN (SELECT short_code FROM urls WHERE workspace = ?)")
+		args = append(args, filter.Workspace)
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "clicked_at >= ?")
+		args = append(args, filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "clicked_at < ?")
+		args = append(args, filter.To.UTC())
+	}
+	conditions = append(conditions, extra...)
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func scanClick(row rowScanner) (*models.Click, error) {
+	var (
+		click models.Click
+		id    string
+	)
+
+	err := row.Scan(&id, &click.ShortCode, &click.Timestamp, &click.Referrer, &click.UserAgent, &click.IP, &click.AcceptLanguage, &click.Country, &click.Browser, &click.OS, &click.Device, &click.Bot, &click.Region, &click.City)
+	if err != nil {
+		return nil, err
+	}
+
+	click.ID, err = primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid click id %q: %w", id, err)
+	}
+
+	return &click, nil
+}
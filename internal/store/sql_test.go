@@ -0,0 +1,636 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := OpenSQL(context.Background(), DialectSQLite, filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func newSQLiteStore(t *testing.T) *SQLURLStore {
+	t.Helper()
+
+	return NewSQLURLStore(openSQLite(t), DialectSQLite)
+}
+
+func TestSQLURLStore_InsertAndGet(t *testing.T) {
+	s := newSQLiteStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	expiresAt := now.Add(24 * time.Hour)
+
+	url := &models.URL{
+		OriginalURL:  "https://example.com",
+		ShortCode:    "abc123",
+		ExpiresAt:    &expiresAt,
+		RedirectType: 302,
+		CreatedBy:    "anonymous",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	require.NoError(t, s.Insert(ctx, url))
+	assert.False(t, url.ID.IsZero())
+
+	byCode, err := s.GetByCode(ctx, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, url.ID, byCode.ID)
+	assert.Equal(t, "https://example.com", byCode.OriginalURL)
+	assert.Equal(t, 302, byCode.RedirectType)
+	assert.True(t, url.ExpiresAt.Equal(*byCode.ExpiresAt))
+	assert.True(t, url.CreatedAt.Equal(byCode.CreatedAt))
+
+	byOriginal, err := s.GetByOriginalURL(ctx, "https://example.com", models.DefaultWorkspace)
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", byOriginal.ShortCode)
+
+	_, err = s.GetByCode(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSQLURLStore_NullExpiry(t *testing.T) {
+	s := newSQLiteStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Insert(ctx, &models.URL{OriginalURL: "https://example.com", ShortCode: "forever", CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+
+	url, err := s.GetByCode(ctx, "forever")
+	require.NoError(t, err)
+	assert.Nil(t, url.ExpiresAt)
+}
+
+func TestSQLURLStore_DuplicateCode(t *testing.T) {
+	s := newSQLiteStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Insert(ctx, &models.URL{OriginalURL: "https://a.com", ShortCode: "same", CreatedAt: time.Now(), UpdatedAt: time.Now()}))
+
+	err := s.Insert(ctx, &models.URL{OriginalURL: "https://b.com", ShortCode: "same", CreatedAt: time.Now(), UpdatedAt: time.Now()})
+	assert.ErrorIs(t, err, ErrDuplicateCode)
+}
+
+func TestSQLURLStore_InsertMany(t *testing.T) {
+	s := newSQLiteStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, s.Insert(ctx, &models.URL{OriginalURL: "https://a.com", ShortCode: "taken", CreatedAt: now, UpdatedAt: now}))
+
+	urls := []*models.URL{
+		{OriginalURL: "https://b.com", ShortCode: "first", CreatedAt: now, UpdatedAt: now},
+		{OriginalURL: "https://c.com", ShortCode: "taken", CreatedAt: now, UpdatedAt: now},
+		{OriginalURL: "https://d.com", ShortCode: "first", CreatedAt: now, UpdatedAt: now},
+		{OriginalURL: "https://e.com", ShortCode: "custom", CustomCode: true, Workspace: "sales", CreatedAt: now, UpdatedAt: now},
+	}
+	duplicates, err := s.InsertMany(ctx, urls)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, duplicates)
+
+	first, err := s.GetByCode(ctx, "first")
+	require.NoError(t, err)
+	assert.Equal(t, "https://b.com", first.OriginalURL)
+	assert.Equal(t, urls[0].ID, first.ID)
+
+	custom, err := s.GetByCode(ctx, "custom")
+	require.NoError(t, err)
+	assert.True(t, custom.CustomCode)
+	assert.Equal(t, "sales", custom.Workspace)
+
+	taken, err := s.GetByCode(ctx, "taken")
+	require.NoError(t, err)
+	assert.Equal(t, "https://a.com", taken.OriginalURL)
+
+	duplicates, err = s.InsertMany(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, duplicates)
+}
+
+func TestSQLURLStore_ScanURLs(t *testing.T) {
+	s := newSQLiteStore(t)
+	ctx := context.Background()
+	day := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	deletedAt := day.Add(time.Hour)
+	for i, code := range []string{"c", "a", "b"} {
+		created := day.Add(time.Duration(2-i) * 24 * time.Hour)
+		url := &models.URL{OriginalURL: "https://example.com/" + code, ShortCode: code, Workspace: "sales", CreatedAt: created, UpdatedAt: created}
+		if code == "b" {
+			url.DeletedAt = &deletedAt
+		}
+		require.NoError(t, s.Insert(ctx, url))
+	}
+
+	scan := func(opts ListOptions) []string {
+		codes := []string{}
+		require.NoError(t, s.ScanURLs(ctx, opts, func(url models.URL) error {
+			codes = append(codes, url.ShortCode)
+			return nil
+		}))
+		return codes
+	}
+
+	assert.Equal(t, []string{"a", "c"}, scan(ListOptions{}))
+	assert.Equal(t, []string{"b", "a", "c"}, scan(ListOptions{Status: StatusAll, Workspace: "sales"}))
+	assert.Equal(t, []string{"b", "a"}, scan(ListOptions{Status: StatusAll, CreatedTo: day.Add(48 * time.Hour)}))
+	assert.Empty(t, scan(ListOptions{Workspace: models.DefaultWorkspace}))
+
+	stop := errors.New("stop")
+	err := s.ScanURLs(ctx, ListOptions{}, func(models.URL) error { return stop })
+	assert.ErrorIs(t, err, stop)
+}
+
+func TestSQLURLStore_IncrementDeleteAndList(t *testing.T) {
+	s := newSQLiteStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, s.Insert(ctx, &models.URL{
+			OriginalURL: fmt.Sprintf("https://example.com/%d", i),
+			ShortCode:   fmt.Sprintf("code%d", i),
+			CreatedAt:   now.Add(time.Duration(i) * time.Minute),
+			UpdatedAt:   now,
+		}))
+	}
+
+	require.NoError(t, s.IncrementClicks(ctx, "code0", 3))
+	url, err := s.GetByCode(ctx, "code0")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), url.Clicks)
+	assert.ErrorIs(t, s.IncrementClicks(ctx, "missing", 1), ErrNotFound)
+
+	require.NoError(t, s.Delete(ctx, "code4"))
+	assert.ErrorIs(t, s.Delete(ctx, "code4"), ErrNotFound)
+
+	urls, err := s.List(ctx, ListOptions{Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	assert.Len(t, urls, 2)
+	assert.Equal(t, "code2", urls[0].ShortCode)
+	assert.Equal(t, "code1", urls[1].ShortCode)
+
+	urls, err = s.List(ctx, ListOptions{Offset: 3})
+	require.NoError(t, err)
+	assert.Len(t, urls, 1)
+}
+
+func TestSQLURLStore_MigrationsAreIdempotent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	first, err := OpenSQL(context.Background(), DialectSQLite, path)
+	require.NoError(t, err)
+	require.NoError(t, first.Close())
+
+	second, err := OpenSQL(context.Background(), DialectSQLite, path)
+	require.NoError(t, err)
+	require.NoError(t, second.Close())
+}
+
+func TestRebind(t *testing.T) {
+	query := "SELECT * FROM urls WHERE short_code = ? AND clicks > ?"
+
+	assert.Equal(t, query, rebind(DialectSQLite, query))
+	assert.Equal(t, "SELECT * FROM urls WHERE short_code = $1 AND clicks > $2", rebind(DialectPostgres, query))
+}
+
+func TestSQLURLStore_NextSequence(t *testing.T) {
+	s := newSQLiteStore(t)
+	ctx := context.Background()
+
+	for want := int64(1); want <= 3; want++ {
+		got, err := s.NextSequence(ctx, "short_codes")
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	other, err := s.NextSequence(ctx, "other")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), other)
+}
+
+func TestSQLURLStore_UpdateAndFilters(t *testing.T) {
+	s := newSQLiteStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	past := now.Add(-time.Hour)
+
+	urls := []*models.URL{
+		{OriginalURL: "https://example.com/a", ShortCode: "active", CreatedBy: "alice"},
+		{OriginalURL: "https://example.com/b", ShortCode: "expired", ExpiresAt: &past, CreatedBy: "bob"},
+		{OriginalURL: "https://other.com/100%", ShortCode: "deleted", CreatedBy: "alice"},
+	}
+	for _, url := range urls {
+		url.CreatedAt, url.UpdatedAt = now, now
+		require.NoError(t, s.Insert(ctx, url))
+	}
+
+	deleted := urls[2]
+	deleted.DeletedAt = &now
+	deleted.OriginalURL = "https://other.com/100%/moved"
+	require.NoError(t, s.Update(ctx, deleted))
+
+	stored, err := s.GetByCode(ctx, "deleted")
+	require.NoError(t, err)
+	assert.NotNil(t, stored.DeletedAt)
+	assert.Equal(t, "https://other.com/100%/moved", stored.OriginalURL)
+
+	_, err = s.GetByOriginalURL(ctx, "https://other.com/100%/moved", models.DefaultWorkspace)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	tests := []struct {
+		opts     ListOptions
+		expected int64
+	}{
+		{ListOptions{}, 2},
+		{ListOptions{Status: StatusActive}, 1},
+		{ListOptions{Status: StatusExpired}, 1},
+		{ListOptions{Status: StatusDeleted}, 1},
+		{ListOptions{CreatedBy: "alice"}, 1},
+		{ListOptions{Search: "EXAMPLE"}, 2},
+		{ListOptions{Search: "100%", Status: StatusDeleted}, 1},
+		{ListOptions{Search: "_"}, 0},
+	}
+
+	for _, tt := range tests {
+		count, err := s.Count(ctx, tt.opts)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, count, "%+v", tt.opts)
+
+		listed, err := s.List(ctx, tt.opts)
+		require.NoError(t, err)
+		assert.Len(t, listed, int(tt.expected), "%+v", tt.opts)
+	}
+
+	assert.ErrorIs(t, s.Update(ctx, &models.URL{ShortCode: "missing"}), ErrNotFound)
+}
+
+func TestSQLClickStore_InsertClicks(t *testing.T) {
+	db := openSQLite(t)
+	s := NewSQLClickStore(db, DialectSQLite)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	require.NoError(t, s.InsertClicks(ctx, nil))
+	require.NoError(t, s.InsertClicks(ctx, []models.Click{
+		{ShortCode: "abc123", Timestamp: now, Referrer: "https://referrer.example/", UserAgent: "test-agent", IP: "192.0.2.1", AcceptLanguage: "en-US"},
+		{ShortCode: "abc123", Timestamp: now.Add(time.Second)},
+	}))
+
+	var (
+		count     int
+		clickedAt time.Time
+		referrer  string
+		userAgent string
+		ip        string
+		language  string
+	)
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM clicks WHERE short_code = ?", "abc123").Scan(&count))
+	assert.Equal(t, 2, count)
+
+	row := db.QueryRow("SELECT clicked_at, referrer, user_agent, ip, accept_language FROM clicks ORDER BY clicked_at LIMIT 1")
+	require.NoError(t, row.Scan(&clickedAt, &referrer, &userAgent, &ip, &language))
+	assert.True(t, now.Equal(clickedAt))
+	assert.Equal(t, "https://referrer.example/", referrer)
+	assert.Equal(t, "test-agent", userAgent)
+	assert.Equal(t, "192.0.2.1", ip)
+	assert.Equal(t, "en-US", language)
+}
+
+func TestSQLClickStore_WriteClicks(t *testing.T) {
+	db := openSQLite(t)
+	urls := NewSQLURLStore(db, DialectSQLite)
+	clicks := NewSQLClickStore(db, DialectSQLite)
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, urls.Insert(ctx, &models.URL{OriginalURL: "https://example.com", ShortCode: "abc123", CreatedAt: now, UpdatedAt: now}))
+
+	counted := func(click models.Click) bool { return !click.Bot }
+	id := primitive.NewObjectID()
+	duplicates, err := clicks.WriteClicks(ctx, []models.Click{
+		{ID: id, ShortCode: "abc123", Timestamp: now},
+		{ShortCode: "abc123", Timestamp: now, Bot: true},
+	}, counted)
+	require.NoError(t, err)
+	assert.Empty(t, duplicates)
+
+	// Replayed clicks are skipped and not counted again.
+	duplicates, err = clicks.WriteClicks(ctx, []models.Click{
+		{ShortCode: "abc123", Timestamp: now},
+		{ID: id, ShortCode: "abc123", Timestamp: now},
+	}, counted)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, duplicates)
+
+	url, err := urls.GetByCode(ctx, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), url.Clicks)
+
+	// A failed counter update rolls the events back with it.
+	_, err = db.Exec("DROP TABLE urls")
+	require.NoError(t, err)
+	_, err = clicks.WriteClicks(ctx, []models.Click{{ShortCode: "abc123", Timestamp: now}}, counted)
+	require.Error(t, err)
+
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM clicks").Scan(&count))
+	assert.Equal(t, 3, count)
+}
+
+func TestSQLURLStore_AddClicks(t *testing.T) {
+	s := newSQLiteStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, code := range []string{"abc123", "def456"} {
+		require.NoError(t, s.Insert(ctx, &models.URL{OriginalURL: "https://example.com/" + code, ShortCode: code, CreatedAt: now, UpdatedAt: now}))
+	}
+
+	require.NoError(t, s.AddClicks(ctx, map[string]int64{"abc123": 3, "def456": 1, "missing": 2}))
+	require.NoError(t, s.AddClicks(ctx, map[string]int64{"abc123": 1}))
+	require.NoError(t, s.AddClicks(ctx, nil))
+
+	abc, err := s.GetByCode(ctx, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), abc.Clicks)
+
+	def, err := s.GetByCode(ctx, "def456")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), def.Clicks)
+}
+
+func TestSQLClickStore_ScanClicks(t *testing.T) {
+	s := NewSQLClickStore(openSQLite(t), DialectSQLite)
+	ctx := context.Background()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, s.InsertClicks(ctx, []models.Click{
+		{ShortCode: "abc123", Timestamp: base.Add(2 * time.Hour), Country: "BR", Browser: "Firefox", OS: "Linux"},
+		{ShortCode: "abc123", Timestamp: base.Add(500 * time.Millisecond)},
+		{ShortCode: "abc123", Timestamp: base.Add(-time.Hour)},
+		{ShortCode: "abc123", Timestamp: base.Add(3 * time.Hour)},
+		{ShortCode: "other", Timestamp: base.Add(time.Hour)},
+	}))
+
+	var clicks []models.Click
+	err := s.ScanClicks(ctx, ClickFilter{ShortCode: "abc123", From: base, To: base.Add(3 * time.Hour)}, func(click models.Click) error {
+		clicks = append(clicks, click)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, clicks, 2)
+	assert.True(t, base.Add(500*time.Millisecond).Equal(clicks[0].Timestamp))
+	assert.True(t, base.Add(2*time.Hour).Equal(clicks[1].Timestamp))
+	assert.Equal(t, "BR", clicks[1].Country)
+	assert.Equal(t, "Firefox", clicks[1].Browser)
+	assert.Equal(t, "Linux", clicks[1].OS)
+	assert.False(t, clicks[1].ID.IsZero())
+
+	stop := fmt.Errorf("stop")
+	visited := 0
+	err = s.ScanClicks(ctx, ClickFilter{}, func(models.Click) error {
+		visited++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, visited)
+}
+
+func TestSQLClickStore_ScanClicksPagesByWorkspace(t *testing.T) {
+	db := openSQLite(t)
+	urls := NewSQLURLStore(db, DialectSQLite)
+	clicks := NewSQLClickStore(db, DialectSQLite)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	deletedAt := now
+	require.NoError(t, urls.Insert(ctx, &models.URL{OriginalURL: "https://example.com/a", ShortCode: "a", Workspace: "sales", CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, urls.Insert(ctx, &models.URL{OriginalURL: "https://example.com/b", ShortCode: "b", Workspace: "sales", CreatedAt: now, UpdatedAt: now, DeletedAt: &deletedAt}))
+	require.NoError(t, urls.Insert(ctx, &models.URL{OriginalURL: "https://example.com/c", ShortCode: "c", CreatedAt: now, UpdatedAt: now}))
+
+	// Several clicks share a timestamp, so pages must break ties on the ID.
+	var batch []models.Click
+	for i := range 7 {
+		for _, code := range []string{"a", "b", "c"} {
+			batch = append(batch, models.Click{ShortCode: code, Timestamp: now.Add(time.Duration(i/3) * time.Second)})
+		}
+	}
+	require.NoError(t, clicks.InsertClicks(ctx, batch))
+
+	defer func(size int) { scanPageSize = size }(scanPageSize)
+	scanPageSize = 2
+
+	seen := map[primitive.ObjectID]bool{}
+	codes := map[string]int{}
+	var last time.Time
+	require.NoError(t, clicks.ScanClicks(ctx, ClickFilter{Workspace: "sales"}, func(click models.Click) error {
+		assert.False(t, seen[click.ID], "click %s scanned twice", click.ID.Hex())
+		assert.False(t, click.Timestamp.Before(last))
+		seen[click.ID] = true
+		codes[click.ShortCode]++
+		last = click.Timestamp
+		return nil
+	}))
+	assert.Equal(t, map[string]int{"a": 7, "b": 7}, codes)
+
+	count := 0
+	require.NoError(t, clicks.ScanClicks(ctx, ClickFilter{Workspace: models.DefaultWorkspace}, func(models.Click) error {
+		count++
+		return nil
+	}))
+	assert.Equal(t, 7, count)
+}
+
+func TestSQLClickStore_SummarizeClicks(t *testing.T) {
+	s := NewSQLClickStore(openSQLite(t), DialectSQLite)
+	ctx := context.Background()
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, s.InsertClicks(ctx, []models.Click{
+		{ShortCode: "abc123", Timestamp: day.Add(time.Hour + 500*time.Millisecond), IP: "192.0.2.1", UserAgent: "a", Referrer: "https://news.example/1", Country: "BR", Region: "BR-SP", City: "São Paulo", Browser: "Firefox", OS: "Linux"},
+		{ShortCode: "abc123", Timestamp: day.Add(time.Hour + 30*time.Minute), IP: "192.0.2.1", UserAgent: "a", Referrer: "https://news.example/1", Country: "BR", City: "Campinas", Browser: "Firefox", OS: "Linux"},
+		{ShortCode: "abc123", Timestamp: day.Add(26 * time.Hour), IP: "192.0.2.2", UserAgent: "b", City: "Nowhere"},
+		{ShortCode: "abc123", Timestamp: day.Add(3 * time.Hour), IP: "192.0.2.6", Browser: "Slackbot", Bot: true},
+		{ShortCode: "abc123", Timestamp: day.Add(-time.Hour), IP: "192.0.2.4"},
+		{ShortCode: "other", Timestamp: day.Add(time.Hour), IP: "192.0.2.5"},
+	}))
+
+	filter := ClickFilter{ShortCode: "abc123", From: day, To: day.AddDate(0, 0, 3)}
+	summary, err := s.SummarizeClicks(ctx, filter, SummaryOptions{Bucket: time.Hour})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3), summary.Clicks)
+	assert.Equal(t, int64(1), summary.Bots)
+	assert.Equal(t, int64(2), summary.Visitors)
+	assert.Equal(t, map[time.Time]int64{day.Add(time.Hour): 2, day.Add(26 * time.Hour): 1}, summary.Series)
+	assert.Equal(t, map[string]int64{"https://news.example/1": 2}, summary.Referrers)
+	assert.Equal(t, map[string]int64{"BR": 2}, summary.Countries)
+	assert.Equal(t, map[string]int64{"BR-SP": 1}, summary.Regions)
+	assert.Equal(t, map[string]int64{"São Paulo, BR": 1, "Campinas, BR": 1, "Nowhere": 1}, summary.Cities)
+	assert.Equal(t, map[string]int64{"Firefox": 2}, summary.Browsers)
+	assert.Equal(t, map[string]int64{"Linux": 2}, summary.OS)
+
+	summary, err = s.SummarizeClicks(ctx, filter, SummaryOptions{Bucket: 24 * time.Hour, IncludeBots: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(4), summary.Clicks)
+	assert.Equal(t, int64(1), summary.Bots)
+	assert.Equal(t, int64(3), summary.Visitors)
+	assert.Equal(t, map[time.Time]int64{day: 3, day.AddDate(0, 0, 1): 1}, summary.Series)
+	assert.Equal(t, map[string]int64{"Firefox": 2, "Slackbot": 1}, summary.Browsers)
+
+	summary, err = s.SummarizeClicks(ctx, ClickFilter{ShortCode: "missing"}, SummaryOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), summary.Clicks)
+	assert.Empty(t, summary.Series)
+}
+
+func TestSQLClickStore_DerivedDimensions(t *testing.T) {
+	s := NewSQLClickStore(openSQLite(t), DialectSQLite)
+	ctx := context.Background()
+
+	require.NoError(t, s.InsertClicks(ctx, []models.Click{
+		{ShortCode: "abc123", Timestamp: time.Now(), Browser: "Slackbot", Device: "bot", Bot: true, Region: "BR-SP", City: "São Paulo"},
+	}))
+
+	var clicks []models.Click
+	require.NoError(t, s.ScanClicks(ctx, ClickFilter{ShortCode: "abc123"}, func(click models.Click) error {
+		clicks = append(clicks, click)
+		return nil
+	}))
+
+	require.Len(t, clicks, 1)
+	assert.True(t, clicks[0].Bot)
+	assert.Equal(t, "bot", clicks[0].Device)
+	assert.Equal(t, "BR-SP", clicks[0].Region)
+	assert.Equal(t, "São Paulo", clicks[0].City)
+}
+
+func TestSQLAPIKeyStore(t *testing.T) {
+	s := NewSQLAPIKeyStore(openSQLite(t), DialectSQLite)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	key := &models.APIKey{Name: "ci", Owner: "alice", Prefix: "usk_abc", Hash: "hash", CreatedAt: now}
+	require.NoError(t, s.InsertAPIKey(ctx, key))
+	assert.False(t, key.ID.IsZero())
+	assert.ErrorIs(t, s.InsertAPIKey(ctx, &models.APIKey{Owner: "bob", Prefix: "usk_abc", Hash: "other", CreatedAt: now}), ErrDuplicateKey)
+	require.NoError(t, s.InsertAPIKey(ctx, &models.APIKey{Owner: "bob", Prefix: "usk_def", Hash: "other", CreatedAt: now.Add(time.Second)}))
+
+	stored, err := s.GetAPIKey(ctx, "usk_abc")
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, stored.ID)
+	assert.Equal(t, "hash", stored.Hash)
+	assert.False(t, stored.IsRevoked())
+
+	_, err = s.GetAPIKey(ctx, "usk_missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	keys, err := s.ListAPIKeys(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, "usk_def", keys[0].Prefix)
+
+	keys, err = s.ListAPIKeys(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+
+	require.NoError(t, s.RevokeAPIKey(ctx, "usk_abc", now))
+	require.NoError(t, s.RevokeAPIKey(ctx, "usk_abc", now.Add(time.Hour)))
+	assert.ErrorIs(t, s.RevokeAPIKey(ctx, "usk_missing", now), ErrKeyNotFound)
+
+	stored, err = s.GetAPIKey(ctx, "usk_abc")
+	require.NoError(t, err)
+	require.True(t, stored.IsRevoked())
+	assert.True(t, now.Equal(*stored.RevokedAt))
+}
+
+func TestSQLWorkspaceStore(t *testing.T) {
+	s := NewSQLWorkspaceStore(openSQLite(t), DialectSQLite)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	require.NoError(t, s.InsertUser(ctx, &models.User{Name: "alice", CreatedAt: now}))
+	require.NoError(t, s.InsertUser(ctx, &models.User{Name: "bob", CreatedAt: now}))
+	assert.ErrorIs(t, s.InsertUser(ctx, &models.User{Name: "alice", CreatedAt: now}), ErrDuplicateUser)
+
+	_, err := s.GetUser(ctx, "carol")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	require.NoError(t, s.InsertWorkspace(ctx, &models.Workspace{Slug: "marketing", Name: "Marketing", CreatedAt: now}))
+	assert.ErrorIs(t, s.InsertWorkspace(ctx, &models.Workspace{Slug: "marketing", CreatedAt: now}), ErrDuplicateWorkspace)
+
+	workspace, err := s.GetWorkspace(ctx, "marketing")
+	require.NoError(t, err)
+	assert.Equal(t, "Marketing", workspace.Name)
+
+	_, err = s.GetWorkspace(ctx, "sales")
+	assert.ErrorIs(t, err, ErrWorkspaceNotFound)
+
+	require.NoError(t, s.SetMember(ctx, models.Membership{Workspace: "marketing", User: "alice", Role: models.RoleOwner, CreatedAt: now}))
+	require.NoError(t, s.SetMember(ctx, models.Membership{Workspace: "marketing", User: "bob", Role: models.RoleViewer, CreatedAt: now}))
+	require.NoError(t, s.SetMember(ctx, models.Membership{Workspace: "marketing", User: "bob", Role: models.RoleEditor, CreatedAt: now.Add(time.Hour)}))
+
+	member, err := s.GetMember(ctx, "marketing", "bob")
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleEditor, member.Role)
+	assert.True(t, now.Equal(member.CreatedAt))
+
+	members, err := s.ListMembers(ctx, "marketing", "")
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+
+	members, err = s.ListMembers(ctx, "", "bob")
+	require.NoError(t, err)
+	assert.Len(t, members, 1)
+
+	require.NoError(t, s.RemoveMember(ctx, "marketing", "bob"))
+	assert.ErrorIs(t, s.RemoveMember(ctx, "marketing", "bob"), ErrMemberNotFound)
+
+	_, err = s.GetMember(ctx, "marketing", "bob")
+	assert.ErrorIs(t, err, ErrMemberNotFound)
+}
+
+func TestSQLURLStore_QuotaFilters(t *testing.T) {
+	s := newSQLiteStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	lastMonth := now.AddDate(0, -1, 0)
+
+	require.NoError(t, s.Insert(ctx, &models.URL{OriginalURL: "https://example.com/a", ShortCode: "custom", CustomCode: true, CreatedBy: "alice", CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, s.Insert(ctx, &models.URL{OriginalURL: "https://example.com/b", ShortCode: "gen001", CreatedBy: "alice", CreatedAt: lastMonth, UpdatedAt: lastMonth}))
+	require.NoError(t, s.Insert(ctx, &models.URL{OriginalURL: "https://example.com/c", ShortCode: "gen002", CreatedBy: "alice", CreatedAt: now, UpdatedAt: now, DeletedAt: &now}))
+
+	stored, err := s.GetByCode(ctx, "custom")
+	require.NoError(t, err)
+	assert.True(t, stored.CustomCode)
+
+	count, err := s.Count(ctx, ListOptions{CustomCode: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	count, err = s.Count(ctx, ListOptions{Status: StatusAll})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	count, err = s.Count(ctx, ListOptions{Status: StatusAll, CreatedBy: "alice", CreatedFrom: now.Add(-time.Minute)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
@@ -0,0 +1,233 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SQLWorkspaceStore struct {
+	db      *sql.DB
+	dialect string
+}
+
+func NewSQLWorkspaceStore(db *sql.DB, dialect string) *SQLWorkspaceStore {
+	return &SQLWorkspaceStore{db: db, dialect: dialect}
+}
+
+func (s *SQLWorkspaceStore) InsertUser(ctx context.Context, user *models.User) error {
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+
+	query := "INSERT INTO users (id, name, created_at) VALUES (?, ?, ?)"
+	_, err := s.db.ExecContext(ctx, rebind(s.dialect, query), user.ID.Hex(), user.Name, user.CreatedAt.UTC())
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateUser
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *SQLWorkspaceStore) GetUser(ctx context.Context, name string) (*models.User, error) {
+	row := s.db.QueryRowContext(ctx, rebind(s.dialect, "SELECT id, name, created_at FROM users WHERE name = ?"), name)
+
+	user, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+
+	return user, err
+}
+
+func (s *SQLWorkspaceStore) ListUsers(ctx context.Context) ([]models.User, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT id, name, created_at FROM users ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+
+	return users, rows.Err()
+}
+
+func (s *SQLWorkspaceStore) InsertWorkspace(ctx context.Context, workspace *models.Workspace) error {
+	if workspace.ID.IsZero() {
+		workspace.ID = primitive.NewObjectID()
+	}
+
+	query := "INSERT INTO workspaces (id, slug, name, created_at) VALUES (?, ?, ?, ?)"
+	_, err := s.db.ExecContext(ctx, rebind(s.dialect, query), workspace.ID.Hex(), workspace.Slug, workspace.Name, workspace.CreatedAt.UTC())
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicateWorkspace
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *SQLWorkspaceStore) GetWorkspace(ctx context.Context, slug string) (*models.Workspace, error) {
+	row := s.db.QueryRowContext(ctx, rebind(s.dialect, "SELECT id, slug, name, created_at FROM workspaces WHERE slug = ?"), slug)
+
+	workspace, err := scanWorkspace(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrWorkspaceNotFound
+	}
+
+	return workspace, err
+}
+
+func (s *SQLWorkspaceStore) ListWorkspaces(ctx context.Context) ([]models.Workspace, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT id, slug, name, created_at FROM workspaces ORDER BY slug")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	workspaces := []models.Workspace{}
+	for rows.Next() {
+		workspace, err := scanWorkspace(rows)
+		if err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, *workspace)
+	}
+
+	return workspaces, rows.Err()
+}
+
+func (s *SQLWorkspaceStore) SetMember(ctx context.Context, member models.Membership) error {
+	query := `INSERT INTO memberships (workspace, user_name, role, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (workspace, user_name) DO UPDATE SET role = excluded.role`
+	_, err := s.db.ExecContext(ctx, rebind(s.dialect, query), member.Workspace, member.User, member.Role, member.CreatedAt.UTC())
+
+	return err
+}
+
+func (s *SQLWorkspaceStore) GetMember(ctx context.Context, workspace, user string) (*models.Membership, error) {
+	query := "SELECT workspace, user_name, role, created_at FROM memberships WHERE workspace = ? AND user_name = ?"
+
+	var member models.Membership
+	err := s.db.QueryRowContext(ctx, rebind(s.dialect, query), workspace, user).Scan(&member.Workspace, &member.User, &member.Role, &member.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMemberNotFound
+		}
+
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+func (s *SQLWorkspaceStore) ListMembers(ctx context.Context, workspace, user string) ([]models.Membership, error) {
+	conditions := []string{}
+	args := []any{}
+	if workspace != "" {
+		conditions = append(conditions, "workspace = ?")
+		args = append(args, workspace)
+	}
+	if user != "" {
+		conditions = append(conditions, "user_name = ?")
+		args = append(args, user)
+	}
+
+	query := "SELECT workspace, user_name, role, created_at FROM memberships"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY workspace, user_name"
+
+	rows, err := s.db.QueryContext(ctx, rebind(s.dialect, query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.Membership{}
+	for rows.Next() {
+		var member models.Membership
+		if err := rows.Scan(&member.Workspace, &member.User, &member.Role, &member.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+func (s *SQLWorkspaceStore) RemoveMember(ctx context.Context, workspace, user string) error {
+	query := "DELETE FROM memberships WHERE workspace = ? AND user_name = ?"
+	result, err := s.db.ExecContext(ctx, rebind(s.dialect, query), workspace, user)
+	if err != nil {
+		return err
+	}
+
+	if err := expectAffected(result); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrMemberNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func scanUser(row rowScanner) (*models.User, error) {
+	var (
+		user models.User
+		id   string
+	)
+
+	if err := row.Scan(&id, &user.Name, &user.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	user.ID, err = primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id %q: %w", id, err)
+	}
+
+	return &user, nil
+}
+
+func scanWorkspace(row rowScanner) (*models.Workspace, error) {
+	var (
+		workspace models.Workspace
+		id        string
+	)
+
+	if err := row.Scan(&id, &workspace.Slug, &workspace.Name, &workspace.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	workspace.ID, err = primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid workspace id %q: %w", id, err)
+	}
+
+	return &workspace, nil
+}
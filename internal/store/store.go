@@ -0,0 +1,206 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/yan-cerqueira-unvoid/url-shortener/internal/models"
+)
+
+var (
+	ErrNotFound      = errors.New("url not found")
+	ErrDuplicateCode = errors.New("short code already exists")
+	ErrKeyNotFound   = errors.New("api key not found")
+	ErrDuplicateKey  = errors.New("api key prefix already exists")
+
+	ErrUserNotFound       = errors.New("user not found")
+	ErrWorkspaceNotFound  = errors.New("workspace not found")
+	ErrMemberNotFound     = errors.New("membership not found")
+	ErrDuplicateUser      = errors.New("user already exists")
+	ErrDuplicateWorkspace = errors.New("workspace already exists")
+)
+
+const (
+	StatusActive  = "active"
+	StatusExpired = "expired"
+	StatusDeleted = "deleted"
+	// StatusAll also matches deleted links.
+	StatusAll = "all"
+)
+
+// ListOptions filters and paginates List and Count. An empty Status matches
+// every link that has not been deleted and an empty Workspace every
+// workspace.
+type ListOptions struct {
+	Limit     int64
+	Offset    int64
+	Search    string // case-insensitive substring of the original URL or short code
+	CreatedBy string
+	Status    string
+	Workspace string
+	// CustomCode only matches links whose short code was chosen by their
+	// creator.
+	CustomCode bool
+	// CreatedFrom only matches links created at or after it, and CreatedTo
+	// links created before it, when set.
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+}
+
+// Sequencer hands out atomically increasing numbers per named sequence,
+// starting at 1.
+type Sequencer interface {
+	NextSequence(ctx context.Context, name string) (int64, error)
+}
+
+// ClickCounter applies click count increments in bulk. Codes that match no
+// link are skipped rather than failing the whole batch.
+type ClickCounter interface {
+	AddClicks(ctx context.Context, counts map[string]int64) error
+}
+
+// URLStore is the persistence layer used by the URL service. Implementations
+// must return ErrNotFound when a lookup matches no document and
+// ErrDuplicateCode when Insert violates the unique short code constraint.
+// GetByOriginalURL only looks in one workspace and ignores deleted links;
+// GetByCode returns them. Links stored without a workspace belong to
+// models.DefaultWorkspace.
+type URLStore interface {
+	GetByCode(ctx context.Context, shortCode string) (*models.URL, error)
+	GetByOriginalURL(ctx context.Context, originalURL, workspace string) (*models.URL, error)
+	Insert(ctx context.Context, url *models.URL) error
+	// InsertMany inserts urls in as few round trips as the backend allows.
+	// URLs whose short code is already taken, by a stored link or an earlier
+	// one of urls, are skipped and their indexes returned; an error means
+	// none of the URLs may have been stored.
+	InsertMany(ctx context.Context, urls []*models.URL) (duplicates []int, err error)
+	IncrementClicks(ctx context.Context, shortCode string, delta int64) error
+	// Update writes the mutable fields of url (original URL, expiry, redirect
+	// type, deletion time and update time), leaving counters untouched.
+	Update(ctx context.Context, url *models.URL) error
+	Delete(ctx context.Context, shortCode string) error
+	List(ctx context.Context, opts ListOptions) ([]models.URL, error)
+	// ScanURLs streams the links matching opts, oldest first, stopping at the
+	// first error returned by fn. Limit and Offset are ignored.
+	ScanURLs(ctx context.Context, opts ListOptions, fn func(models.URL) error) error
+	Count(ctx context.Context, opts ListOptions) (int64, error)
+	Sequencer
+	ClickCounter
+}
+
+// ClickFilter selects click events. Workspace selects the clicks on the
+// links of a workspace, deleted ones included. From is inclusive and To
+// exclusive; zero times leave that side of the range open.
+type ClickFilter struct {
+	ShortCode string
+	Workspace string
+	From      time.Time
+	To        time.Time
+}
+
+// ClickStore persists click events, kept apart from the URL documents so
+// analytics writes never contend with redirects. InsertClicks skips clicks
+// whose ID is already stored, so replaying events is harmless.
+type ClickStore interface {
+	InsertClicks(ctx context.Context, clicks []models.Click) error
+	// ScanClicks streams the matching clicks in timestamp order, stopping at
+	// the first error returned by fn.
+	ScanClicks(ctx context.Context, filter ClickFilter, fn func(models.Click) error) error
+	// SummarizeClicks counts the matching clicks by time bucket and by
+	// dimension, grouping them where they are stored instead of reading them
+	// one by one.
+	SummarizeClicks(ctx context.Context, filter ClickFilter, opts SummaryOptions) (*ClickSummary, error)
+}
+
+// SummaryOptions tell SummarizeClicks how to group clicks.
+type SummaryOptions struct {
+	// Bucket is the length of the series buckets: time.Hour, or a day for
+	// any other value. Buckets start on the hour or at midnight, in UTC.
+	Bucket time.Duration
+	// IncludeBots counts bot clicks along with the others.
+	IncludeBots bool
+}
+
+// ClickSummary is what SummarizeClicks returns. Bots counts the bot clicks
+// in any case; everything else only counts the clicks selected by
+// SummaryOptions.IncludeBots. The dimension maps leave out empty values.
+type ClickSummary struct {
+	Clicks int64
+	Bots   int64
+	// Visitors is the number of distinct IP and user agent pairs.
+	Visitors int64
+	// Series maps the start of each bucket holding clicks to their number.
+	Series    map[time.Time]int64
+	Referrers map[string]int64
+	Countries map[string]int64
+	Regions   map[string]int64
+	// Cities are qualified by their country, "City, CC", as names are not
+	// unique.
+	Cities   map[string]int64
+	Browsers map[string]int64
+	OS       map[string]int64
+}
+
+func newClickSummary() *ClickSummary {
+	return &ClickSummary{
+		Series:    map[time.Time]int64{},
+		Referrers: map[string]int64{},
+		Countries: map[string]int64{},
+		Regions:   map[string]int64{},
+		Cities:    map[string]int64{},
+		Browsers:  map[string]int64{},
+		OS:        map[string]int64{},
+	}
+}
+
+// cityName qualifies city with its country when both are known.
+func cityName(city, country string) string {
+	if city == "" || country == "" {
+		return city
+	}
+
+	return city + ", " + country
+}
+
+// ClickWriter is implemented by click stores that can also increment the link
+// click counters, counting only the clicks they actually store. Each stored
+// click for which counted returns true adds one to its link's counter;
+// skipped clicks are returned as indexes, like URLStore.InsertMany. SQL
+// stores, and MongoDB replica sets and sharded clusters, write the events and
+// counters in one transaction, so the two never drift apart. A standalone
+// MongoDB server stores the events first and then counts them: a failure in
+// between leaves those clicks uncounted for good.
+type ClickWriter interface {
+	WriteClicks(ctx context.Context, clicks []models.Click, counted func(models.Click) bool) (duplicates []int, err error)
+}
+
+// APIKeyStore persists API keys. Keys are looked up by their unique prefix;
+// implementations return ErrKeyNotFound when no key matches and
+// ErrDuplicateKey when Insert reuses a prefix.
+type APIKeyStore interface {
+	InsertAPIKey(ctx context.Context, key *models.APIKey) error
+	GetAPIKey(ctx context.Context, prefix string) (*models.APIKey, error)
+	// ListAPIKeys returns the keys of owner, or every key when owner is empty,
+	// newest first.
+	ListAPIKeys(ctx context.Context, owner string) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, prefix string, at time.Time) error
+}
+
+// WorkspaceStore persists users, workspaces and the memberships that link
+// them. Users are identified by name and workspaces by slug. SetMember
+// creates or replaces a membership.
+type WorkspaceStore interface {
+	InsertUser(ctx context.Context, user *models.User) error
+	GetUser(ctx context.Context, name string) (*models.User, error)
+	ListUsers(ctx context.Context) ([]models.User, error)
+	InsertWorkspace(ctx context.Context, workspace *models.Workspace) error
+	GetWorkspace(ctx context.Context, slug string) (*models.Workspace, error)
+	ListWorkspaces(ctx context.Context) ([]models.Workspace, error)
+	SetMember(ctx context.Context, member models.Membership) error
+	GetMember(ctx context.Context, workspace, user string) (*models.Membership, error)
+	// ListMembers returns the memberships matching workspace and user; an
+	// empty argument matches any.
+	ListMembers(ctx context.Context, workspace, user string) ([]models.Membership, error)
+	RemoveMember(ctx context.Context, workspace, user string) error
+}